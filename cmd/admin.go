@@ -0,0 +1,105 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/achintha-dilshan/go-rest-api/internal/models"
+	"github.com/achintha-dilshan/go-rest-api/internal/services"
+	"github.com/achintha-dilshan/go-rest-api/internal/utils/password"
+)
+
+// create an admin account, or make an existing user an admin
+func createAdmin(args []string) error {
+	flags := flag.NewFlagSet("create-admin", flag.ContinueOnError)
+	email := flags.String("email", "", "email of the admin; required")
+	name := flags.String("name", "Admin", "name of a new admin")
+	passwordFile := flags.String("password-file", "", "path of a file holding the password of a new admin; read from standard input when empty")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	*email = strings.TrimSpace(*email)
+	if *email == "" {
+		flags.Usage()
+		return errors.New("the -email flag is required")
+	}
+
+	return withDatabase(func(db *sql.DB) error {
+		ctx := context.Background()
+
+		svc := services.New(db, nil)
+
+		user, err := svc.User.FindUserByEmail(ctx, *email)
+		if err != nil {
+			return err
+		}
+
+		if user == nil {
+			secret, err := readPassword(*passwordFile)
+			if err != nil {
+				return err
+			}
+
+			message, err := password.PolicyFromConfig().Validate(secret, *name, *email)
+			if err != nil {
+				return err
+			}
+			if message != "" {
+				return errors.New(message)
+			}
+
+			hashedPassword, err := password.HasherFromConfig().Hash(secret)
+			if err != nil {
+				return err
+			}
+
+			user = &models.User{Name: *name, Email: *email, Password: hashedPassword}
+			if user.Id, err = svc.User.CreateUser(ctx, user); err != nil {
+				return err
+			}
+			log.Printf("Created user %s", *email)
+		} else if user.Role == models.RoleAdmin {
+			log.Printf("%s is already an admin", *email)
+			return nil
+		}
+
+		if err := svc.User.SetUserRole(ctx, user.Id, models.RoleAdmin); err != nil {
+			return err
+		}
+
+		log.Printf("%s is now an admin", *email)
+		return nil
+	})
+}
+
+// readPassword reads a password from the first line of the file at path, or
+// of standard input when path is empty.
+func readPassword(path string) (string, error) {
+	input := io.Reader(os.Stdin)
+	if path != "" {
+		file, err := os.Open(path)
+		if err != nil {
+			return "", err
+		}
+		defer file.Close()
+		input = file
+	} else {
+		fmt.Fprint(os.Stderr, "Password: ")
+	}
+
+	line, err := bufio.NewReader(input).ReadString('\n')
+	if err != nil && line == "" {
+		return "", errors.New("no password given")
+	}
+
+	return strings.TrimRight(line, "\r\n"), nil
+}
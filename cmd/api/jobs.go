@@ -0,0 +1,47 @@
+package api
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/achintha-dilshan/go-rest-api/config"
+	"github.com/achintha-dilshan/go-rest-api/internal/repositories"
+	"github.com/achintha-dilshan/go-rest-api/internal/services"
+	"github.com/achintha-dilshan/go-rest-api/internal/utils/mailer"
+)
+
+// events sent to webhooks, through the outbox
+var webhookEvents = []string{
+	services.EventPostCreated,
+	services.EventPostUpdated,
+	services.EventPostDeleted,
+	services.EventUserRegistered,
+}
+
+// newJobWorker creates the background job worker with every job type the
+// API runs.
+func newJobWorker(db *sql.DB, svc *services.Services) services.JobWorker {
+	jobRepository := repositories.NewJobRepository(db)
+	outboxRepository := repositories.NewOutboxRepository(db)
+
+	worker := services.NewJobWorker(jobRepository, outboxRepository, repositories.NewTxManager(db), services.JobOptions{
+		Workers: map[string]int{
+			services.JobQueueDefault: config.Env.JobWorkers,
+			services.JobQueueMail:    config.Env.JobMailWorkers,
+		},
+		Timeout: config.Env.JobTimeout,
+	})
+
+	retention := time.Duration(config.Env.JobRetentionDays) * 24 * time.Hour
+
+	worker.Handle(services.JobWebhookDispatch, services.NewWebhookDispatchJob(svc.Webhook))
+	worker.Handle(services.JobMagicLink, services.NewMagicLinkJob(svc.User, svc.MagicLink, mailer.New(), config.Env.AppURL))
+	worker.Handle(services.JobCleanup, services.NewCleanupJob(jobRepository, outboxRepository, retention))
+
+	for _, event := range webhookEvents {
+		worker.Subscribe(event, services.JobQueueDefault, services.JobWebhookDispatch)
+	}
+	worker.Schedule(services.JobQueueDefault, services.JobCleanup, time.Hour)
+
+	return worker
+}
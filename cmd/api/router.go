@@ -3,40 +3,73 @@ package api
 import (
 	"database/sql"
 
+	"github.com/achintha-dilshan/go-rest-api/internal/middlewares"
 	"github.com/achintha-dilshan/go-rest-api/internal/routes"
+	"github.com/achintha-dilshan/go-rest-api/internal/services"
+	"github.com/achintha-dilshan/go-rest-api/internal/utils/broker"
+	"github.com/achintha-dilshan/go-rest-api/internal/utils/cookie"
+	"github.com/achintha-dilshan/go-rest-api/internal/utils/hub"
 	"github.com/go-chi/chi/middleware"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/render"
 )
 
 type router struct {
-	db *sql.DB
+	db       *sql.DB
+	services *services.Services
+	events   *broker.Broker
+	hub      *hub.Hub
 }
 
 type Router interface {
 	Init() *chi.Mux
 }
 
-func NewRouter(db *sql.DB) Router {
+func NewRouter(db *sql.DB, services *services.Services, events *broker.Broker, hub *hub.Hub) Router {
 	return &router{
-		db: db,
+		db:       db,
+		services: services,
+		events:   events,
+		hub:      hub,
 	}
 }
 
 func (r *router) Init() *chi.Mux {
 	router := chi.NewRouter()
+	router.Use(middleware.RequestID)
 	router.Use(middleware.Logger)
 	router.Use(middleware.Recoverer)
 	router.Use(render.SetContentType(render.ContentTypeJSON))
+	router.Use(middlewares.RequestMetadata)
+
+	// Cookie sessions need CSRF protection on unsafe methods
+	if cookie.Enabled() {
+		router.Use(middlewares.CSRFMiddleware)
+	}
 
 	// Auth Routes
-	router.Mount("/auth", routes.NewAuthRoutes(r.db).Get())
+	router.Mount("/auth", routes.NewAuthRoutes(r.services).Get())
 
 	// User Routes
-	router.Mount("/user", routes.NewUserRoutes(r.db).Get())
+	router.Mount("/user", routes.NewUserRoutes(r.services).Get())
+
+	// Public Profile Routes
+	router.Mount("/users", routes.NewProfileRoutes(r.services).Get())
 
 	// Post Routes
-	router.Mount("/posts", routes.NewPostRoutes(r.db).Get())
+	router.Mount("/posts", routes.NewPostRoutes(r.services, r.events).Get())
+
+	// Feed Routes
+	router.Mount("/feed", routes.NewFeedRoutes(r.services).Get())
+
+	// Media Routes
+	router.Mount("/media", routes.NewMediaRoutes(r.services).Get())
+
+	// WebSocket Routes
+	router.Mount("/ws", routes.NewWSRoutes(r.services, r.events, r.hub).Get())
+
+	// Admin Routes
+	router.Mount("/admin", routes.NewAdminRoutes(r.services, r.db).Get())
 
 	return router
 }
@@ -1,13 +1,28 @@
 package api
 
 import (
+	"context"
 	"database/sql"
 	"log"
+	"net"
 	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
 
 	"github.com/achintha-dilshan/go-rest-api/config"
+	"github.com/achintha-dilshan/go-rest-api/internal/repositories"
+	"github.com/achintha-dilshan/go-rest-api/internal/services"
+	"github.com/achintha-dilshan/go-rest-api/internal/utils/broker"
+	"github.com/achintha-dilshan/go-rest-api/internal/utils/hub"
 )
 
+// how long open requests and connections get to finish on shutdown
+const shutdownTimeout = 15 * time.Second
+
 type apiServer struct {
 	db *sql.DB
 }
@@ -23,14 +38,80 @@ func NewAPIServer(db *sql.DB) APIServer {
 }
 
 func (s *apiServer) Run() error {
-	port := ":" + config.Env.ServerPort
+	addr := net.JoinHostPort(config.Env.ServerHost, strconv.Itoa(config.Env.ServerPort))
+
+	// events are shared by every route, so live streams see changes made through any of them
+	events := broker.New(config.Env.EventReplaySize)
+	defer events.Close()
+
+	clients := hub.New()
+	svc := services.New(s.db, events)
 
 	server := &http.Server{
-		Addr:    port,
-		Handler: NewRouter(s.db).Init(),
+		Addr:    addr,
+		Handler: NewRouter(s.db, svc, events, clients).Init(),
 	}
 
-	log.Printf("Server is running on port %v", port)
+	// Shutdown neither waits for nor closes hijacked WebSockets and endless
+	// streams, so end them as it begins
+	server.RegisterOnShutdown(func() {
+		clients.Close()
+		events.Close()
+	})
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// background workers stop with the server, finishing what they are doing
+	var workers sync.WaitGroup
+	workersCtx, stopWorkers := context.WithCancel(context.Background())
+	defer func() {
+		stopWorkers()
+		workers.Wait()
+	}()
+
+	webhooks := services.NewWebhookWorker(repositories.NewWebhookRepository(s.db), services.WebhookOptions{
+		MaxAttempts:  config.Env.WebhookMaxAttempts,
+		DisableAfter: config.Env.WebhookDisableAfter,
+		Timeout:      config.Env.WebhookTimeout,
+		AllowPrivate: config.Env.WebhookAllowPrivate,
+	})
+	workers.Add(1)
+	go func() {
+		defer workers.Done()
+		webhooks.Run(workersCtx)
+	}()
+
+	jobs := newJobWorker(s.db, svc)
+	workers.Add(1)
+	go func() {
+		defer workers.Done()
+		jobs.Run(workersCtx)
+	}()
+
+	serverErr := make(chan error, 1)
+	go func() {
+		log.Printf("Server is running on %s", addr)
+		serverErr <- server.ListenAndServe()
+	}()
+
+	select {
+	case err := <-serverErr:
+		return err
+	case <-ctx.Done():
+	}
+
+	log.Printf("Shutting down the server")
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		return err
+	}
+	if err := clients.Wait(shutdownCtx); err != nil {
+		return err
+	}
 
-	return server.ListenAndServe()
+	return nil
 }
@@ -1,30 +1,112 @@
 package main
 
 import (
+	"context"
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
 	"log"
+	"os"
 
 	"github.com/achintha-dilshan/go-rest-api/cmd/api"
+	"github.com/achintha-dilshan/go-rest-api/config"
 	"github.com/achintha-dilshan/go-rest-api/database"
 )
 
+const usage = `Usage: go-rest-api [options] [command] [arguments]
+
+Commands:
+  serve                  Run the API server; the default
+  migrate up             Apply every pending migration
+  migrate down           Roll back the latest migration
+  migrate status         List the migrations and whether they are applied
+  migrate to <version>   Migrate up or down to a version; 0 rolls back every migration
+  seed                   Fill the database with demo users and posts; refused in production without -force
+  create-admin           Create an admin, or make an existing user one; see create-admin -h
+
+Options override the configuration file and environment variables:
+`
+
 func main() {
-	// init database
+	cfg, args, err := config.Load(os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		printUsage(os.Stdout)
+		return
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	config.Env = cfg
+
+	command := "serve"
+	if len(args) > 0 {
+		command, args = args[0], args[1:]
+	}
+
+	switch command {
+	case "serve":
+		err = serve()
+	case "migrate":
+		err = migrate(args)
+	case "seed":
+		err = seed(args)
+	case "create-admin":
+		err = createAdmin(args)
+	case "help":
+		printUsage(os.Stdout)
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown command %q.\n\n", command)
+		printUsage(os.Stderr)
+		os.Exit(2)
+	}
+
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// printUsage writes the commands and options to w.
+func printUsage(w io.Writer) {
+	fmt.Fprint(w, usage)
+	config.PrintFlags(w)
+}
+
+// run the API server, applying pending migrations first when configured to
+func serve() error {
+	return withDatabase(func(db *sql.DB) error {
+		if config.Env.DBAutoMigrate {
+			migrator, err := database.NewMigrator(db)
+			if err != nil {
+				return err
+			}
+			if err := migrator.Up(context.Background()); err != nil {
+				return fmt.Errorf("failed to apply the migrations: %w", err)
+			}
+		}
+
+		if err := api.NewAPIServer(db).Run(); err != nil {
+			return fmt.Errorf("failed to start the server: %w", err)
+		}
+		return nil
+	})
+}
+
+// withDatabase connects to the configured database, runs fn and closes the
+// connection.
+func withDatabase(fn func(db *sql.DB) error) error {
 	db := database.NewDatabase()
 	if err := db.Connect(); err != nil {
-		log.Fatalf("Database connection failed: %v", err)
+		return fmt.Errorf("database connection failed: %w", err)
 	}
 	defer db.Close()
 
-	// Get the database instance
 	sqlDB, err := db.GetDB()
 	if err != nil {
-		log.Fatalf("Error retrieving database instance: %v", err)
+		return fmt.Errorf("error retrieving database instance: %w", err)
 	}
 
-	// init server
-	server := api.NewAPIServer(sqlDB)
-
-	if err := server.Run(); err != nil {
-		log.Fatalf("Failed to start the server: %v", err)
-	}
+	return fn(sqlDB)
 }
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/achintha-dilshan/go-rest-api/database"
+)
+
+// apply, roll back or list the embedded migrations
+func migrate(args []string) error {
+	if len(args) == 0 {
+		return errors.New("usage: migrate up|down|status|to <version>")
+	}
+
+	var version int64
+	switch args[0] {
+	case "up", "down", "status":
+		if len(args) != 1 {
+			return fmt.Errorf("usage: migrate %s", args[0])
+		}
+	case "to":
+		if len(args) != 2 {
+			return errors.New("usage: migrate to <version>")
+		}
+		var err error
+		if version, err = strconv.ParseInt(args[1], 10, 64); err != nil || version < 0 {
+			return fmt.Errorf("invalid migration version %q", args[1])
+		}
+	default:
+		return fmt.Errorf("unknown migrate command %q; use up, down, status or to <version>", args[0])
+	}
+
+	return withDatabase(func(db *sql.DB) error {
+		migrator, err := database.NewMigrator(db)
+		if err != nil {
+			return err
+		}
+
+		ctx := context.Background()
+		switch args[0] {
+		case "up":
+			return migrator.Up(ctx)
+		case "down":
+			return migrator.Down(ctx)
+		case "to":
+			return migrator.To(ctx, version)
+		}
+
+		migrations, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tAPPLIED AT\tMIGRATION")
+		for _, migration := range migrations {
+			appliedAt := "pending"
+			if migration.Applied {
+				appliedAt = migration.AppliedAt.UTC().Format(time.DateTime)
+			}
+			fmt.Fprintf(w, "%d\t%s\t%s\n", migration.Version, appliedAt, migration.Name)
+		}
+		return w.Flush()
+	})
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+
+	"github.com/achintha-dilshan/go-rest-api/config"
+	"github.com/achintha-dilshan/go-rest-api/internal/models"
+	"github.com/achintha-dilshan/go-rest-api/internal/services"
+	"github.com/achintha-dilshan/go-rest-api/internal/utils/password"
+)
+
+// the password of every demo user
+const seedPassword = "demo-password"
+
+// demo users, each with a few posts
+var seedUsers = []struct {
+	name  string
+	email string
+	bio   string
+	posts []string
+}{
+	{"Ada Lovelace", "ada@example.com", "Writing programs before there were computers.", []string{"Notes on the Analytical Engine", "Computing Bernoulli numbers"}},
+	{"Grace Hopper", "grace@example.com", "Making computers speak English.", []string{"Finding the first bug", "Why compilers matter"}},
+	{"Alan Turing", "alan@example.com", "Thinking about thinking machines.", []string{"Can machines think?", "On computable numbers"}},
+}
+
+// fill the database with demo users, posts and follows. Users that already
+// exist are left alone, so seeding twice changes nothing. The demo password
+// is public, so production databases are only seeded with -force.
+func seed(args []string) error {
+	flags := flag.NewFlagSet("seed", flag.ContinueOnError)
+	force := flags.Bool("force", false, "seed even when APP_ENV is production")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if config.Env.AppEnv == "production" && !*force {
+		return errors.New("refusing to create demo users with a public password in production; use -force to seed anyway")
+	}
+
+	policy := password.PolicyFromConfig()
+	for _, seedUser := range seedUsers {
+		message, err := policy.Validate(seedPassword, seedUser.name, seedUser.email)
+		if err != nil {
+			return err
+		}
+		if message != "" {
+			return fmt.Errorf("the demo password does not meet the password policy for %s: %s", seedUser.email, message)
+		}
+	}
+
+	return withDatabase(func(db *sql.DB) error {
+		ctx := context.Background()
+
+		svc := services.New(db, nil)
+
+		hashedPassword, err := password.HasherFromConfig().Hash(seedPassword)
+		if err != nil {
+			return err
+		}
+
+		var userIds []int64
+		for _, seedUser := range seedUsers {
+			exists, err := svc.User.ExistUserByEmail(ctx, seedUser.email)
+			if err != nil {
+				return err
+			}
+			if exists {
+				log.Printf("Skipping %s, who already exists", seedUser.email)
+				continue
+			}
+
+			userId, err := svc.User.CreateUser(ctx, &models.User{
+				Name:     seedUser.name,
+				Email:    seedUser.email,
+				Password: hashedPassword,
+			})
+			if err != nil {
+				return err
+			}
+
+			user, err := svc.User.FindUserById(ctx, userId)
+			if err != nil {
+				return err
+			}
+			user.Bio = seedUser.bio
+			if err := svc.User.UpdateUser(ctx, user); err != nil {
+				return err
+			}
+
+			for _, title := range seedUser.posts {
+				_, err := svc.Post.CreatePost(ctx, &models.Post{
+					AuthorId: userId,
+					Title:    title,
+					Body:     "This is a demo post by **" + seedUser.name + "**.",
+					Format:   models.PostFormatMarkdown,
+				})
+				if err != nil {
+					return err
+				}
+			}
+
+			log.Printf("Created %s with %d posts", seedUser.email, len(seedUser.posts))
+			userIds = append(userIds, userId)
+		}
+
+		// everyone new follows everyone else new
+		for _, followerId := range userIds {
+			for _, followeeId := range userIds {
+				if followerId == followeeId {
+					continue
+				}
+				if err := svc.Follow.Follow(ctx, followerId, followeeId); err != nil {
+					return err
+				}
+			}
+		}
+
+		log.Printf("Seeding done; demo users sign in with the password %q", seedPassword)
+		return nil
+	})
+}
@@ -1,48 +1,162 @@
 package config
 
 import (
-	"log"
-	"os"
-
-	"github.com/joho/godotenv"
+	"fmt"
+	"strconv"
+	"time"
 )
 
+// Config is the application configuration. Each field is read from the
+// environment variable named by its env tag, falling back to its default
+// tag; see Load for the other sources.
 type Config struct {
-	AppEnv string
+	AppEnv string `env:"APP_ENV" default:"development"`
+
+	ServerHost string `env:"SERVER_HOST"`
+	ServerPort int    `env:"SERVER_PORT" default:"8080"`
+
+	DBDriver string `env:"DB_DRIVER" default:"mysql"`
+	DBHost   string `env:"DB_HOST"`
+	// DBPort defaults to the driver's usual port.
+	DBPort     int    `env:"DB_PORT"`
+	DBName     string `env:"DB_NAME"`
+	DBUser     string `env:"DB_USER"`
+	DBPassword string `env:"DB_PASSWORD" secret:"true"`
+	// DBAutoMigrate applies pending migrations when the server starts.
+	DBAutoMigrate bool `env:"DB_AUTO_MIGRATE" default:"false"`
+
+	// DBMaxOpenConns limits the connections in the pool; 0 is unlimited.
+	DBMaxOpenConns    int           `env:"DB_MAX_OPEN_CONNS" default:"10"`
+	DBMaxIdleConns    int           `env:"DB_MAX_IDLE_CONNS" default:"5"`
+	DBConnMaxLifetime time.Duration `env:"DB_CONN_MAX_LIFETIME" default:"3m"`
+	DBConnMaxIdleTime time.Duration `env:"DB_CONN_MAX_IDLE_TIME"`
+	// DBDialTimeout limits connecting; DBReadTimeout and DBWriteTimeout limit
+	// each read and write on a MySQL connection. 0 waits forever.
+	DBDialTimeout  time.Duration `env:"DB_DIAL_TIMEOUT" default:"5s"`
+	DBReadTimeout  time.Duration `env:"DB_READ_TIMEOUT"`
+	DBWriteTimeout time.Duration `env:"DB_WRITE_TIMEOUT"`
+	// DBTLS is "false", "true", "skip-verify" or "preferred"; empty leaves the
+	// driver's default. DBTLSCA is a PEM file of the CAs to trust.
+	DBTLS   string `env:"DB_TLS"`
+	DBTLSCA string `env:"DB_TLS_CA"`
+	// DBCharset is the MySQL connection character set.
+	DBCharset string `env:"DB_CHARSET" default:"utf8mb4"`
+	// DBConnectAttempts is how many times the database is tried on startup,
+	// waiting DBConnectBackoff after the first failure, doubling up to
+	// DBConnectMaxBackoff.
+	DBConnectAttempts   int           `env:"DB_CONNECT_ATTEMPTS" default:"10"`
+	DBConnectBackoff    time.Duration `env:"DB_CONNECT_BACKOFF" default:"1s"`
+	DBConnectMaxBackoff time.Duration `env:"DB_CONNECT_MAX_BACKOFF" default:"30s"`
+
+	JWTSecret string `env:"JWT_SECRET" secret:"true"`
+
+	// AuthMode selects how clients carry their token: "bearer" or "cookie".
+	AuthMode       string `env:"AUTH_MODE" default:"bearer"`
+	CookieDomain   string `env:"COOKIE_DOMAIN"`
+	CookieSecure   bool   `env:"COOKIE_SECURE" default:"true"`
+	CookieSameSite string `env:"COOKIE_SAMESITE" default:"lax"`
+
+	// AppURL is the public base URL used in links sent to users. It defaults
+	// to the server's address on localhost.
+	AppURL string `env:"APP_URL"`
+
+	SMTPHost     string `env:"SMTP_HOST"`
+	SMTPPort     int    `env:"SMTP_PORT" default:"587"`
+	SMTPUser     string `env:"SMTP_USER"`
+	SMTPPassword string `env:"SMTP_PASSWORD" secret:"true"`
+	MailFrom     string `env:"MAIL_FROM" default:"no-reply@localhost"`
+
+	WebAuthnRPID   string `env:"WEBAUTHN_RP_ID" default:"localhost"`
+	WebAuthnRPName string `env:"WEBAUTHN_RP_NAME" default:"Go REST API"`
+	// WebAuthnOrigin defaults to AppURL.
+	WebAuthnOrigin string `env:"WEBAUTHN_ORIGIN"`
 
-	ServerHost string
-	ServerPort string
+	PasswordMinLength     int  `env:"PASSWORD_MIN_LENGTH" default:"8"`
+	PasswordMaxLength     int  `env:"PASSWORD_MAX_LENGTH" default:"64"`
+	PasswordRequireUpper  bool `env:"PASSWORD_REQUIRE_UPPER" default:"false"`
+	PasswordRequireLower  bool `env:"PASSWORD_REQUIRE_LOWER" default:"false"`
+	PasswordRequireDigit  bool `env:"PASSWORD_REQUIRE_DIGIT" default:"false"`
+	PasswordRequireSymbol bool `env:"PASSWORD_REQUIRE_SYMBOL" default:"false"`
+	// PasswordBreachedDir holds Have I Been Pwned range files; empty disables the check.
+	PasswordBreachedDir string `env:"PASSWORD_BREACHED_DIR"`
 
-	DBDriver   string
-	DBHost     string
-	DBPort     string
-	DBName     string
-	DBUser     string
-	DBPassword string
+	// PasswordHashAlgorithm is used for new hashes: "argon2id" or "bcrypt".
+	PasswordHashAlgorithm string `env:"PASSWORD_HASH_ALGORITHM" default:"argon2id"`
+	BcryptCost            int    `env:"BCRYPT_COST" default:"10"`
+	// Argon2Memory is in KiB.
+	Argon2Memory      int `env:"ARGON2_MEMORY" default:"65536"`
+	Argon2Iterations  int `env:"ARGON2_ITERATIONS" default:"3"`
+	Argon2Parallelism int `env:"ARGON2_PARALLELISM" default:"2"`
 
-	JWTSecret string
+	// StorageDriver selects where uploads are kept: "local" or "s3".
+	StorageDriver   string `env:"STORAGE_DRIVER" default:"local"`
+	StorageLocalDir string `env:"STORAGE_LOCAL_DIR" default:"uploads"`
+	// StoragePublicURL is the base URL uploaded files are served from.
+	StoragePublicURL string `env:"STORAGE_PUBLIC_URL"`
+	S3Endpoint       string `env:"S3_ENDPOINT" default:"https://s3.amazonaws.com"`
+	S3Region         string `env:"S3_REGION" default:"us-east-1"`
+	S3Bucket         string `env:"S3_BUCKET"`
+	S3AccessKey      string `env:"S3_ACCESS_KEY_ID" secret:"true"`
+	S3SecretKey      string `env:"S3_SECRET_ACCESS_KEY" secret:"true"`
+	S3PathStyle      bool   `env:"S3_PATH_STYLE" default:"false"`
+
+	// MediaMaxBytes limits the size of an uploaded file.
+	MediaMaxBytes      int `env:"MEDIA_MAX_BYTES" default:"5242880"`
+	MediaThumbnailSize int `env:"MEDIA_THUMBNAIL_SIZE" default:"320"`
+	AvatarSize         int `env:"AVATAR_SIZE" default:"256"`
+
+	// EventReplaySize is the number of recent events kept for clients
+	// resuming an event stream.
+	EventReplaySize int `env:"EVENT_REPLAY_SIZE" default:"1000"`
+
+	// WebhookMaxAttempts is the number of attempts before a delivery is given up on.
+	WebhookMaxAttempts int `env:"WEBHOOK_MAX_ATTEMPTS" default:"8"`
+	// WebhookDisableAfter is the number of failed attempts in a row that disables a webhook.
+	WebhookDisableAfter int           `env:"WEBHOOK_DISABLE_AFTER" default:"20"`
+	WebhookTimeout      time.Duration `env:"WEBHOOK_TIMEOUT" default:"10s"`
+	WebhookAllowPrivate bool          `env:"WEBHOOK_ALLOW_PRIVATE" default:"false"`
+
+	// JobWorkers and JobMailWorkers are the number of jobs run at once from
+	// the default and mail queues.
+	JobWorkers     int           `env:"JOB_WORKERS" default:"4"`
+	JobMailWorkers int           `env:"JOB_MAIL_WORKERS" default:"2"`
+	JobTimeout     time.Duration `env:"JOB_TIMEOUT" default:"1m"`
+	// JobRetentionDays is how long finished jobs and published events are kept.
+	JobRetentionDays int `env:"JOB_RETENTION_DAYS" default:"7"`
 }
 
-// Init initializes the configuration by reading from environment variables.
-func Init() *Config {
-	// load env
-	if err := godotenv.Load(); err != nil {
-		log.Fatalf("Error loading .env file: %v", err)
+// Default returns the configuration made of the defaults alone.
+func Default() *Config {
+	cfg := &Config{}
+	for _, field := range fields(cfg) {
+		if err := field.set(field.fallback); err != nil {
+			// the defaults are constants, so this is a programming error
+			panic(fmt.Sprintf("config: invalid default for %s: %v", field.env, err))
+		}
 	}
+	cfg.derive()
 
-	return &Config{
-		AppEnv:     os.Getenv("APP_ENV"),
-		ServerHost: os.Getenv("SERVER_HOST"),
-		ServerPort: os.Getenv("SERVER_PORT"),
-		DBDriver:   os.Getenv("DB_DRIVER"),
-		DBHost:     os.Getenv("DB_HOST"),
-		DBPort:     os.Getenv("DB_PORT"),
-		DBName:     os.Getenv("DB_NAME"),
-		DBUser:     os.Getenv("DB_USER"),
-		DBPassword: os.Getenv("DB_PASSWORD"),
-		JWTSecret:  os.Getenv("JWT_SECRET"),
+	return cfg
+}
+
+// derive fills the fields whose defaults depend on other fields.
+func (c *Config) derive() {
+	if c.DBPort == 0 {
+		switch c.DBDriver {
+		case "mysql":
+			c.DBPort = 3306
+		case "postgres":
+			c.DBPort = 5432
+		}
+	}
+	if c.AppURL == "" {
+		c.AppURL = "http://localhost:" + strconv.Itoa(c.ServerPort)
+	}
+	if c.WebAuthnOrigin == "" {
+		c.WebAuthnOrigin = c.AppURL
 	}
 }
 
-// Global configuration instance
-var Env = Init()
+// Env is the configuration in use. It holds the defaults until the program
+// replaces it with the result of Load.
+var Env = Default()
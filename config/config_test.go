@@ -0,0 +1,216 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// the least environment that passes validation
+func testEnviron() map[string]string {
+	return map[string]string{
+		"DB_DRIVER":  "sqlite",
+		"DB_NAME":    "test.db",
+		"JWT_SECRET": "secret",
+	}
+}
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestDefault(t *testing.T) {
+	cfg := Default()
+
+	if cfg.ServerPort != 8080 {
+		t.Errorf("Expected '%d', got '%d'", 8080, cfg.ServerPort)
+	}
+	if cfg.DBPort != 3306 {
+		t.Errorf("Expected '%d', got '%d'", 3306, cfg.DBPort)
+	}
+	if cfg.AppURL != "http://localhost:8080" {
+		t.Errorf("Expected '%s', got '%s'", "http://localhost:8080", cfg.AppURL)
+	}
+	if cfg.WebAuthnOrigin != cfg.AppURL {
+		t.Errorf("Expected '%s', got '%s'", cfg.AppURL, cfg.WebAuthnOrigin)
+	}
+	if cfg.JobTimeout != time.Minute {
+		t.Errorf("Expected '%v', got '%v'", time.Minute, cfg.JobTimeout)
+	}
+	if !cfg.CookieSecure {
+		t.Error("Expected cookies to be secure by default")
+	}
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	path := writeFile(t, "config.yaml", "server_port: 7000\napp_env: staging\nsmtp:\n  host: mail.example.com\n  port: 2525\n")
+
+	environ := testEnviron()
+	environ["CONFIG_FILE"] = path
+	environ["SERVER_PORT"] = "7001"
+
+	cfg, args, err := load([]string{"-mail-from", "me@example.com", "seed", "-x"}, environ)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg.AppEnv != "staging" {
+		t.Errorf("Expected '%s', got '%s'", "staging", cfg.AppEnv)
+	}
+	if cfg.ServerPort != 7001 {
+		t.Errorf("Expected '%d', got '%d'", 7001, cfg.ServerPort)
+	}
+	if cfg.SMTPHost != "mail.example.com" || cfg.SMTPPort != 2525 {
+		t.Errorf("Expected '%s:%d', got '%s:%d'", "mail.example.com", 2525, cfg.SMTPHost, cfg.SMTPPort)
+	}
+	if cfg.MailFrom != "me@example.com" {
+		t.Errorf("Expected '%s', got '%s'", "me@example.com", cfg.MailFrom)
+	}
+	if strings.Join(args, " ") != "seed -x" {
+		t.Errorf("Expected '%s', got '%s'", "seed -x", strings.Join(args, " "))
+	}
+
+	cfg, _, err = load([]string{"-server-port", "7002"}, environ)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.ServerPort != 7002 {
+		t.Errorf("Expected '%d', got '%d'", 7002, cfg.ServerPort)
+	}
+}
+
+func TestLoadTOML(t *testing.T) {
+	path := writeFile(t, "config.toml", "[job]\nworkers = 9\ntimeout = \"90s\"\n")
+
+	cfg, _, err := load([]string{"-config", path}, testEnviron())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg.JobWorkers != 9 {
+		t.Errorf("Expected '%d', got '%d'", 9, cfg.JobWorkers)
+	}
+	if cfg.JobTimeout != 90*time.Second {
+		t.Errorf("Expected '%v', got '%v'", 90*time.Second, cfg.JobTimeout)
+	}
+}
+
+func TestLoadDurationInSeconds(t *testing.T) {
+	environ := testEnviron()
+	environ["WEBHOOK_TIMEOUT"] = "15"
+
+	cfg, _, err := load(nil, environ)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg.WebhookTimeout != 15*time.Second {
+		t.Errorf("Expected '%v', got '%v'", 15*time.Second, cfg.WebhookTimeout)
+	}
+}
+
+func TestLoadSecretFile(t *testing.T) {
+	environ := testEnviron()
+	delete(environ, "JWT_SECRET")
+	environ["JWT_SECRET_FILE"] = writeFile(t, "jwt", "from-a-file\n")
+
+	cfg, _, err := load(nil, environ)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.JWTSecret != "from-a-file" {
+		t.Errorf("Expected '%s', got '%s'", "from-a-file", cfg.JWTSecret)
+	}
+
+	environ["JWT_SECRET"] = "inline"
+	if _, _, err := load(nil, environ); err == nil {
+		t.Error("Expected an error when a secret is set twice")
+	}
+}
+
+func TestLoadReportsEveryProblem(t *testing.T) {
+	path := writeFile(t, "config.yaml", "db_hots: localhost\n")
+
+	environ := map[string]string{
+		"CONFIG_FILE":     path,
+		"DB_DRIVER":       "oracle",
+		"SERVER_PORT":     "http",
+		"COOKIE_SECURE":   "maybe",
+		"COOKIE_SAMESITE": "none",
+	}
+
+	_, _, err := load(nil, environ)
+
+	var problems ValidationErrors
+	if !errors.As(err, &problems) {
+		t.Fatalf("Expected ValidationErrors, got '%v'", err)
+	}
+
+	for _, want := range []string{"unknown setting db_hots", "DB_DRIVER must be", "SERVER_PORT from the environment", "COOKIE_SECURE from the environment", "DB_NAME is required", "JWT_SECRET is required"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Expected '%s' to be reported, got '%s'", want, err)
+		}
+	}
+}
+
+func TestLoadUnknownFlag(t *testing.T) {
+	if _, _, err := load([]string{"-no-such-flag"}, testEnviron()); err == nil {
+		t.Error("Expected an error for an unknown flag")
+	}
+}
+
+func TestLoadDatabasePool(t *testing.T) {
+	environ := testEnviron()
+	environ["DB_MAX_OPEN_CONNS"] = "2"
+	environ["DB_MAX_IDLE_CONNS"] = "3"
+	environ["DB_TLS"] = "skip-verify"
+	environ["DB_TLS_CA"] = writeFile(t, "ca.pem", "")
+
+	_, _, err := load(nil, environ)
+	if err == nil {
+		t.Fatal("Expected an error")
+	}
+
+	for _, want := range []string{"DB_MAX_IDLE_CONNS cannot be above DB_MAX_OPEN_CONNS", "DB_TLS_CA needs DB_TLS=true"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Expected '%s' to be reported, got '%s'", want, err)
+		}
+	}
+
+	environ["DB_MAX_IDLE_CONNS"] = "2"
+	environ["DB_TLS"] = "true"
+	environ["DB_CONN_MAX_LIFETIME"] = "10m"
+
+	cfg, _, err := load(nil, environ)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.DBConnMaxLifetime != 10*time.Minute {
+		t.Errorf("Expected '%v', got '%v'", 10*time.Minute, cfg.DBConnMaxLifetime)
+	}
+}
+
+func TestLoadProductionNeedsSMTP(t *testing.T) {
+	environ := testEnviron()
+	environ["APP_ENV"] = "production"
+	environ["JWT_SECRET"] = strings.Repeat("x", 32)
+
+	_, _, err := load(nil, environ)
+	if err == nil || !strings.Contains(err.Error(), "SMTP_HOST is required in production") {
+		t.Errorf("Expected '%s' to be reported, got '%v'", "SMTP_HOST is required in production", err)
+	}
+
+	environ["SMTP_HOST"] = "mail.example.com"
+	if _, _, err := load(nil, environ); err != nil {
+		t.Errorf("Expected no error, got '%v'", err)
+	}
+}
@@ -0,0 +1,344 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v3"
+)
+
+// secretFileSuffix names the variable holding the path of a file to read a
+// secret from, e.g. JWT_SECRET_FILE
+const secretFileSuffix = "_FILE"
+
+// field is a setting of a Config
+type field struct {
+	env      string
+	fallback string
+	secret   bool
+	value    reflect.Value
+}
+
+// source is a set of raw values keyed by environment variable name
+type source struct {
+	name   string
+	values map[string]string
+}
+
+// Load builds the configuration from, in increasing order of precedence:
+// the defaults, a YAML or TOML file named by the -config flag or
+// CONFIG_FILE, a .env file, the environment, and command line flags. A
+// secret may be read from the file named by its variable with a _FILE
+// suffix instead, e.g. JWT_SECRET_FILE. Flags stop at the first argument
+// that is not one, and the arguments left over are returned. Every problem
+// found is reported at once as ValidationErrors.
+func Load(args []string) (*Config, []string, error) {
+	environ, err := godotenv.Read()
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, nil, fmt.Errorf("failed to read .env: %w", err)
+	}
+	if environ == nil {
+		environ = map[string]string{}
+	}
+
+	// the real environment wins over .env
+	for _, entry := range os.Environ() {
+		if key, value, ok := strings.Cut(entry, "="); ok {
+			environ[key] = value
+		}
+	}
+
+	return load(args, environ)
+}
+
+// PrintFlags writes the command line flags Load accepts to w.
+func PrintFlags(w io.Writer) {
+	flags, _ := newFlagSet(fields(&Config{}))
+	flags.SetOutput(w)
+	flags.PrintDefaults()
+}
+
+// load builds the configuration from args and the environment variables in
+// environ.
+func load(args []string, environ map[string]string) (*Config, []string, error) {
+	cfg := &Config{}
+	list := fields(cfg)
+
+	flags, path := newFlagSet(list)
+	if err := flags.Parse(args); err != nil {
+		return nil, nil, err
+	}
+
+	var problems ValidationErrors
+
+	// lowest precedence first
+	var layers []source
+	if *path == "" {
+		*path = environ["CONFIG_FILE"]
+	}
+	if *path != "" {
+		file, fileProblems := readFile(*path, list)
+		problems = append(problems, fileProblems...)
+		layers = append(layers, file)
+	}
+	layers = append(layers, source{name: "the environment", values: environ})
+
+	set := source{name: "flags", values: map[string]string{}}
+	flags.Visit(func(f *flag.Flag) {
+		if f.Name != "config" {
+			set.values[strings.ToUpper(strings.ReplaceAll(f.Name, "-", "_"))] = f.Value.String()
+		}
+	})
+	layers = append(layers, set)
+
+	for _, f := range list {
+		raw, from, err := f.resolve(layers)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %v", f.env, err))
+			continue
+		}
+		if err := f.set(raw); err != nil {
+			problems = append(problems, fmt.Sprintf("%s from %s: %v", f.env, from, err))
+			// validate the default instead, so the problem is reported once
+			f.set(f.fallback)
+		}
+	}
+
+	cfg.derive()
+	problems = append(problems, cfg.validate()...)
+
+	if len(problems) > 0 {
+		return nil, nil, problems
+	}
+
+	return cfg, flags.Args(), nil
+}
+
+// fields lists the settings of cfg, which the fields point into.
+func fields(cfg *Config) []field {
+	value := reflect.ValueOf(cfg).Elem()
+	typ := value.Type()
+
+	list := make([]field, 0, typ.NumField())
+	for i := 0; i < typ.NumField(); i++ {
+		tag := typ.Field(i).Tag
+		list = append(list, field{
+			env:      tag.Get("env"),
+			fallback: tag.Get("default"),
+			secret:   tag.Get("secret") == "true",
+			value:    value.Field(i),
+		})
+	}
+
+	return list
+}
+
+// resolve returns the raw value of f from the highest layer that sets it, and
+// the name of that layer. Empty values count as unset.
+func (f field) resolve(layers []source) (string, string, error) {
+	for i := len(layers) - 1; i >= 0; i-- {
+		layer := layers[i]
+		value := layer.values[f.env]
+
+		if f.secret {
+			if path := layer.values[f.env+secretFileSuffix]; path != "" {
+				if value != "" {
+					return "", "", fmt.Errorf("set either %s or %s%s in %s, not both", f.env, f.env, secretFileSuffix, layer.name)
+				}
+
+				secret, err := os.ReadFile(path)
+				if err != nil {
+					return "", "", fmt.Errorf("failed to read the secret file: %w", err)
+				}
+				return strings.TrimRight(string(secret), "\r\n"), path, nil
+			}
+		}
+
+		if value != "" {
+			return value, layer.name, nil
+		}
+	}
+
+	return f.fallback, "the defaults", nil
+}
+
+// kind names the type of the field's values.
+func (f field) kind() string {
+	switch f.value.Interface().(type) {
+	case bool:
+		return "bool"
+	case int:
+		return "number"
+	case time.Duration:
+		return "duration"
+	}
+	return "string"
+}
+
+// set parses raw into the field.
+func (f field) set(raw string) error {
+	switch f.value.Interface().(type) {
+	case string:
+		f.value.SetString(raw)
+	case bool:
+		if raw == "" {
+			f.value.SetBool(false)
+			return nil
+		}
+		value, err := strconv.ParseBool(raw)
+		if err != nil {
+			return fmt.Errorf("expected true or false, got %q", raw)
+		}
+		f.value.SetBool(value)
+	case int:
+		if raw == "" {
+			f.value.SetInt(0)
+			return nil
+		}
+		value, err := strconv.Atoi(raw)
+		if err != nil {
+			return fmt.Errorf("expected a whole number, got %q", raw)
+		}
+		f.value.SetInt(int64(value))
+	case time.Duration:
+		if raw == "" {
+			f.value.SetInt(0)
+			return nil
+		}
+		// a bare number is in seconds, as durations used to be
+		if seconds, err := strconv.Atoi(raw); err == nil {
+			f.value.SetInt(int64(time.Duration(seconds) * time.Second))
+			return nil
+		}
+		value, err := time.ParseDuration(raw)
+		if err != nil {
+			return fmt.Errorf("expected a duration such as 30s or 5m, got %q", raw)
+		}
+		f.value.SetInt(int64(value))
+	default:
+		return fmt.Errorf("unsupported setting type %s", f.value.Type())
+	}
+
+	return nil
+}
+
+// newFlagSet defines a flag for each setting, named after its variable in
+// lower case with dashes. Secrets only get a -<name>-file flag, as
+// arguments are visible to other users of the machine.
+func newFlagSet(list []field) (*flag.FlagSet, *string) {
+	flags := flag.NewFlagSet("go-rest-api", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+
+	path := flags.String("config", "", "path of a YAML or TOML configuration file, instead of CONFIG_FILE")
+	for _, f := range list {
+		name := strings.ToLower(strings.ReplaceAll(f.env, "_", "-"))
+		if f.secret {
+			flags.String(name+"-file", "", "path of a file holding "+f.env)
+			continue
+		}
+		if f.kind() == "bool" {
+			flags.Var(&flagValue{raw: f.fallback, isBool: true}, name, "sets "+f.env)
+			continue
+		}
+		flags.Var(&flagValue{raw: f.fallback}, name, fmt.Sprintf("sets %s to a `%s`", f.env, f.kind()))
+	}
+
+	return flags, path
+}
+
+// flagValue is the raw value of a flag, parsed along with the other sources
+type flagValue struct {
+	raw    string
+	isBool bool
+}
+
+func (v *flagValue) String() string {
+	return v.raw
+}
+
+func (v *flagValue) Set(raw string) error {
+	v.raw = raw
+	return nil
+}
+
+// IsBoolFlag lets boolean flags be given without a value.
+func (v *flagValue) IsBoolFlag() bool {
+	return v.isBool
+}
+
+// readFile reads a YAML or TOML configuration file. Its keys are the
+// variable names in lower case; nested tables join their keys with
+// underscores, so db: {host: x} sets DB_HOST. The problems found are
+// returned along with whatever could be read.
+func readFile(path string, list []field) (source, []string) {
+	file := source{name: path, values: map[string]string{}}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return file, []string{fmt.Sprintf("failed to read the configuration file: %v", err)}
+	}
+
+	tree := map[string]any{}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &tree)
+	case ".toml":
+		err = toml.Unmarshal(data, &tree)
+	default:
+		return file, []string{fmt.Sprintf("%s: unsupported configuration file; use .yaml, .yml or .toml", path)}
+	}
+	if err != nil {
+		return file, []string{fmt.Sprintf("%s: %v", path, err)}
+	}
+
+	known := map[string]bool{}
+	for _, f := range list {
+		known[f.env] = true
+		if f.secret {
+			known[f.env+secretFileSuffix] = true
+		}
+	}
+
+	var problems []string
+	flatten("", tree, file.values, &problems)
+	for key := range file.values {
+		if !known[key] {
+			problems = append(problems, fmt.Sprintf("unknown setting %s", strings.ToLower(key)))
+			delete(file.values, key)
+		}
+	}
+	for i, problem := range problems {
+		problems[i] = path + ": " + problem
+	}
+
+	return file, problems
+}
+
+// flatten copies the values of tree into values, keyed by their upper case
+// path.
+func flatten(prefix string, tree map[string]any, values map[string]string, problems *[]string) {
+	for key, value := range tree {
+		key = prefix + strings.ToUpper(strings.ReplaceAll(key, "-", "_"))
+
+		switch value := value.(type) {
+		case map[string]any:
+			flatten(key+"_", value, values, problems)
+		case []any:
+			*problems = append(*problems, fmt.Sprintf("%s cannot be a list", strings.ToLower(key)))
+		case nil:
+		default:
+			values[key] = fmt.Sprint(value)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// ValidationErrors lists every problem found while loading a configuration.
+type ValidationErrors []string
+
+func (e ValidationErrors) Error() string {
+	return "invalid configuration:\n  - " + strings.Join(e, "\n  - ")
+}
+
+// validate checks the settings against each other and their allowed values.
+func (c *Config) validate() ValidationErrors {
+	var problems ValidationErrors
+	check := func(ok bool, format string, args ...any) {
+		if !ok {
+			problems = append(problems, fmt.Sprintf(format, args...))
+		}
+	}
+	oneOf := func(key, value string, allowed ...string) {
+		for _, option := range allowed {
+			if value == option {
+				return
+			}
+		}
+		last := len(allowed) - 1
+		problems = append(problems, fmt.Sprintf("%s must be %s or %s, not %q", key, strings.Join(allowed[:last], ", "), allowed[last], value))
+	}
+	port := func(key string, value int) {
+		check(value > 0 && value <= 65535, "%s must be a port between 1 and 65535, not %d", key, value)
+	}
+	positive := func(key string, value int) {
+		check(value > 0, "%s must be above 0, not %d", key, value)
+	}
+	absoluteURL := func(key, value string) {
+		u, err := url.Parse(value)
+		check(err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != "", "%s must be an http or https URL, not %q", key, value)
+	}
+
+	port("SERVER_PORT", c.ServerPort)
+
+	oneOf("DB_DRIVER", c.DBDriver, "mysql", "postgres", "sqlite")
+	check(c.DBName != "", "DB_NAME is required")
+	if c.DBDriver == "mysql" || c.DBDriver == "postgres" {
+		check(c.DBHost != "", "DB_HOST is required")
+		check(c.DBUser != "", "DB_USER is required")
+		port("DB_PORT", c.DBPort)
+	}
+	check(c.DBMaxOpenConns >= 0, "DB_MAX_OPEN_CONNS cannot be negative")
+	check(c.DBMaxIdleConns >= 0, "DB_MAX_IDLE_CONNS cannot be negative")
+	check(c.DBMaxOpenConns == 0 || c.DBMaxIdleConns <= c.DBMaxOpenConns, "DB_MAX_IDLE_CONNS cannot be above DB_MAX_OPEN_CONNS")
+	check(c.DBConnMaxLifetime >= 0, "DB_CONN_MAX_LIFETIME cannot be negative")
+	check(c.DBConnMaxIdleTime >= 0, "DB_CONN_MAX_IDLE_TIME cannot be negative")
+	check(c.DBDialTimeout >= 0, "DB_DIAL_TIMEOUT cannot be negative")
+	check(c.DBReadTimeout >= 0, "DB_READ_TIMEOUT cannot be negative")
+	check(c.DBWriteTimeout >= 0, "DB_WRITE_TIMEOUT cannot be negative")
+	if c.DBTLS != "" {
+		oneOf("DB_TLS", c.DBTLS, "false", "true", "skip-verify", "preferred")
+	}
+	if c.DBTLSCA != "" {
+		check(c.DBTLS == "true", "DB_TLS_CA needs DB_TLS=true")
+		_, err := os.Stat(c.DBTLSCA)
+		check(err == nil, "DB_TLS_CA cannot be read: %v", err)
+	}
+	positive("DB_CONNECT_ATTEMPTS", c.DBConnectAttempts)
+	check(c.DBConnectBackoff > 0, "DB_CONNECT_BACKOFF must be above 0")
+	check(c.DBConnectMaxBackoff >= c.DBConnectBackoff, "DB_CONNECT_MAX_BACKOFF must be at least DB_CONNECT_BACKOFF")
+
+	check(c.JWTSecret != "", "JWT_SECRET is required")
+	if c.AppEnv == "production" {
+		check(c.JWTSecret == "" || len(c.JWTSecret) >= 32, "JWT_SECRET must be at least 32 bytes in production")
+	}
+
+	oneOf("AUTH_MODE", c.AuthMode, "bearer", "cookie")
+	oneOf("COOKIE_SAMESITE", strings.ToLower(c.CookieSameSite), "strict", "lax", "none")
+	check(!strings.EqualFold(c.CookieSameSite, "none") || c.CookieSecure, "COOKIE_SAMESITE=none needs COOKIE_SECURE=true")
+
+	absoluteURL("APP_URL", c.AppURL)
+	absoluteURL("WEBAUTHN_ORIGIN", c.WebAuthnOrigin)
+
+	if c.SMTPHost != "" {
+		port("SMTP_PORT", c.SMTPPort)
+	}
+	// without SMTP, sign-in links would only be written to the log
+	check(c.AppEnv != "production" || c.SMTPHost != "", "SMTP_HOST is required in production")
+
+	positive("PASSWORD_MIN_LENGTH", c.PasswordMinLength)
+	check(c.PasswordMaxLength >= c.PasswordMinLength, "PASSWORD_MAX_LENGTH must be at least PASSWORD_MIN_LENGTH")
+	oneOf("PASSWORD_HASH_ALGORITHM", c.PasswordHashAlgorithm, "argon2id", "bcrypt")
+	check(c.BcryptCost >= 4 && c.BcryptCost <= 31, "BCRYPT_COST must be between 4 and 31, not %d", c.BcryptCost)
+	positive("ARGON2_ITERATIONS", c.Argon2Iterations)
+	check(c.Argon2Parallelism > 0 && c.Argon2Parallelism <= 255, "ARGON2_PARALLELISM must be between 1 and 255, not %d", c.Argon2Parallelism)
+	check(c.Argon2Memory >= 8*c.Argon2Parallelism, "ARGON2_MEMORY must be at least 8 KiB per ARGON2_PARALLELISM")
+
+	oneOf("STORAGE_DRIVER", c.StorageDriver, "local", "s3")
+	if c.StorageDriver == "s3" {
+		check(c.S3Bucket != "", "S3_BUCKET is required when STORAGE_DRIVER is s3")
+		absoluteURL("S3_ENDPOINT", c.S3Endpoint)
+	}
+	if c.StoragePublicURL != "" {
+		absoluteURL("STORAGE_PUBLIC_URL", c.StoragePublicURL)
+	}
+
+	positive("MEDIA_MAX_BYTES", c.MediaMaxBytes)
+	positive("MEDIA_THUMBNAIL_SIZE", c.MediaThumbnailSize)
+	positive("AVATAR_SIZE", c.AvatarSize)
+	positive("EVENT_REPLAY_SIZE", c.EventReplaySize)
+
+	positive("WEBHOOK_MAX_ATTEMPTS", c.WebhookMaxAttempts)
+	positive("WEBHOOK_DISABLE_AFTER", c.WebhookDisableAfter)
+	check(c.WebhookTimeout > 0, "WEBHOOK_TIMEOUT must be above 0")
+
+	positive("JOB_WORKERS", c.JobWorkers)
+	positive("JOB_MAIL_WORKERS", c.JobMailWorkers)
+	check(c.JobTimeout > 0, "JOB_TIMEOUT must be above 0")
+	positive("JOB_RETENTION_DAYS", c.JobRetentionDays)
+
+	return problems
+}
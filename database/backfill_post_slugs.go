@@ -0,0 +1,124 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+	"strings"
+
+	"github.com/achintha-dilshan/go-rest-api/internal/utils/slug"
+	"github.com/pressly/goose/v3"
+)
+
+// the version of the migration replacing the post-<id> slugs given to
+// existing posts with ones made from their titles
+const backfillPostSlugsVersion = 20261019110000
+
+// newBackfillPostSlugs creates the migration replacing the post-<id> slugs
+// given to existing posts when slugs were added. Each post gets a slug made
+// from its title, with the lowest free numeric suffix, and keeps post-<id> as
+// a former slug so links to it still resolve. Rolling back leaves the slugs
+// as they are.
+func newBackfillPostSlugs(driver string) *goose.Migration {
+	// queries are written with ? and numbered for PostgreSQL
+	bind := func(query string) string {
+		if driver != "postgres" {
+			return query
+		}
+
+		var b strings.Builder
+		n := 0
+		for _, r := range query {
+			if r == '?' {
+				n++
+				b.WriteString("$" + strconv.Itoa(n))
+				continue
+			}
+			b.WriteRune(r)
+		}
+		return b.String()
+	}
+
+	up := func(ctx context.Context, tx *sql.Tx) error {
+		taken, err := querySlugs(ctx, tx, "SELECT slug FROM posts UNION SELECT slug FROM post_slugs")
+		if err != nil {
+			return err
+		}
+
+		type post struct {
+			id    int64
+			title string
+		}
+		var posts []post
+
+		rows, err := tx.QueryContext(ctx, "SELECT id, title, slug FROM posts WHERE slug LIKE 'post-%' ORDER BY id")
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var p post
+			var current string
+			if err := rows.Scan(&p.id, &p.title, &current); err != nil {
+				return err
+			}
+			// only the slugs the earlier migration made
+			if current == "post-"+strconv.FormatInt(p.id, 10) {
+				posts = append(posts, p)
+			}
+		}
+		if err := rows.Err(); err != nil {
+			return err
+		}
+		rows.Close()
+
+		for _, p := range posts {
+			old := "post-" + strconv.FormatInt(p.id, 10)
+			base := slug.Make(p.title)
+
+			candidate := base
+			for n := 2; taken[candidate]; n++ {
+				candidate = base + "-" + strconv.Itoa(n)
+			}
+			if candidate == old {
+				continue
+			}
+			taken[candidate] = true
+
+			if _, err := tx.ExecContext(ctx, bind("UPDATE posts SET slug = ? WHERE id = ?"), candidate, p.id); err != nil {
+				return err
+			}
+			if _, err := tx.ExecContext(ctx, bind("INSERT INTO post_slugs (slug, post_id) VALUES (?, ?)"), old, p.id); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	migration := goose.NewGoMigration(backfillPostSlugsVersion, &goose.GoFunc{RunTx: up}, nil)
+	migration.Source = strconv.Itoa(backfillPostSlugsVersion) + "_backfill_post_slugs.go"
+	return migration
+}
+
+// querySlugs runs a query selecting slugs and collects them into a set
+func querySlugs(ctx context.Context, tx *sql.Tx, query string) (map[string]bool, error) {
+	slugs := map[string]bool{}
+
+	rows, err := tx.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var value string
+		if err := rows.Scan(&value); err != nil {
+			return nil, err
+		}
+		slugs[value] = true
+	}
+
+	return slugs, rows.Err()
+}
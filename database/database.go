@@ -1,16 +1,38 @@
 package database
 
 import (
+	"crypto/tls"
+	"crypto/x509"
 	"database/sql"
 	"errors"
 	"fmt"
 	"log"
+	"math"
+	"net"
+	"net/url"
+	"os"
+	"strconv"
 	"sync"
+	"time"
 
 	"github.com/achintha-dilshan/go-rest-api/config"
+	"github.com/achintha-dilshan/go-rest-api/internal/utils/backoff"
 	"github.com/go-sql-driver/mysql"
+	_ "github.com/jackc/pgx/v5/stdlib"
+	_ "modernc.org/sqlite"
 )
 
+// the name the MySQL TLS configuration trusting DB_TLS_CA is registered under
+const mysqlTLSConfig = "custom"
+
+// the PostgreSQL sslmode matching each DB_TLS value
+var postgresSSLModes = map[string]string{
+	"false":       "disable",
+	"true":        "verify-full",
+	"skip-verify": "require",
+	"preferred":   "prefer",
+}
+
 type database struct {
 	db       *sql.DB
 	once     sync.Once
@@ -31,22 +53,14 @@ func NewDatabase() *database {
 func (h *database) Connect() error {
 	var initErr error
 	h.once.Do(func() {
-		if config.Env.DBHost == "" || config.Env.DBPort == "" || config.Env.DBUser == "" || config.Env.DBName == "" {
-			initErr = errors.New("database configuration is incomplete")
+		driver, dsn, err := dataSource()
+		if err != nil {
+			initErr = err
 			log.Println(initErr)
 			return
 		}
 
-		dbConfig := mysql.Config{
-			Addr:                 fmt.Sprintf("%s:%s", config.Env.DBHost, config.Env.DBPort),
-			DBName:               config.Env.DBName,
-			User:                 config.Env.DBUser,
-			Passwd:               config.Env.DBPassword,
-			Net:                  "tcp",
-			AllowNativePasswords: true,
-		}
-
-		db, err := sql.Open("mysql", dbConfig.FormatDSN())
+		db, err := sql.Open(driver, dsn)
 		if err != nil {
 			initErr = fmt.Errorf("failed to connect to the database: %w", err)
 			log.Println(initErr)
@@ -54,11 +68,17 @@ func (h *database) Connect() error {
 		}
 
 		// Configure connection pool settings
-		db.SetMaxOpenConns(10)
-		db.SetMaxIdleConns(5)
-		db.SetConnMaxIdleTime(0)
+		db.SetMaxOpenConns(config.Env.DBMaxOpenConns)
+		db.SetMaxIdleConns(config.Env.DBMaxIdleConns)
+		db.SetConnMaxLifetime(config.Env.DBConnMaxLifetime)
+		db.SetConnMaxIdleTime(config.Env.DBConnMaxIdleTime)
+		if driver == "sqlite" {
+			// SQLite allows one writer at a time
+			db.SetMaxOpenConns(1)
+		}
 
-		if err := db.Ping(); err != nil {
+		if err := ping(db); err != nil {
+			db.Close()
 			initErr = fmt.Errorf("failed to verify the database connection: %w", err)
 			log.Println(initErr)
 			return
@@ -71,6 +91,122 @@ func (h *database) Connect() error {
 	return initErr
 }
 
+// ping waits for the database to answer, retrying with backoff so the API can
+// start alongside a database that is still starting.
+func ping(db *sql.DB) error {
+	attempts := config.Env.DBConnectAttempts
+	for attempt := 1; ; attempt++ {
+		err := db.Ping()
+		if err == nil || attempt >= attempts {
+			return err
+		}
+
+		delay := backoff.Jitter(backoff.Exponential(attempt, config.Env.DBConnectBackoff, config.Env.DBConnectMaxBackoff))
+		log.Printf("Database is not reachable (attempt %d of %d), retrying in %v: %v", attempt, attempts, delay.Round(time.Millisecond), err)
+		time.Sleep(delay)
+	}
+}
+
+// dataSource returns the driver name and data source name for the
+// configured database.
+func dataSource() (string, string, error) {
+	switch config.Env.DBDriver {
+	case "mysql":
+		if config.Env.DBHost == "" || config.Env.DBPort == 0 || config.Env.DBUser == "" || config.Env.DBName == "" {
+			return "", "", errors.New("database configuration is incomplete")
+		}
+
+		dbConfig := mysql.Config{
+			Addr:                 net.JoinHostPort(config.Env.DBHost, strconv.Itoa(config.Env.DBPort)),
+			DBName:               config.Env.DBName,
+			User:                 config.Env.DBUser,
+			Passwd:               config.Env.DBPassword,
+			Net:                  "tcp",
+			AllowNativePasswords: true,
+			ParseTime:            true,
+			Loc:                  time.UTC,
+			Timeout:              config.Env.DBDialTimeout,
+			ReadTimeout:          config.Env.DBReadTimeout,
+			WriteTimeout:         config.Env.DBWriteTimeout,
+			TLSConfig:            config.Env.DBTLS,
+			// keep CURRENT_TIMESTAMP in the same zone as the parsed times
+			Params: map[string]string{"time_zone": "'+00:00'"},
+		}
+		if config.Env.DBCharset != "" {
+			dbConfig.Params["charset"] = config.Env.DBCharset
+		}
+
+		if config.Env.DBTLSCA != "" {
+			tlsConfig, err := trustedCAs()
+			if err != nil {
+				return "", "", err
+			}
+			if err := mysql.RegisterTLSConfig(mysqlTLSConfig, tlsConfig); err != nil {
+				return "", "", err
+			}
+			dbConfig.TLSConfig = mysqlTLSConfig
+		}
+
+		return "mysql", dbConfig.FormatDSN(), nil
+
+	case "postgres":
+		if config.Env.DBHost == "" || config.Env.DBPort == 0 || config.Env.DBUser == "" || config.Env.DBName == "" {
+			return "", "", errors.New("database configuration is incomplete")
+		}
+
+		query := url.Values{"timezone": {"UTC"}}
+		if config.Env.DBDialTimeout > 0 {
+			// connect_timeout is in whole seconds
+			query.Set("connect_timeout", strconv.Itoa(int(math.Ceil(config.Env.DBDialTimeout.Seconds()))))
+		}
+		if config.Env.DBTLS != "" {
+			query.Set("sslmode", postgresSSLModes[config.Env.DBTLS])
+		}
+		if config.Env.DBTLSCA != "" {
+			query.Set("sslrootcert", config.Env.DBTLSCA)
+		}
+
+		dsn := url.URL{
+			Scheme:   "postgres",
+			User:     url.UserPassword(config.Env.DBUser, config.Env.DBPassword),
+			Host:     net.JoinHostPort(config.Env.DBHost, strconv.Itoa(config.Env.DBPort)),
+			Path:     config.Env.DBName,
+			RawQuery: query.Encode(),
+		}
+		return "pgx", dsn.String(), nil
+
+	case "sqlite":
+		if config.Env.DBName == "" {
+			return "", "", errors.New("database configuration is incomplete")
+		}
+
+		// DB_NAME is the path of the database file. Foreign keys are enforced
+		// and writers wait on each other rather than failing.
+		return "sqlite", "file:" + config.Env.DBName + "?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)", nil
+	}
+
+	return "", "", fmt.Errorf("unsupported database driver %q", config.Env.DBDriver)
+}
+
+// trustedCAs returns a TLS configuration trusting the CAs in DB_TLS_CA.
+func trustedCAs() (*tls.Config, error) {
+	pem, err := os.ReadFile(config.Env.DBTLSCA)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the database CA: %w", err)
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no PEM certificates found in %s", config.Env.DBTLSCA)
+	}
+
+	return &tls.Config{
+		RootCAs:    pool,
+		ServerName: config.Env.DBHost,
+		MinVersion: tls.VersionTLS12,
+	}, nil
+}
+
 func (h *database) Close() error {
 	h.mu.Lock()
 	defer h.mu.Unlock()
@@ -0,0 +1,194 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"time"
+
+	"github.com/achintha-dilshan/go-rest-api/config"
+	"github.com/pressly/goose/v3"
+)
+
+// the migrations of every driver, built into the binary
+//
+//go:embed migrations
+var migrations embed.FS
+
+// how long a migration waits for another instance to finish migrating
+const migrationLockTimeout = 5 * time.Minute
+
+// the name of the lock held while migrating, and its key for PostgreSQL
+const (
+	migrationLockName = "go_rest_api_migrations"
+	migrationLockKey  = 7246198031
+)
+
+// MigrationStatus is a migration and whether it has been applied.
+type MigrationStatus struct {
+	Version   int64
+	Name      string
+	Applied   bool
+	AppliedAt time.Time
+}
+
+type migrator struct {
+	db       *sql.DB
+	driver   string
+	provider *goose.Provider
+}
+
+type Migrator interface {
+	Up(ctx context.Context) error
+	Down(ctx context.Context) error
+	To(ctx context.Context, version int64) error
+	Status(ctx context.Context) ([]MigrationStatus, error)
+}
+
+// NewMigrator creates a migrator applying the embedded migrations of the
+// configured driver to db.
+func NewMigrator(db *sql.DB) (Migrator, error) {
+	dialects := map[string]goose.Dialect{
+		"mysql":    goose.DialectMySQL,
+		"postgres": goose.DialectPostgres,
+		"sqlite":   goose.DialectSQLite3,
+	}
+
+	driver := config.Env.DBDriver
+	dialect, ok := dialects[driver]
+	if !ok {
+		return nil, fmt.Errorf("unsupported database driver %q", driver)
+	}
+
+	fsys, err := fs.Sub(migrations, "migrations/"+driver)
+	if err != nil {
+		return nil, err
+	}
+
+	provider, err := goose.NewProvider(dialect, db, fsys,
+		goose.WithGoMigrations(newBackfillPostSlugs(driver)),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load the migrations: %w", err)
+	}
+
+	return &migrator{
+		db:       db,
+		driver:   driver,
+		provider: provider,
+	}, nil
+}
+
+// apply every pending migration
+func (m *migrator) Up(ctx context.Context) error {
+	return m.withLock(ctx, func() error {
+		results, err := m.provider.Up(ctx)
+		logResults(results)
+		return err
+	})
+}
+
+// roll back the latest migration
+func (m *migrator) Down(ctx context.Context) error {
+	return m.withLock(ctx, func() error {
+		result, err := m.provider.Down(ctx)
+		if result != nil {
+			logResults([]*goose.MigrationResult{result})
+		}
+		return err
+	})
+}
+
+// migrate up or down to version; 0 rolls back every migration
+func (m *migrator) To(ctx context.Context, version int64) error {
+	return m.withLock(ctx, func() error {
+		current, err := m.provider.GetDBVersion(ctx)
+		if err != nil {
+			return err
+		}
+
+		var results []*goose.MigrationResult
+		if version >= current {
+			results, err = m.provider.UpTo(ctx, version)
+		} else {
+			results, err = m.provider.DownTo(ctx, version)
+		}
+		logResults(results)
+		return err
+	})
+}
+
+// list every migration and whether it has been applied
+func (m *migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	statuses, err := m.provider.Status(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	list := make([]MigrationStatus, len(statuses))
+	for i, status := range statuses {
+		list[i] = MigrationStatus{
+			Version:   status.Source.Version,
+			Name:      status.Source.Path,
+			Applied:   status.State == goose.StateApplied,
+			AppliedAt: status.AppliedAt,
+		}
+	}
+
+	return list, nil
+}
+
+// withLock runs fn holding a database lock, so instances starting together
+// do not apply the same migrations at once. SQLite allows one writer at a
+// time and needs no lock of its own.
+func (m *migrator) withLock(ctx context.Context, fn func() error) error {
+	if m.driver == "sqlite" {
+		return fn()
+	}
+
+	lockCtx, cancel := context.WithTimeout(ctx, migrationLockTimeout)
+	defer cancel()
+
+	// the lock belongs to a session, so it is taken and released on one connection
+	conn, err := m.db.Conn(lockCtx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if m.driver == "postgres" {
+		if _, err := conn.ExecContext(lockCtx, "SELECT pg_advisory_lock($1)", migrationLockKey); err != nil {
+			return fmt.Errorf("failed to take the migration lock: %w", err)
+		}
+		defer conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", migrationLockKey)
+
+		return fn()
+	}
+
+	var locked sql.NullInt64
+	err = conn.QueryRowContext(lockCtx, "SELECT GET_LOCK(?, ?)", migrationLockName, int(migrationLockTimeout.Seconds())).Scan(&locked)
+	if err != nil {
+		return fmt.Errorf("failed to take the migration lock: %w", err)
+	}
+	if locked.Int64 != 1 {
+		return errors.New("timed out waiting for the migration lock")
+	}
+	defer conn.ExecContext(context.Background(), "SELECT RELEASE_LOCK(?)", migrationLockName)
+
+	return fn()
+}
+
+// logResults logs the migrations that were applied or rolled back.
+func logResults(results []*goose.MigrationResult) {
+	for _, result := range results {
+		direction := "Applied"
+		if result.Direction == "down" {
+			direction = "Rolled back"
+		}
+		log.Printf("%s migration %s in %v", direction, result.Source.Path, result.Duration.Round(time.Millisecond))
+	}
+}
@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE users
+    ADD COLUMN role VARCHAR(20) NOT NULL DEFAULT 'user' AFTER password,
+    ADD COLUMN suspended_at TIMESTAMP NULL DEFAULT NULL AFTER role,
+    ADD COLUMN password_reset_required BOOLEAN NOT NULL DEFAULT FALSE AFTER suspended_at;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE users
+    DROP COLUMN password_reset_required,
+    DROP COLUMN suspended_at,
+    DROP COLUMN role;
+-- +goose StatementEnd
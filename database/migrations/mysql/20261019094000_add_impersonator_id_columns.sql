@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE sessions
+    ADD COLUMN impersonator_id INT NULL DEFAULT NULL AFTER user_id,
+    ADD CONSTRAINT fk_session_impersonator FOREIGN KEY (impersonator_id) REFERENCES users(id) ON DELETE CASCADE ON UPDATE CASCADE;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE audit_logs
+    ADD COLUMN impersonator_id INT NULL DEFAULT NULL AFTER actor_id;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE audit_logs
+    DROP COLUMN impersonator_id;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE sessions
+    DROP FOREIGN KEY fk_session_impersonator,
+    DROP COLUMN impersonator_id;
+-- +goose StatementEnd
@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE audit_logs
+    ADD COLUMN request_id VARCHAR(64) NOT NULL DEFAULT '' AFTER ip_address,
+    ADD COLUMN before_data JSON NULL AFTER metadata,
+    ADD COLUMN after_data JSON NULL AFTER before_data,
+    ADD INDEX idx_audit_logs_action (action),
+    ADD INDEX idx_audit_logs_request_id (request_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE audit_logs
+    DROP INDEX idx_audit_logs_request_id,
+    DROP INDEX idx_audit_logs_action,
+    DROP COLUMN after_data,
+    DROP COLUMN before_data,
+    DROP COLUMN request_id;
+-- +goose StatementEnd
@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE users
+    ADD COLUMN bio VARCHAR(500) NOT NULL DEFAULT '' AFTER email,
+    ADD COLUMN avatar_url VARCHAR(2048) NOT NULL DEFAULT '' AFTER bio;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE users
+    DROP COLUMN avatar_url,
+    DROP COLUMN bio;
+-- +goose StatementEnd
@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE posts
+    MODIFY COLUMN body MEDIUMTEXT NOT NULL,
+    ADD COLUMN format VARCHAR(16) NOT NULL DEFAULT 'plain' AFTER body;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE posts
+    DROP COLUMN format,
+    MODIFY COLUMN body VARCHAR(255) NOT NULL;
+-- +goose StatementEnd
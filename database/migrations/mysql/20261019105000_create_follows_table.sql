@@ -0,0 +1,33 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS follows (
+    follower_id INT NOT NULL,
+    followee_id INT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (follower_id, followee_id),
+    INDEX idx_follows_followee (followee_id, created_at),
+    CONSTRAINT chk_follows_self CHECK (follower_id <> followee_id),
+    CONSTRAINT fk_follow_follower FOREIGN KEY (follower_id) REFERENCES users(id) ON DELETE CASCADE ON UPDATE CASCADE,
+    CONSTRAINT fk_follow_followee FOREIGN KEY (followee_id) REFERENCES users(id) ON DELETE CASCADE ON UPDATE CASCADE
+);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+-- feed queries read posts newest first, per author or across all authors
+ALTER TABLE posts
+    ADD INDEX idx_posts_author_created (author_id, created_at, id),
+    ADD INDEX idx_posts_created (created_at, id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- MySQL may have dropped the foreign key's own index in favour of the new one
+ALTER TABLE posts
+    ADD INDEX idx_posts_author (author_id),
+    DROP INDEX idx_posts_created,
+    DROP INDEX idx_posts_author_created;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+DROP TABLE IF EXISTS follows;
+-- +goose StatementEnd
@@ -0,0 +1,9 @@
+-- +goose Up
+-- +goose StatementBegin
+-- emails are stored lower case from now on; the unique index already
+-- ignores case under the default collation
+UPDATE users SET email = LOWER(TRIM(email));
+-- +goose StatementEnd
+
+-- +goose Down
+-- the original case is not kept, so there is nothing to restore
@@ -0,0 +1,24 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS audit_logs (
+    id BIGSERIAL PRIMARY KEY,
+    actor_id INT NULL,
+    action VARCHAR(64) NOT NULL,
+    target_type VARCHAR(32) NOT NULL,
+    target_id BIGINT NULL,
+    ip_address VARCHAR(45) NOT NULL DEFAULT '',
+    metadata JSON NULL,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE INDEX idx_audit_logs_actor ON audit_logs (actor_id);
+CREATE INDEX idx_audit_logs_target ON audit_logs (target_type, target_id);
+CREATE INDEX idx_audit_logs_created_at ON audit_logs (created_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS audit_logs;
+-- +goose StatementEnd
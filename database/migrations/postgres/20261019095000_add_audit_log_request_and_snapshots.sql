@@ -0,0 +1,25 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE audit_logs
+    ADD COLUMN request_id VARCHAR(64) NOT NULL DEFAULT '',
+    ADD COLUMN before_data JSON NULL,
+    ADD COLUMN after_data JSON NULL;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE INDEX idx_audit_logs_action ON audit_logs (action);
+CREATE INDEX idx_audit_logs_request_id ON audit_logs (request_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_audit_logs_request_id;
+DROP INDEX IF EXISTS idx_audit_logs_action;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE audit_logs
+    DROP COLUMN after_data,
+    DROP COLUMN before_data,
+    DROP COLUMN request_id;
+-- +goose StatementEnd
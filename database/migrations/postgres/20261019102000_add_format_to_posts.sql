@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE posts
+    ALTER COLUMN body TYPE TEXT,
+    ADD COLUMN format VARCHAR(16) NOT NULL DEFAULT 'plain';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE posts
+    DROP COLUMN format,
+    ALTER COLUMN body TYPE VARCHAR(255);
+-- +goose StatementEnd
@@ -0,0 +1,32 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS follows (
+    follower_id INT NOT NULL,
+    followee_id INT NOT NULL,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (follower_id, followee_id),
+    CONSTRAINT chk_follows_self CHECK (follower_id <> followee_id),
+    CONSTRAINT fk_follow_follower FOREIGN KEY (follower_id) REFERENCES users(id) ON DELETE CASCADE ON UPDATE CASCADE,
+    CONSTRAINT fk_follow_followee FOREIGN KEY (followee_id) REFERENCES users(id) ON DELETE CASCADE ON UPDATE CASCADE
+);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE INDEX idx_follows_followee ON follows (followee_id, created_at);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+-- feed queries read posts newest first, per author or across all authors
+CREATE INDEX idx_posts_author_created ON posts (author_id, created_at, id);
+CREATE INDEX idx_posts_created ON posts (created_at, id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_posts_created;
+DROP INDEX IF EXISTS idx_posts_author_created;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+DROP TABLE IF EXISTS follows;
+-- +goose StatementEnd
@@ -0,0 +1,53 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS outbox (
+    id BIGSERIAL PRIMARY KEY,
+    event VARCHAR(64) NOT NULL,
+    payload JSON NOT NULL,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    published_at TIMESTAMPTZ NULL DEFAULT NULL
+);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE INDEX idx_outbox_published ON outbox (published_at, id);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS jobs (
+    id BIGSERIAL PRIMARY KEY,
+    queue VARCHAR(32) NOT NULL,
+    type VARCHAR(64) NOT NULL,
+    payload JSON NULL,
+    unique_key VARCHAR(191) NULL,
+    status VARCHAR(16) NOT NULL DEFAULT 'pending',
+    attempts INT NOT NULL DEFAULT 0,
+    max_attempts INT NOT NULL,
+    last_error VARCHAR(1000) NOT NULL DEFAULT '',
+    run_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    locked_until TIMESTAMPTZ NULL DEFAULT NULL,
+    finished_at TIMESTAMPTZ NULL DEFAULT NULL,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ DEFAULT NULL,
+    CONSTRAINT idx_jobs_unique_key UNIQUE (unique_key)
+);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE INDEX idx_jobs_due ON jobs (queue, status, run_at);
+CREATE INDEX idx_jobs_status ON jobs (status, id);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER jobs_updated_at BEFORE UPDATE ON jobs
+    FOR EACH ROW EXECUTE FUNCTION set_updated_at();
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS jobs;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+DROP TABLE IF EXISTS outbox;
+-- +goose StatementEnd
@@ -0,0 +1,25 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE users ADD COLUMN role VARCHAR(20) NOT NULL DEFAULT 'user';
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE users ADD COLUMN suspended_at TIMESTAMP NULL DEFAULT NULL;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE users ADD COLUMN password_reset_required BOOLEAN NOT NULL DEFAULT FALSE;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE users DROP COLUMN password_reset_required;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE users DROP COLUMN suspended_at;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE users DROP COLUMN role;
+-- +goose StatementEnd
@@ -0,0 +1,44 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE sessions ADD COLUMN impersonator_id INTEGER NULL DEFAULT NULL CONSTRAINT fk_session_impersonator REFERENCES users(id) ON DELETE CASCADE ON UPDATE CASCADE;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE audit_logs ADD COLUMN impersonator_id INTEGER NULL DEFAULT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE audit_logs DROP COLUMN impersonator_id;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+-- SQLite cannot drop a column with a foreign key, so the table is rebuilt
+CREATE TABLE sessions_rebuild (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER NOT NULL,
+    user_agent VARCHAR(255) NOT NULL DEFAULT '',
+    ip_address VARCHAR(45) NOT NULL DEFAULT '',
+    last_seen_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    revoked_at TIMESTAMP NULL DEFAULT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_session_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE ON UPDATE CASCADE
+);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+INSERT INTO sessions_rebuild (id, user_id, user_agent, ip_address, last_seen_at, revoked_at, created_at)
+    SELECT id, user_id, user_agent, ip_address, last_seen_at, revoked_at, created_at FROM sessions;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+DROP TABLE sessions;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE sessions_rebuild RENAME TO sessions;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE INDEX IF NOT EXISTS idx_sessions_user ON sessions (user_id);
+-- +goose StatementEnd
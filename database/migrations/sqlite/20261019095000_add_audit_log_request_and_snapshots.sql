@@ -0,0 +1,41 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE audit_logs ADD COLUMN request_id VARCHAR(64) NOT NULL DEFAULT '';
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE audit_logs ADD COLUMN before_data TEXT NULL;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE audit_logs ADD COLUMN after_data TEXT NULL;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE INDEX IF NOT EXISTS idx_audit_logs_action ON audit_logs (action);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE INDEX IF NOT EXISTS idx_audit_logs_request_id ON audit_logs (request_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_audit_logs_request_id;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_audit_logs_action;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE audit_logs DROP COLUMN after_data;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE audit_logs DROP COLUMN before_data;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE audit_logs DROP COLUMN request_id;
+-- +goose StatementEnd
@@ -0,0 +1,10 @@
+-- +goose Up
+-- +goose StatementBegin
+-- body is TEXT from the start, SQLite does not limit its length anyway
+ALTER TABLE posts ADD COLUMN format VARCHAR(16) NOT NULL DEFAULT 'plain';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE posts DROP COLUMN format;
+-- +goose StatementEnd
@@ -0,0 +1,37 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE posts ADD COLUMN slug VARCHAR(191) NULL;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+-- a placeholder until backfill_post_slugs.go makes slugs from the titles
+UPDATE posts SET slug = 'post-' || id;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+-- SQLite cannot add NOT NULL to an existing column, the unique index is
+-- what posts rely on
+CREATE UNIQUE INDEX IF NOT EXISTS idx_posts_slug ON posts (slug);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS post_slugs (
+    slug VARCHAR(191) PRIMARY KEY,
+    post_id INTEGER NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_post_slug_post FOREIGN KEY (post_id) REFERENCES posts(id) ON DELETE CASCADE ON UPDATE CASCADE
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS post_slugs;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_posts_slug;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE posts DROP COLUMN slug;
+-- +goose StatementEnd
@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+-- emails are stored lower case from now on; this fails if two accounts
+-- differ only in the case of their email, which have to be merged by hand
+UPDATE users SET email = LOWER(TRIM(email));
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_email_nocase ON users (email COLLATE NOCASE);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_users_email_nocase;
+-- +goose StatementEnd
@@ -0,0 +1,387 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/achintha-dilshan/go-rest-api/internal/models"
+	"github.com/achintha-dilshan/go-rest-api/internal/repositories"
+	"github.com/achintha-dilshan/go-rest-api/internal/services"
+	"github.com/achintha-dilshan/go-rest-api/internal/types"
+	"github.com/achintha-dilshan/go-rest-api/internal/utils/jwt"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+)
+
+type adminHandler struct {
+	users    services.UserService
+	posts    services.PostService
+	sessions services.SessionService
+	audit    services.AuditService
+}
+
+type AdminHandler interface {
+	GetUsers(w http.ResponseWriter, r *http.Request)
+	GetUser(w http.ResponseWriter, r *http.Request)
+	GetUserPosts(w http.ResponseWriter, r *http.Request)
+	GetUserSessions(w http.ResponseWriter, r *http.Request)
+	SuspendUser(w http.ResponseWriter, r *http.Request)
+	UnsuspendUser(w http.ResponseWriter, r *http.Request)
+	ForcePasswordReset(w http.ResponseWriter, r *http.Request)
+	DeleteUser(w http.ResponseWriter, r *http.Request)
+	Impersonate(w http.ResponseWriter, r *http.Request)
+	GetAuditLogs(w http.ResponseWriter, r *http.Request)
+}
+
+func NewAdminHandler(users services.UserService, posts services.PostService, sessions services.SessionService, audit services.AuditService) AdminHandler {
+	return &adminHandler{
+		users:    users,
+		posts:    posts,
+		sessions: sessions,
+		audit:    audit,
+	}
+}
+
+// list users, optionally filtered by name or email
+func (h *adminHandler) GetUsers(w http.ResponseWriter, r *http.Request) {
+	page, perPage := paginate(r)
+
+	users, total, err := h.users.SearchUsers(r.Context(), r.URL.Query().Get("search"), perPage, (page-1)*perPage)
+	if err != nil {
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{
+			"error": "Internal server error.",
+		})
+		return
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, map[string]interface{}{
+		"users":    users,
+		"page":     page,
+		"per_page": perPage,
+		"total":    total,
+	})
+}
+
+// get a single user
+func (h *adminHandler) GetUser(w http.ResponseWriter, r *http.Request) {
+	user, ok := h.findUser(w, r)
+	if !ok {
+		return
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, map[string]interface{}{
+		"user": user,
+	})
+}
+
+// get the posts of a user
+func (h *adminHandler) GetUserPosts(w http.ResponseWriter, r *http.Request) {
+	user, ok := h.findUser(w, r)
+	if !ok {
+		return
+	}
+
+	posts, err := h.posts.FindPostsByAuthor(r.Context(), user.Id)
+	if err != nil {
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{
+			"error": "Internal server error.",
+		})
+		return
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, map[string]interface{}{
+		"posts": posts,
+	})
+}
+
+// get the active sessions of a user
+func (h *adminHandler) GetUserSessions(w http.ResponseWriter, r *http.Request) {
+	user, ok := h.findUser(w, r)
+	if !ok {
+		return
+	}
+
+	sessions, err := h.sessions.FindUserSessions(r.Context(), user.Id)
+	if err != nil {
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{
+			"error": "Internal server error.",
+		})
+		return
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, map[string]interface{}{
+		"sessions": sessions,
+	})
+}
+
+// suspend a user
+func (h *adminHandler) SuspendUser(w http.ResponseWriter, r *http.Request) {
+	user, ok := h.findOtherUser(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.users.SuspendUser(r.Context(), user.Id); err != nil {
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{
+			"error": "Internal server error.",
+		})
+		return
+	}
+
+	// send success response
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, map[string]interface{}{
+		"message": "User suspended successfully.",
+	})
+}
+
+// lift a user's suspension
+func (h *adminHandler) UnsuspendUser(w http.ResponseWriter, r *http.Request) {
+	user, ok := h.findOtherUser(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.users.UnsuspendUser(r.Context(), user.Id); err != nil {
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{
+			"error": "Internal server error.",
+		})
+		return
+	}
+
+	// send success response
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, map[string]interface{}{
+		"message": "User unsuspended successfully.",
+	})
+}
+
+// require a user to change their password and sign them out everywhere
+func (h *adminHandler) ForcePasswordReset(w http.ResponseWriter, r *http.Request) {
+	user, ok := h.findOtherUser(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.users.RequirePasswordReset(r.Context(), user.Id); err != nil {
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{
+			"error": "Internal server error.",
+		})
+		return
+	}
+
+	if err := h.sessions.RevokeUserSessions(r.Context(), user.Id); err != nil {
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{
+			"error": "Internal server error.",
+		})
+		return
+	}
+
+	// send success response
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, map[string]interface{}{
+		"message": "User must reset their password on next login.",
+	})
+}
+
+// delete a user and their content
+func (h *adminHandler) DeleteUser(w http.ResponseWriter, r *http.Request) {
+	user, ok := h.findOtherUser(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.users.DeleteUser(r.Context(), user.Id); err != nil {
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{
+			"error": "Internal server error.",
+		})
+		return
+	}
+
+	// send success response
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, map[string]interface{}{
+		"message": "User deleted successfully.",
+	})
+}
+
+// issue a short-lived token that lets an admin act as a user
+func (h *adminHandler) Impersonate(w http.ResponseWriter, r *http.Request) {
+	user, ok := h.findOtherUser(w, r)
+	if !ok {
+		return
+	}
+
+	if user.Role == models.RoleAdmin {
+		render.Status(r, http.StatusForbidden)
+		render.JSON(w, r, map[string]string{
+			"error": "Admins cannot be impersonated.",
+		})
+		return
+	}
+
+	if user.SuspendedAt != nil {
+		render.Status(r, http.StatusForbidden)
+		render.JSON(w, r, map[string]string{
+			"error": "Account is suspended.",
+		})
+		return
+	}
+
+	userID, _ := r.Context().Value(types.UserIDKey).(int)
+	actorId := int64(userID)
+
+	// the session is tied to the admin so it shows up as an impersonation
+	sessionId, err := h.sessions.CreateImpersonationSession(r.Context(), &models.Session{
+		UserId:         user.Id,
+		ImpersonatorId: &actorId,
+		UserAgent:      truncate(r.UserAgent(), 255),
+		IPAddress:      clientIP(r),
+	})
+	if err != nil {
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{
+			"error": "Internal server error.",
+		})
+		return
+	}
+
+	token, err := jwt.GenerateImpersonationToken(user.Id, sessionId, actorId)
+	if err != nil {
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{
+			"error": "Internal server error.",
+		})
+		return
+	}
+
+	// send success response
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, map[string]interface{}{
+		"token":      token,
+		"expires_at": time.Now().Add(jwt.ImpersonationTTL).UTC(),
+	})
+}
+
+// query the audit log
+func (h *adminHandler) GetAuditLogs(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	page, perPage := paginate(r)
+
+	filter := repositories.AuditLogFilter{
+		Action:     query.Get("action"),
+		TargetType: query.Get("target_type"),
+		RequestId:  query.Get("request_id"),
+	}
+
+	fieldErrors := map[string]string{}
+
+	for name, dest := range map[string]**int64{"actor_id": &filter.ActorId, "target_id": &filter.TargetId} {
+		if value := query.Get(name); value != "" {
+			id, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				fieldErrors[name] = "Must be a number."
+				continue
+			}
+			*dest = &id
+		}
+	}
+
+	for name, dest := range map[string]**time.Time{"from": &filter.From, "to": &filter.To} {
+		if value := query.Get(name); value != "" {
+			t, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				fieldErrors[name] = "Must be an RFC 3339 timestamp."
+				continue
+			}
+			*dest = &t
+		}
+	}
+
+	if len(fieldErrors) > 0 {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, map[string]interface{}{
+			"error": fieldErrors,
+		})
+		return
+	}
+
+	entries, total, err := h.audit.FindAuditLogs(r.Context(), filter, perPage, (page-1)*perPage)
+	if err != nil {
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{
+			"error": "Internal server error.",
+		})
+		return
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, map[string]interface{}{
+		"audit_logs": entries,
+		"page":       page,
+		"per_page":   perPage,
+		"total":      total,
+	})
+}
+
+// findUser loads the user named in the URL, writing an error response if it cannot.
+func (h *adminHandler) findUser(w http.ResponseWriter, r *http.Request) (*models.User, bool) {
+	userId, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, map[string]string{
+			"error": "Invalid user ID.",
+		})
+		return nil, false
+	}
+
+	user, err := h.users.FindUserById(r.Context(), int64(userId))
+	if err != nil {
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{
+			"error": "Internal server error.",
+		})
+		return nil, false
+	}
+
+	if user == nil {
+		render.Status(r, http.StatusNotFound)
+		render.JSON(w, r, map[string]string{
+			"error": "User does not exists.",
+		})
+		return nil, false
+	}
+
+	return user, true
+}
+
+// findOtherUser is findUser for actions admins must not take on their own account.
+func (h *adminHandler) findOtherUser(w http.ResponseWriter, r *http.Request) (*models.User, bool) {
+	user, ok := h.findUser(w, r)
+	if !ok {
+		return nil, false
+	}
+
+	if userID, _ := r.Context().Value(types.UserIDKey).(int); int64(userID) == user.Id {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, map[string]string{
+			"error": "You cannot perform this action on your own account.",
+		})
+		return nil, false
+	}
+
+	return user, true
+}
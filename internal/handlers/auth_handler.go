@@ -2,28 +2,49 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
+	"log"
 	"net/http"
+	"time"
 
 	"github.com/achintha-dilshan/go-rest-api/internal/models"
 	"github.com/achintha-dilshan/go-rest-api/internal/services"
+	"github.com/achintha-dilshan/go-rest-api/internal/types"
+	"github.com/achintha-dilshan/go-rest-api/internal/utils/cookie"
 	"github.com/achintha-dilshan/go-rest-api/internal/utils/jwt"
+	"github.com/achintha-dilshan/go-rest-api/internal/utils/password"
+	"github.com/achintha-dilshan/go-rest-api/internal/utils/ratelimit"
 	"github.com/achintha-dilshan/go-rest-api/internal/utils/validator"
 	"github.com/go-chi/render"
-	"golang.org/x/crypto/bcrypt"
 )
 
 type authHandler struct {
-	service services.UserService
+	service          services.UserService
+	sessions         services.SessionService
+	magicLinks       services.MagicLinkService
+	jobs             services.JobService
+	magicLinkLimiter *ratelimit.Limiter
+	policy           *password.Policy
+	hasher           password.Hasher
 }
 
 type AuthHandler interface {
 	RegisterUser(w http.ResponseWriter, r *http.Request)
 	LoginUser(w http.ResponseWriter, r *http.Request)
+	LogoutUser(w http.ResponseWriter, r *http.Request)
+	RequestMagicLink(w http.ResponseWriter, r *http.Request)
+	ConsumeMagicLink(w http.ResponseWriter, r *http.Request)
 }
 
-func NewAuthHandler(service services.UserService) AuthHandler {
+func NewAuthHandler(service services.UserService, sessions services.SessionService, magicLinks services.MagicLinkService, jobs services.JobService, policy *password.Policy, hasher password.Hasher) AuthHandler {
 	return &authHandler{
-		service: service,
+		service:          service,
+		sessions:         sessions,
+		magicLinks:       magicLinks,
+		jobs:             jobs,
+		magicLinkLimiter: ratelimit.New(3, 15*time.Minute),
+		policy:           policy,
+		hasher:           hasher,
 	}
 }
 
@@ -32,7 +53,7 @@ func (h *authHandler) RegisterUser(w http.ResponseWriter, r *http.Request) {
 	var req struct {
 		Name     string `json:"name" validate:"required,min=3"`
 		Email    string `json:"email" validate:"required,email"`
-		Password string `json:"password" validate:"required,min=3"`
+		Password string `json:"password" validate:"required"`
 	}
 
 	// decode request body
@@ -52,6 +73,26 @@ func (h *authHandler) RegisterUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// check the password against the password policy
+	message, err := h.policy.Validate(req.Password, req.Name, req.Email)
+	if err != nil {
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{
+			"error": "Internal server error.",
+		})
+		return
+	}
+
+	if message != "" {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, map[string]interface{}{
+			"error": map[string]interface{}{
+				"password": message,
+			},
+		})
+		return
+	}
+
 	// check if the email is already exist
 	exists, err := h.service.ExistUserByEmail(r.Context(), req.Email)
 	if err != nil {
@@ -73,7 +114,7 @@ func (h *authHandler) RegisterUser(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// hash the password
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	hashedPassword, err := h.hasher.Hash(req.Password)
 	if err != nil {
 		render.Status(r, http.StatusInternalServerError)
 		render.JSON(w, r, map[string]string{
@@ -81,7 +122,7 @@ func (h *authHandler) RegisterUser(w http.ResponseWriter, r *http.Request) {
 		})
 		return
 	}
-	req.Password = string(hashedPassword)
+	req.Password = hashedPassword
 
 	// create a new user
 	newUser := models.User{
@@ -144,6 +185,7 @@ func (h *authHandler) LoginUser(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if user == nil {
+		h.service.RecordFailedLogin(r.Context(), req.Email, nil)
 		render.Status(r, http.StatusBadRequest)
 		render.JSON(w, r, map[string]string{
 			"error": "Email or password is incorrect.",
@@ -152,7 +194,17 @@ func (h *authHandler) LoginUser(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// compare passwords
-	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password)); err != nil {
+	match, err := h.hasher.Verify(req.Password, user.Password)
+	if err != nil {
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{
+			"error": "Internal server error.",
+		})
+		return
+	}
+
+	if !match {
+		h.service.RecordFailedLogin(r.Context(), req.Email, user)
 		render.Status(r, http.StatusBadRequest)
 		render.JSON(w, r, map[string]string{
 			"error": "Email or password is incorrect.",
@@ -160,8 +212,172 @@ func (h *authHandler) LoginUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// upgrade hashes made with another algorithm or outdated parameters
+	if h.hasher.NeedsRehash(user.Password) {
+		if hashedPassword, err := h.hasher.Hash(req.Password); err != nil {
+			log.Printf("Failed to rehash password: %v", err)
+		} else {
+			user.Password = hashedPassword
+			if err := h.service.UpgradePasswordHash(r.Context(), user); err != nil {
+				log.Printf("Failed to store rehashed password: %v", err)
+			}
+		}
+	}
+
+	respondWithToken(w, r, h.sessions, user)
+}
+
+// logout user
+func (h *authHandler) LogoutUser(w http.ResponseWriter, r *http.Request) {
+	// get session ID from the context
+	sessionID, ok := r.Context().Value(types.SessionIDKey).(int64)
+	if !ok {
+		render.Status(r, http.StatusUnauthorized)
+		render.JSON(w, r, map[string]string{
+			"error": "Ensure that you are logged in.",
+		})
+		return
+	}
+
+	if err := h.sessions.RevokeSession(r.Context(), sessionID); err != nil {
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{
+			"error": "Internal server error.",
+		})
+		return
+	}
+
+	if cookie.Enabled() {
+		cookie.ClearSession(w)
+	}
+
+	// send success response
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, map[string]interface{}{
+		"message": "Logout was successful.",
+	})
+}
+
+// request a magic sign-in link
+func (h *authHandler) RequestMagicLink(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Email string `json:"email" validate:"required,email"`
+	}
+
+	// decode request body
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, map[string]string{
+			"error": "Invalid JSON payload.",
+		})
+		return
+	}
+
+	// validate user inputs
+	validator := validator.New()
+	if err := validator.Validate(req); err != nil {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, err)
+		return
+	}
+
+	// limit by email whether or not an account exists
+	email := models.NormalizeEmail(req.Email)
+	if !h.magicLinkLimiter.Allow(email) {
+		render.Status(r, http.StatusTooManyRequests)
+		render.JSON(w, r, map[string]string{
+			"error": "Too many sign-in links requested. Try again later.",
+		})
+		return
+	}
+
+	// look up and mail in a background job so the response does not reveal
+	// whether the account exists
+	job := &models.Job{Queue: services.JobQueueMail, Type: services.JobMagicLink}
+	if _, err := h.jobs.Enqueue(r.Context(), job, services.MagicLinkJob{Email: email}); err != nil {
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{
+			"error": "Internal server error.",
+		})
+		return
+	}
+
+	// send success response
+	render.Status(r, http.StatusAccepted)
+	render.JSON(w, r, map[string]interface{}{
+		"message": "If an account exists for this email, a sign-in link has been sent.",
+	})
+}
+
+// exchange a magic sign-in link for a token
+func (h *authHandler) ConsumeMagicLink(w http.ResponseWriter, r *http.Request) {
+	token := r.URL.Query().Get("token")
+	if token == "" {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, map[string]string{
+			"error": "Sign-in token missing.",
+		})
+		return
+	}
+
+	userId, err := h.magicLinks.ConsumeMagicLink(r.Context(), token)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidMagicLink) {
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, map[string]string{
+				"error": "Sign-in link is invalid or has expired.",
+			})
+			return
+		}
+
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{
+			"error": "Internal server error.",
+		})
+		return
+	}
+
+	// retrieve user by id
+	user, err := h.service.FindUserById(r.Context(), userId)
+	if err != nil || user == nil {
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{
+			"error": "User not found or unauthorized.",
+		})
+		return
+	}
+
+	respondWithToken(w, r, h.sessions, user)
+}
+
+// respondWithToken starts a session for the user and sends the login response,
+// either as a bearer token or as cookies depending on the auth mode.
+func respondWithToken(w http.ResponseWriter, r *http.Request, sessions services.SessionService, user *models.User) {
+	// suspended accounts cannot start new sessions
+	if user.SuspendedAt != nil {
+		render.Status(r, http.StatusForbidden)
+		render.JSON(w, r, map[string]string{
+			"error": "Account is suspended.",
+		})
+		return
+	}
+
+	// record the session
+	sessionId, err := sessions.CreateSession(r.Context(), &models.Session{
+		UserId:    user.Id,
+		UserAgent: truncate(r.UserAgent(), 255),
+		IPAddress: clientIP(r),
+	})
+	if err != nil {
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{
+			"error": "Internal server error.",
+		})
+		return
+	}
+
 	// generate token
-	token, err := jwt.GenerateToken(user.Id)
+	token, err := jwt.GenerateToken(user.Id, sessionId)
 	if err != nil {
 		render.Status(r, http.StatusInternalServerError)
 		render.JSON(w, r, map[string]string{
@@ -170,10 +386,31 @@ func (h *authHandler) LoginUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// in cookie mode the token never reaches JavaScript
+	if cookie.Enabled() {
+		csrfToken, err := cookie.SetSession(w, token, jwt.TokenTTL)
+		if err != nil {
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, map[string]string{
+				"error": "Internal server error.",
+			})
+			return
+		}
+
+		render.Status(r, http.StatusOK)
+		render.JSON(w, r, map[string]interface{}{
+			"csrf_token":              csrfToken,
+			"password_reset_required": user.PasswordResetRequired,
+			"message":                 "Login was successful.",
+		})
+		return
+	}
+
 	// send success response
 	render.Status(r, http.StatusOK)
 	render.JSON(w, r, map[string]interface{}{
-		"token":   token,
-		"message": "Login was successful.",
+		"token":                   token,
+		"password_reset_required": user.PasswordResetRequired,
+		"message":                 "Login was successful.",
 	})
 }
@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/go-chi/render"
+)
+
+type databaseHandler struct {
+	stats func() sql.DBStats
+}
+
+type DatabaseHandler interface {
+	GetStats(w http.ResponseWriter, r *http.Request)
+}
+
+func NewDatabaseHandler(stats func() sql.DBStats) DatabaseHandler {
+	return &databaseHandler{
+		stats: stats,
+	}
+}
+
+// report how the database connection pool is used
+func (h *databaseHandler) GetStats(w http.ResponseWriter, r *http.Request) {
+	stats := h.stats()
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, map[string]interface{}{
+		"stats": map[string]interface{}{
+			"max_open_connections": stats.MaxOpenConnections,
+			"open_connections":     stats.OpenConnections,
+			"in_use":               stats.InUse,
+			"idle":                 stats.Idle,
+			"wait_count":           stats.WaitCount,
+			"wait_duration_ms":     stats.WaitDuration.Milliseconds(),
+			"max_idle_closed":      stats.MaxIdleClosed,
+			"max_idle_time_closed": stats.MaxIdleTimeClosed,
+			"max_lifetime_closed":  stats.MaxLifetimeClosed,
+		},
+	})
+}
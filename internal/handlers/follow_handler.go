@@ -0,0 +1,204 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/achintha-dilshan/go-rest-api/internal/models"
+	"github.com/achintha-dilshan/go-rest-api/internal/services"
+	"github.com/achintha-dilshan/go-rest-api/internal/types"
+	"github.com/achintha-dilshan/go-rest-api/internal/utils/cursor"
+	"github.com/go-chi/render"
+)
+
+type followHandler struct {
+	follows   services.FollowService
+	users     services.UserService
+	reactions services.ReactionService
+}
+
+type FollowHandler interface {
+	Follow(w http.ResponseWriter, r *http.Request)
+	Unfollow(w http.ResponseWriter, r *http.Request)
+	GetFollowers(w http.ResponseWriter, r *http.Request)
+	GetFollowing(w http.ResponseWriter, r *http.Request)
+	GetFeed(w http.ResponseWriter, r *http.Request)
+}
+
+func NewFollowHandler(follows services.FollowService, users services.UserService, reactions services.ReactionService) FollowHandler {
+	return &followHandler{
+		follows:   follows,
+		users:     users,
+		reactions: reactions,
+	}
+}
+
+// follow a user as the current user
+func (h *followHandler) Follow(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(types.UserIDKey).(int)
+	if !ok {
+		render.Status(r, http.StatusUnauthorized)
+		render.JSON(w, r, map[string]string{
+			"error": "Ensure that you are logged in.",
+		})
+		return
+	}
+
+	user, ok := findPublicUser(w, r, h.users)
+	if !ok {
+		return
+	}
+
+	if err := h.follows.Follow(r.Context(), int64(userID), user.Id); err != nil {
+		if errors.Is(err, services.ErrSelfFollow) {
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, map[string]string{
+				"error": "You cannot follow yourself.",
+			})
+			return
+		}
+
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{
+			"error": "Internal server error.",
+		})
+		return
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, map[string]interface{}{
+		"following": true,
+		"message":   "User followed.",
+	})
+}
+
+// stop following a user
+func (h *followHandler) Unfollow(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(types.UserIDKey).(int)
+	if !ok {
+		render.Status(r, http.StatusUnauthorized)
+		render.JSON(w, r, map[string]string{
+			"error": "Ensure that you are logged in.",
+		})
+		return
+	}
+
+	user, ok := findPublicUser(w, r, h.users)
+	if !ok {
+		return
+	}
+
+	if err := h.follows.Unfollow(r.Context(), int64(userID), user.Id); err != nil {
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{
+			"error": "Internal server error.",
+		})
+		return
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, map[string]interface{}{
+		"following": false,
+		"message":   "User unfollowed.",
+	})
+}
+
+// list the followers of a user
+func (h *followHandler) GetFollowers(w http.ResponseWriter, r *http.Request) {
+	h.listUsers(w, r, "followers", h.follows.FindFollowers)
+}
+
+// list the users a user follows
+func (h *followHandler) GetFollowing(w http.ResponseWriter, r *http.Request) {
+	h.listUsers(w, r, "following", h.follows.FindFollowing)
+}
+
+// get the current user's feed: posts by the authors they follow, newest first.
+// Pages are addressed by the next_cursor of the previous page.
+func (h *followHandler) GetFeed(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(types.UserIDKey).(int)
+	if !ok {
+		render.Status(r, http.StatusUnauthorized)
+		render.JSON(w, r, map[string]string{
+			"error": "Ensure that you are logged in.",
+		})
+		return
+	}
+
+	var before *cursor.Cursor
+	if token := r.URL.Query().Get("cursor"); token != "" {
+		var err error
+		if before, err = cursor.Decode(token); err != nil {
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, map[string]interface{}{
+				"error": map[string]string{"cursor": "Invalid cursor."},
+			})
+			return
+		}
+	}
+
+	limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
+	if err != nil || limit < 1 {
+		limit = defaultPerPage
+	}
+	if limit > maxPerPage {
+		limit = maxPerPage
+	}
+
+	posts, next, err := h.follows.FindFeed(r.Context(), int64(userID), before, limit)
+	if err != nil {
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{
+			"error": "Internal server error.",
+		})
+		return
+	}
+
+	if err := h.reactions.AttachCounts(r.Context(), posts...); err != nil {
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{
+			"error": "Internal server error.",
+		})
+		return
+	}
+
+	var nextCursor *string
+	if next != nil {
+		token := cursor.Encode(*next)
+		nextCursor = &token
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, map[string]interface{}{
+		"posts":       posts,
+		"next_cursor": nextCursor,
+	})
+}
+
+// listUsers responds with a page of the users find returns for the user in the URL
+func (h *followHandler) listUsers(w http.ResponseWriter, r *http.Request, key string, find func(ctx context.Context, userId int64, limit, offset int) ([]*models.FollowUser, int64, error)) {
+	user, ok := findPublicUser(w, r, h.users)
+	if !ok {
+		return
+	}
+
+	page, perPage := paginate(r)
+	users, total, err := find(r.Context(), user.Id, perPage, (page-1)*perPage)
+	if err != nil {
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{
+			"error": "Internal server error.",
+		})
+		return
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, map[string]interface{}{
+		key:        users,
+		"page":     page,
+		"per_page": perPage,
+		"total":    total,
+	})
+}
@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strconv"
+	"unicode/utf8"
+
+	"github.com/achintha-dilshan/go-rest-api/internal/services"
+	"github.com/achintha-dilshan/go-rest-api/internal/types"
+)
+
+// pagination defaults
+const (
+	defaultPerPage = 20
+	maxPerPage     = 100
+)
+
+// clientIP returns the IP address of the client that made the request.
+func clientIP(r *http.Request) string {
+	if ip, ok := r.Context().Value(types.ClientIPKey).(string); ok {
+		return ip
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// truncate shortens a string to at most n bytes without splitting a character.
+func truncate(value string, n int) string {
+	if len(value) <= n {
+		return value
+	}
+	for n > 0 && !utf8.RuneStart(value[n]) {
+		n--
+	}
+	return value[:n]
+}
+
+// paginate reads the page and per_page query parameters, falling back to the
+// defaults for missing or invalid values.
+func paginate(r *http.Request) (page, perPage int) {
+	page, err := strconv.Atoi(r.URL.Query().Get("page"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	perPage, err = strconv.Atoi(r.URL.Query().Get("per_page"))
+	if err != nil || perPage < 1 {
+		perPage = defaultPerPage
+	}
+	if perPage > maxPerPage {
+		perPage = maxPerPage
+	}
+
+	return page, perPage
+}
+
+// sessionActive reports whether the session a long-lived connection was
+// opened with may still be used: it has not been revoked and its user is not
+// suspended. Connections check it again as they go, since authentication
+// only happens when they open.
+func sessionActive(ctx context.Context, sessions services.SessionService, users services.UserService, sessionId int64) (bool, error) {
+	session, err := sessions.FindSessionById(ctx, sessionId)
+	if err != nil {
+		return false, err
+	}
+	if session == nil || session.RevokedAt != nil {
+		return false, nil
+	}
+
+	user, err := users.FindUserById(ctx, session.UserId)
+	if err != nil {
+		return false, err
+	}
+
+	return user != nil && user.SuspendedAt == nil, nil
+}
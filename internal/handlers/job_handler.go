@@ -0,0 +1,154 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"slices"
+	"strconv"
+
+	"github.com/achintha-dilshan/go-rest-api/internal/models"
+	"github.com/achintha-dilshan/go-rest-api/internal/repositories"
+	"github.com/achintha-dilshan/go-rest-api/internal/services"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+)
+
+type jobHandler struct {
+	jobs  services.JobService
+	audit services.AuditService
+}
+
+type JobHandler interface {
+	GetJobs(w http.ResponseWriter, r *http.Request)
+	GetJob(w http.ResponseWriter, r *http.Request)
+	RetryJob(w http.ResponseWriter, r *http.Request)
+}
+
+func NewJobHandler(jobs services.JobService, audit services.AuditService) JobHandler {
+	return &jobHandler{
+		jobs:  jobs,
+		audit: audit,
+	}
+}
+
+// list background jobs, optionally filtered by queue, type or status
+func (h *jobHandler) GetJobs(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	page, perPage := paginate(r)
+
+	filter := repositories.JobFilter{
+		Queue:  query.Get("queue"),
+		Type:   query.Get("type"),
+		Status: query.Get("status"),
+	}
+
+	statuses := []string{models.JobPending, models.JobRunning, models.JobSucceeded, models.JobDead}
+	if filter.Status != "" && !slices.Contains(statuses, filter.Status) {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, map[string]interface{}{
+			"error": map[string]string{"status": "Must be one of pending, running, succeeded or dead."},
+		})
+		return
+	}
+
+	jobs, total, err := h.jobs.FindJobs(r.Context(), filter, perPage, (page-1)*perPage)
+	if err != nil {
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{
+			"error": "Internal server error.",
+		})
+		return
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, map[string]interface{}{
+		"jobs":     jobs,
+		"page":     page,
+		"per_page": perPage,
+		"total":    total,
+	})
+}
+
+// get a single job
+func (h *jobHandler) GetJob(w http.ResponseWriter, r *http.Request) {
+	job, ok := h.findJob(w, r)
+	if !ok {
+		return
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, map[string]interface{}{
+		"job": job,
+	})
+}
+
+// give a dead job a fresh set of attempts
+func (h *jobHandler) RetryJob(w http.ResponseWriter, r *http.Request) {
+	job, ok := h.findJob(w, r)
+	if !ok {
+		return
+	}
+
+	retried, err := h.jobs.RetryJob(r.Context(), job.Id)
+	if err != nil {
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{
+			"error": "Internal server error.",
+		})
+		return
+	}
+
+	if !retried {
+		render.Status(r, http.StatusConflict)
+		render.JSON(w, r, map[string]string{
+			"error": "Only dead jobs can be retried.",
+		})
+		return
+	}
+
+	entry := &models.AuditLog{
+		Action:     services.AuditJobRetried,
+		TargetType: services.AuditTargetJob,
+		TargetId:   &job.Id,
+	}
+	metadata := map[string]interface{}{"type": job.Type, "attempts": job.Attempts, "last_error": job.LastError}
+	if err := h.audit.Record(r.Context(), entry, metadata); err != nil {
+		log.Printf("Failed to record audit log entry %s: %v", services.AuditJobRetried, err)
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, map[string]string{
+		"message": "Job queued for retry.",
+	})
+}
+
+// findJob loads the job named in the URL, writing an error response if it cannot.
+func (h *jobHandler) findJob(w http.ResponseWriter, r *http.Request) (*models.Job, bool) {
+	jobId, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, map[string]string{
+			"error": "Invalid job ID.",
+		})
+		return nil, false
+	}
+
+	job, err := h.jobs.FindJobById(r.Context(), jobId)
+	if err != nil {
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{
+			"error": "Internal server error.",
+		})
+		return nil, false
+	}
+
+	if job == nil {
+		render.Status(r, http.StatusNotFound)
+		render.JSON(w, r, map[string]string{
+			"error": "Job does not exists.",
+		})
+		return nil, false
+	}
+
+	return job, true
+}
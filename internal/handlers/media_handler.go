@@ -0,0 +1,330 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/achintha-dilshan/go-rest-api/internal/models"
+	"github.com/achintha-dilshan/go-rest-api/internal/services"
+	"github.com/achintha-dilshan/go-rest-api/internal/types"
+	"github.com/achintha-dilshan/go-rest-api/internal/utils/imaging"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+)
+
+// multipart uploads are kept in memory up to this size and spill to disk beyond it
+const uploadMemory = 1 << 20
+
+type mediaHandler struct {
+	media    services.MediaService
+	posts    services.PostService
+	users    services.UserService
+	maxBytes int64
+}
+
+type MediaHandler interface {
+	UploadMedia(w http.ResponseWriter, r *http.Request)
+	UploadAvatar(w http.ResponseWriter, r *http.Request)
+	GetMedia(w http.ResponseWriter, r *http.Request)
+	GetPostMedia(w http.ResponseWriter, r *http.Request)
+	DeleteMedia(w http.ResponseWriter, r *http.Request)
+}
+
+func NewMediaHandler(media services.MediaService, posts services.PostService, users services.UserService, maxBytes int) MediaHandler {
+	return &mediaHandler{
+		media:    media,
+		posts:    posts,
+		users:    users,
+		maxBytes: int64(maxBytes),
+	}
+}
+
+// upload an image, optionally attaching it to one of the user's posts
+func (h *mediaHandler) UploadMedia(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(types.UserIDKey).(int)
+	if !ok {
+		render.Status(r, http.StatusUnauthorized)
+		render.JSON(w, r, map[string]string{
+			"error": "Ensure that you are logged in.",
+		})
+		return
+	}
+
+	data, ok := h.readUpload(w, r)
+	if !ok {
+		return
+	}
+
+	var postId *int64
+	if value := r.FormValue("post_id"); value != "" {
+		id, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, map[string]string{
+				"error": "Invalid post ID.",
+			})
+			return
+		}
+
+		post, err := h.posts.FindPostById(r.Context(), id)
+		if err != nil {
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, map[string]string{
+				"error": "Internal server error.",
+			})
+			return
+		}
+
+		if post == nil || post.AuthorId != int64(userID) {
+			render.Status(r, http.StatusNotFound)
+			render.JSON(w, r, map[string]string{
+				"error": "Post does not exists.",
+			})
+			return
+		}
+
+		postId = &post.Id
+	}
+
+	media, err := h.media.UploadMedia(r.Context(), int64(userID), postId, data)
+	if err != nil {
+		respondUploadError(w, r, err)
+		return
+	}
+
+	render.Status(r, http.StatusCreated)
+	render.JSON(w, r, map[string]interface{}{
+		"media":   media,
+		"message": "File uploaded successfully.",
+	})
+}
+
+// upload an image and make it the user's avatar
+func (h *mediaHandler) UploadAvatar(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(types.UserIDKey).(int)
+	if !ok {
+		render.Status(r, http.StatusUnauthorized)
+		render.JSON(w, r, map[string]string{
+			"error": "Ensure that you are logged in.",
+		})
+		return
+	}
+
+	data, ok := h.readUpload(w, r)
+	if !ok {
+		return
+	}
+
+	user, err := h.users.FindUserById(r.Context(), int64(userID))
+	if err != nil || user == nil {
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{
+			"error": "Internal server error.",
+		})
+		return
+	}
+
+	media, err := h.media.UploadAvatar(r.Context(), user.Id, data)
+	if err != nil {
+		respondUploadError(w, r, err)
+		return
+	}
+
+	user.AvatarURL = media.ThumbnailURL
+	if err := h.users.UpdateUser(r.Context(), user); err != nil {
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{
+			"error": "Internal server error.",
+		})
+		return
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, map[string]interface{}{
+		"avatar_url": user.AvatarURL,
+		"media":      media,
+		"message":    "Avatar updated successfully.",
+	})
+}
+
+// get a media record
+func (h *mediaHandler) GetMedia(w http.ResponseWriter, r *http.Request) {
+	media, ok := h.findMedia(w, r)
+	if !ok {
+		return
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, map[string]interface{}{
+		"media": media,
+	})
+}
+
+// get the media attached to a post
+func (h *mediaHandler) GetPostMedia(w http.ResponseWriter, r *http.Request) {
+	postId, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, map[string]string{
+			"error": "Invalid post ID.",
+		})
+		return
+	}
+
+	media, err := h.media.FindPostMedia(r.Context(), int64(postId))
+	if err != nil {
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{
+			"error": "Internal server error.",
+		})
+		return
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, map[string]interface{}{
+		"media": media,
+	})
+}
+
+// delete one of the user's uploads
+func (h *mediaHandler) DeleteMedia(w http.ResponseWriter, r *http.Request) {
+	media, ok := h.findMedia(w, r)
+	if !ok {
+		return
+	}
+
+	if userID, _ := r.Context().Value(types.UserIDKey).(int); int64(userID) != media.UserId {
+		render.Status(r, http.StatusForbidden)
+		render.JSON(w, r, map[string]string{
+			"error": "You are not allowed to delete this file.",
+		})
+		return
+	}
+
+	if err := h.media.DeleteMedia(r.Context(), media); err != nil {
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{
+			"error": "Internal server error.",
+		})
+		return
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, map[string]interface{}{
+		"message": "File deleted successfully.",
+	})
+}
+
+// readUpload reads the "file" field of a multipart upload, enforcing the size
+// limit, and writes an error response if it cannot.
+func (h *mediaHandler) readUpload(w http.ResponseWriter, r *http.Request) ([]byte, bool) {
+	// leave room for the multipart framing around the file
+	r.Body = http.MaxBytesReader(w, r.Body, h.maxBytes+uploadMemory)
+
+	if err := r.ParseMultipartForm(uploadMemory); err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			respondFileTooLarge(w, r, h.maxBytes)
+			return nil, false
+		}
+
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, map[string]string{
+			"error": "Expected a multipart/form-data upload.",
+		})
+		return nil, false
+	}
+	defer r.MultipartForm.RemoveAll()
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, map[string]interface{}{
+			"error": map[string]string{"file": "This field is required."},
+		})
+		return nil, false
+	}
+	defer file.Close()
+
+	if header.Size > h.maxBytes {
+		respondFileTooLarge(w, r, h.maxBytes)
+		return nil, false
+	}
+
+	data, err := io.ReadAll(io.LimitReader(file, h.maxBytes+1))
+	if err != nil {
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{
+			"error": "Internal server error.",
+		})
+		return nil, false
+	}
+
+	if int64(len(data)) > h.maxBytes {
+		respondFileTooLarge(w, r, h.maxBytes)
+		return nil, false
+	}
+
+	return data, true
+}
+
+// findMedia loads the media named in the URL, writing an error response if it cannot.
+func (h *mediaHandler) findMedia(w http.ResponseWriter, r *http.Request) (*models.Media, bool) {
+	mediaId, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, map[string]string{
+			"error": "Invalid media ID.",
+		})
+		return nil, false
+	}
+
+	media, err := h.media.FindMediaById(r.Context(), int64(mediaId))
+	if err != nil {
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{
+			"error": "Internal server error.",
+		})
+		return nil, false
+	}
+
+	if media == nil {
+		render.Status(r, http.StatusNotFound)
+		render.JSON(w, r, map[string]string{
+			"error": "File does not exists.",
+		})
+		return nil, false
+	}
+
+	return media, true
+}
+
+func respondFileTooLarge(w http.ResponseWriter, r *http.Request, maxBytes int64) {
+	render.Status(r, http.StatusRequestEntityTooLarge)
+	render.JSON(w, r, map[string]string{
+		"error": "File must be at most " + strconv.FormatInt(maxBytes>>10, 10) + " KB.",
+	})
+}
+
+// respondUploadError maps the errors of storing an upload to a response.
+func respondUploadError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, imaging.ErrUnsupportedFormat):
+		render.Status(r, http.StatusUnsupportedMediaType)
+		render.JSON(w, r, map[string]string{
+			"error": "Only JPEG, PNG, GIF and WebP images can be uploaded.",
+		})
+	case errors.Is(err, imaging.ErrTooLarge):
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, map[string]string{
+			"error": "Image dimensions are too large.",
+		})
+	default:
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{
+			"error": "Internal server error.",
+		})
+	}
+}
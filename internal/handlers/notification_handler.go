@@ -0,0 +1,221 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/achintha-dilshan/go-rest-api/internal/services"
+	"github.com/achintha-dilshan/go-rest-api/internal/types"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+)
+
+type notificationHandler struct {
+	service services.NotificationService
+}
+
+type NotificationHandler interface {
+	GetNotifications(w http.ResponseWriter, r *http.Request)
+	MarkRead(w http.ResponseWriter, r *http.Request)
+	MarkAllRead(w http.ResponseWriter, r *http.Request)
+	GetPreferences(w http.ResponseWriter, r *http.Request)
+	UpdatePreferences(w http.ResponseWriter, r *http.Request)
+}
+
+func NewNotificationHandler(service services.NotificationService) NotificationHandler {
+	return &notificationHandler{
+		service: service,
+	}
+}
+
+// get the notifications of the current user, optionally only the unread ones
+func (h *notificationHandler) GetNotifications(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(types.UserIDKey).(int)
+	if !ok {
+		render.Status(r, http.StatusUnauthorized)
+		render.JSON(w, r, map[string]string{
+			"error": "Ensure that you are logged in.",
+		})
+		return
+	}
+
+	unreadOnly, _ := strconv.ParseBool(r.URL.Query().Get("unread"))
+	page, perPage := paginate(r)
+
+	notifications, total, err := h.service.FindNotifications(r.Context(), int64(userID), unreadOnly, perPage, (page-1)*perPage)
+	if err != nil {
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{
+			"error": "Internal server error.",
+		})
+		return
+	}
+
+	unread, err := h.service.CountUnread(r.Context(), int64(userID))
+	if err != nil {
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{
+			"error": "Internal server error.",
+		})
+		return
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, map[string]interface{}{
+		"notifications": notifications,
+		"unread_count":  unread,
+		"page":          page,
+		"per_page":      perPage,
+		"total":         total,
+	})
+}
+
+// mark a notification of the current user read
+func (h *notificationHandler) MarkRead(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(types.UserIDKey).(int)
+	if !ok {
+		render.Status(r, http.StatusUnauthorized)
+		render.JSON(w, r, map[string]string{
+			"error": "Ensure that you are logged in.",
+		})
+		return
+	}
+
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, map[string]string{
+			"error": "Invalid notification ID.",
+		})
+		return
+	}
+
+	found, err := h.service.MarkRead(r.Context(), int64(userID), id)
+	if err != nil {
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{
+			"error": "Internal server error.",
+		})
+		return
+	}
+
+	if !found {
+		render.Status(r, http.StatusNotFound)
+		render.JSON(w, r, map[string]string{
+			"error": "Notification does not exists.",
+		})
+		return
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, map[string]interface{}{
+		"message": "Notification marked as read.",
+	})
+}
+
+// mark every notification of the current user read
+func (h *notificationHandler) MarkAllRead(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(types.UserIDKey).(int)
+	if !ok {
+		render.Status(r, http.StatusUnauthorized)
+		render.JSON(w, r, map[string]string{
+			"error": "Ensure that you are logged in.",
+		})
+		return
+	}
+
+	marked, err := h.service.MarkAllRead(r.Context(), int64(userID))
+	if err != nil {
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{
+			"error": "Internal server error.",
+		})
+		return
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, map[string]interface{}{
+		"marked":  marked,
+		"message": "All notifications marked as read.",
+	})
+}
+
+// get which notification types are on for the current user
+func (h *notificationHandler) GetPreferences(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(types.UserIDKey).(int)
+	if !ok {
+		render.Status(r, http.StatusUnauthorized)
+		render.JSON(w, r, map[string]string{
+			"error": "Ensure that you are logged in.",
+		})
+		return
+	}
+
+	preferences, err := h.service.FindPreferences(r.Context(), int64(userID))
+	if err != nil {
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{
+			"error": "Internal server error.",
+		})
+		return
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, map[string]interface{}{
+		"preferences": preferences,
+	})
+}
+
+// turn notification types on or off for the current user
+func (h *notificationHandler) UpdatePreferences(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(types.UserIDKey).(int)
+	if !ok {
+		render.Status(r, http.StatusUnauthorized)
+		render.JSON(w, r, map[string]string{
+			"error": "Ensure that you are logged in.",
+		})
+		return
+	}
+
+	var req map[string]bool
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, map[string]string{
+			"error": "Invalid JSON payload.",
+		})
+		return
+	}
+
+	if err := h.service.UpdatePreferences(r.Context(), int64(userID), req); err != nil {
+		if errors.Is(err, services.ErrUnknownNotificationType) {
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, map[string]string{
+				"error": "Unknown notification type.",
+			})
+			return
+		}
+
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{
+			"error": "Internal server error.",
+		})
+		return
+	}
+
+	preferences, err := h.service.FindPreferences(r.Context(), int64(userID))
+	if err != nil {
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{
+			"error": "Internal server error.",
+		})
+		return
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, map[string]interface{}{
+		"preferences": preferences,
+		"message":     "Notification preferences updated.",
+	})
+}
@@ -0,0 +1,481 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/achintha-dilshan/go-rest-api/internal/models"
+	"github.com/achintha-dilshan/go-rest-api/internal/services"
+	"github.com/achintha-dilshan/go-rest-api/internal/types"
+	"github.com/achintha-dilshan/go-rest-api/internal/utils/ratelimit"
+	"github.com/achintha-dilshan/go-rest-api/internal/utils/validator"
+	"github.com/achintha-dilshan/go-rest-api/internal/utils/webauthn"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+)
+
+type passkeyHandler struct {
+	service    services.PasskeyService
+	users      services.UserService
+	sessions   services.SessionService
+	webauthn   *webauthn.WebAuthn
+	challenges *webauthn.ChallengeStore
+	limiter    *ratelimit.Limiter
+}
+
+type PasskeyHandler interface {
+	BeginRegistration(w http.ResponseWriter, r *http.Request)
+	FinishRegistration(w http.ResponseWriter, r *http.Request)
+	GetPasskeys(w http.ResponseWriter, r *http.Request)
+	DeletePasskey(w http.ResponseWriter, r *http.Request)
+	BeginLogin(w http.ResponseWriter, r *http.Request)
+	FinishLogin(w http.ResponseWriter, r *http.Request)
+}
+
+func NewPasskeyHandler(service services.PasskeyService, users services.UserService, sessions services.SessionService, relyingParty *webauthn.WebAuthn) PasskeyHandler {
+	return &passkeyHandler{
+		service:    service,
+		users:      users,
+		sessions:   sessions,
+		webauthn:   relyingParty,
+		challenges: webauthn.NewChallengeStore(),
+		limiter:    ratelimit.New(10, 15*time.Minute),
+	}
+}
+
+// start registering a passkey for the logged-in user
+func (h *passkeyHandler) BeginRegistration(w http.ResponseWriter, r *http.Request) {
+	// get user ID from the context
+	userID, ok := r.Context().Value(types.UserIDKey).(int)
+	if !ok {
+		render.Status(r, http.StatusUnauthorized)
+		render.JSON(w, r, map[string]string{
+			"error": "Ensure that you are logged in.",
+		})
+		return
+	}
+
+	// retrieve user by id
+	user, err := h.users.FindUserById(r.Context(), int64(userID))
+	if err != nil || user == nil {
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{
+			"error": "User not found or unauthorized.",
+		})
+		return
+	}
+
+	existing, err := h.credentialIds(r, user.Id)
+	if err != nil {
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{
+			"error": "Internal server error.",
+		})
+		return
+	}
+
+	challenge, err := webauthn.NewChallenge()
+	if err != nil {
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{
+			"error": "Internal server error.",
+		})
+		return
+	}
+	h.challenges.Put(challenge, user.Id)
+
+	options := h.webauthn.BeginRegistration(webauthn.User{
+		ID:          userHandle(user.Id),
+		Name:        user.Email,
+		DisplayName: user.Name,
+	}, challenge, existing)
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, map[string]interface{}{
+		"publicKey": options,
+	})
+}
+
+// finish registering a passkey for the logged-in user
+func (h *passkeyHandler) FinishRegistration(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Name       string                        `json:"name" validate:"required,min=3"`
+		Credential webauthn.RegistrationResponse `json:"credential"`
+	}
+
+	// decode request body
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, map[string]string{
+			"error": "Invalid JSON payload.",
+		})
+		return
+	}
+
+	// validate user inputs
+	validator := validator.New()
+	if err := validator.Validate(req); err != nil {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, err)
+		return
+	}
+
+	// get user ID from the context
+	userID, ok := r.Context().Value(types.UserIDKey).(int)
+	if !ok {
+		render.Status(r, http.StatusUnauthorized)
+		render.JSON(w, r, map[string]string{
+			"error": "Ensure that you are logged in.",
+		})
+		return
+	}
+
+	// the challenge must have been issued to this user
+	challenge, err := webauthn.ClientChallenge(req.Credential.Response.ClientDataJSON)
+	if err != nil {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, map[string]string{
+			"error": "Invalid passkey response.",
+		})
+		return
+	}
+
+	owner, ok := h.challenges.Take(challenge)
+	if !ok || owner != int64(userID) {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, map[string]string{
+			"error": "Passkey registration has expired. Start again.",
+		})
+		return
+	}
+
+	credential, err := h.webauthn.FinishRegistration(challenge, req.Credential)
+	if err != nil {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, map[string]string{
+			"error": "Passkey could not be verified.",
+		})
+		return
+	}
+
+	// a credential can only belong to one account
+	existing, err := h.service.FindPasskeyByCredentialId(r.Context(), credential.ID)
+	if err != nil {
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{
+			"error": "Internal server error.",
+		})
+		return
+	}
+
+	if existing != nil {
+		render.Status(r, http.StatusConflict)
+		render.JSON(w, r, map[string]string{
+			"error": "Passkey is already registered.",
+		})
+		return
+	}
+
+	// store the passkey
+	passkey := models.Passkey{
+		UserId:       int64(userID),
+		Name:         req.Name,
+		CredentialId: credential.ID,
+		PublicKey:    credential.PublicKey,
+		SignCount:    credential.SignCount,
+	}
+	passkeyId, err := h.service.CreatePasskey(r.Context(), &passkey)
+	if err != nil {
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{
+			"error": "Internal server error.",
+		})
+		return
+	}
+
+	// send success response
+	render.Status(r, http.StatusCreated)
+	render.JSON(w, r, map[string]interface{}{
+		"id":      passkeyId,
+		"name":    passkey.Name,
+		"message": "Passkey registered successfully.",
+	})
+}
+
+// list the passkeys of the logged-in user
+func (h *passkeyHandler) GetPasskeys(w http.ResponseWriter, r *http.Request) {
+	// get user ID from the context
+	userID, ok := r.Context().Value(types.UserIDKey).(int)
+	if !ok {
+		render.Status(r, http.StatusUnauthorized)
+		render.JSON(w, r, map[string]string{
+			"error": "Ensure that you are logged in.",
+		})
+		return
+	}
+
+	passkeys, err := h.service.FindUserPasskeys(r.Context(), int64(userID))
+	if err != nil {
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{
+			"error": "Internal server error.",
+		})
+		return
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, map[string]interface{}{
+		"passkeys": passkeys,
+	})
+}
+
+// delete one of the logged-in user's passkeys
+func (h *passkeyHandler) DeletePasskey(w http.ResponseWriter, r *http.Request) {
+	// get user ID from the context
+	userID, ok := r.Context().Value(types.UserIDKey).(int)
+	if !ok {
+		render.Status(r, http.StatusUnauthorized)
+		render.JSON(w, r, map[string]string{
+			"error": "Ensure that you are logged in.",
+		})
+		return
+	}
+
+	passkeyId, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, map[string]string{
+			"error": "Invalid passkey ID.",
+		})
+		return
+	}
+
+	// find the passkey
+	passkey, err := h.service.FindPasskeyById(r.Context(), int64(passkeyId))
+	if err != nil {
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{
+			"error": "Internal server error.",
+		})
+		return
+	}
+
+	if passkey == nil || passkey.UserId != int64(userID) {
+		render.Status(r, http.StatusNotFound)
+		render.JSON(w, r, map[string]string{
+			"error": "Passkey does not exists.",
+		})
+		return
+	}
+
+	if err := h.service.DeletePasskey(r.Context(), passkey.Id); err != nil {
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{
+			"error": "Internal server error.",
+		})
+		return
+	}
+
+	// send success response
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, map[string]interface{}{
+		"message": "Passkey deleted successfully.",
+	})
+}
+
+// start a passkey login
+func (h *passkeyHandler) BeginLogin(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Email string `json:"email"`
+	}
+
+	// the body is optional; without an email any discoverable passkey can be used
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, map[string]string{
+				"error": "Invalid JSON payload.",
+			})
+			return
+		}
+	}
+
+	var allowed [][]byte
+	if req.Email != "" {
+		// limit by client and email whether or not an account exists, so
+		// others cannot use up a user's attempts
+		email := models.NormalizeEmail(req.Email)
+		if !h.limiter.Allow(clientIP(r) + " " + email) {
+			render.Status(r, http.StatusTooManyRequests)
+			render.JSON(w, r, map[string]string{
+				"error": "Too many sign-in attempts. Try again later.",
+			})
+			return
+		}
+
+		user, err := h.users.FindUserByEmail(r.Context(), email)
+		if err != nil {
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, map[string]string{
+				"error": "Internal server error.",
+			})
+			return
+		}
+
+		if user != nil {
+			allowed, err = h.credentialIds(r, user.Id)
+			if err != nil {
+				render.Status(r, http.StatusInternalServerError)
+				render.JSON(w, r, map[string]string{
+					"error": "Internal server error.",
+				})
+				return
+			}
+		}
+
+		// unknown emails and users without passkeys are offered a credential
+		// no authenticator has, the same one each time, so the response does
+		// not reveal whether the account exists
+		if len(allowed) == 0 {
+			allowed = [][]byte{h.webauthn.DecoyCredentialID(email)}
+		}
+	}
+
+	challenge, err := webauthn.NewChallenge()
+	if err != nil {
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{
+			"error": "Internal server error.",
+		})
+		return
+	}
+	h.challenges.Put(challenge, 0)
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, map[string]interface{}{
+		"publicKey": h.webauthn.BeginLogin(challenge, allowed),
+	})
+}
+
+// finish a passkey login and issue a token
+func (h *passkeyHandler) FinishLogin(w http.ResponseWriter, r *http.Request) {
+	var req webauthn.AssertionResponse
+
+	// decode request body
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, map[string]string{
+			"error": "Invalid JSON payload.",
+		})
+		return
+	}
+
+	challenge, err := webauthn.ClientChallenge(req.Response.ClientDataJSON)
+	if err != nil {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, map[string]string{
+			"error": "Invalid passkey response.",
+		})
+		return
+	}
+
+	if _, ok := h.challenges.Take(challenge); !ok {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, map[string]string{
+			"error": "Passkey login has expired. Start again.",
+		})
+		return
+	}
+
+	credentialId, err := webauthn.CredentialID(req)
+	if err != nil {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, map[string]string{
+			"error": "Invalid passkey response.",
+		})
+		return
+	}
+
+	passkey, err := h.service.FindPasskeyByCredentialId(r.Context(), credentialId)
+	if err != nil {
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{
+			"error": "Internal server error.",
+		})
+		return
+	}
+
+	// a user handle, when sent, must name the passkey's owner
+	if passkey == nil || (req.Response.UserHandle != "" && req.Response.UserHandle != encodeUserHandle(passkey.UserId)) {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, map[string]string{
+			"error": "Passkey is not recognized.",
+		})
+		return
+	}
+
+	signCount, err := h.webauthn.FinishLogin(challenge, webauthn.Credential{
+		ID:        passkey.CredentialId,
+		PublicKey: passkey.PublicKey,
+		SignCount: passkey.SignCount,
+	}, req)
+	if err != nil {
+		message := "Passkey could not be verified."
+		if errors.Is(err, webauthn.ErrSignCount) {
+			message = "Passkey may have been cloned and was rejected."
+		}
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, map[string]string{
+			"error": message,
+		})
+		return
+	}
+
+	if err := h.service.UpdateSignCount(r.Context(), passkey.Id, signCount); err != nil {
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{
+			"error": "Internal server error.",
+		})
+		return
+	}
+
+	// retrieve user by id
+	user, err := h.users.FindUserById(r.Context(), passkey.UserId)
+	if err != nil || user == nil {
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{
+			"error": "User not found or unauthorized.",
+		})
+		return
+	}
+
+	respondWithToken(w, r, h.sessions, user)
+}
+
+// credentialIds returns the credential IDs of a user's passkeys.
+func (h *passkeyHandler) credentialIds(r *http.Request, userId int64) ([][]byte, error) {
+	passkeys, err := h.service.FindUserPasskeys(r.Context(), userId)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([][]byte, 0, len(passkeys))
+	for _, passkey := range passkeys {
+		ids = append(ids, passkey.CredentialId)
+	}
+	return ids, nil
+}
+
+// userHandle is the opaque WebAuthn user ID for an account.
+func userHandle(userId int64) []byte {
+	return []byte(strconv.FormatInt(userId, 10))
+}
+
+// encodeUserHandle returns the user handle as the browser sends it back.
+func encodeUserHandle(userId int64) string {
+	return base64.RawURLEncoding.EncodeToString(userHandle(userId))
+}
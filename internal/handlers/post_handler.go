@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
 	"strconv"
 
 	"github.com/achintha-dilshan/go-rest-api/internal/models"
@@ -17,28 +18,32 @@ import (
 )
 
 type postHandler struct {
-	service services.PostService
+	service   services.PostService
+	reactions services.ReactionService
 }
 
 type PostHandler interface {
 	CreatePost(w http.ResponseWriter, r *http.Request)
 	GetAllPosts(w http.ResponseWriter, r *http.Request)
 	GetSinglePost(w http.ResponseWriter, r *http.Request)
+	GetPostBySlug(w http.ResponseWriter, r *http.Request)
 	EditPost(w http.ResponseWriter, r *http.Request)
 	DeletePost(w http.ResponseWriter, r *http.Request)
 }
 
-func NewPostHandler(service services.PostService) PostHandler {
+func NewPostHandler(service services.PostService, reactions services.ReactionService) PostHandler {
 	return &postHandler{
-		service: service,
+		service:   service,
+		reactions: reactions,
 	}
 }
 
 // create a new post
 func (h *postHandler) CreatePost(w http.ResponseWriter, r *http.Request) {
 	var req struct {
-		Title string `json:"title" validate:"required,min=3"`
-		Body  string `json:"body" validate:"required,min=3"`
+		Title  string `json:"title" validate:"required,min=3"`
+		Body   string `json:"body" validate:"required,min=3,max=100000"`
+		Format string `json:"format"`
 	}
 
 	// decode request body
@@ -58,6 +63,14 @@ func (h *postHandler) CreatePost(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !validFormat(req.Format) {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, map[string]interface{}{
+			"error": map[string]string{"format": "Must be 'plain' or 'markdown'."},
+		})
+		return
+	}
+
 	// get user ID from the context
 	userID, ok := r.Context().Value(types.UserIDKey).(int)
 	if !ok {
@@ -73,6 +86,10 @@ func (h *postHandler) CreatePost(w http.ResponseWriter, r *http.Request) {
 		AuthorId: int64(userID),
 		Title:    req.Title,
 		Body:     req.Body,
+		Format:   req.Format,
+	}
+	if newPost.Format == "" {
+		newPost.Format = models.PostFormatPlain
 	}
 	postId, err := h.service.CreatePost(r.Context(), &newPost)
 	if err != nil {
@@ -90,7 +107,9 @@ func (h *postHandler) CreatePost(w http.ResponseWriter, r *http.Request) {
 		"id":        postId,
 		"author_id": userID,
 		"title":     newPost.Title,
+		"slug":      newPost.Slug,
 		"body":      newPost.Body,
+		"format":    newPost.Format,
 		"message":   "Post created successfully.",
 	})
 }
@@ -106,6 +125,15 @@ func (h *postHandler) GetAllPosts(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// counts for every post come from one query
+	if err := h.reactions.AttachCounts(r.Context(), posts...); err != nil {
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{
+			"error": "Internal server error.",
+		})
+		return
+	}
+
 	render.Status(r, http.StatusOK)
 	render.JSON(w, r, map[string]interface{}{
 		"posts": posts,
@@ -135,6 +163,52 @@ func (h *postHandler) GetSinglePost(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if err := h.reactions.AttachCounts(r.Context(), post); err != nil {
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{
+			"error": "Internal server error.",
+		})
+		return
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, map[string]interface{}{
+		"post": post,
+	})
+}
+
+// get single post by its slug, redirecting former slugs to the current one
+func (h *postHandler) GetPostBySlug(w http.ResponseWriter, r *http.Request) {
+	post, moved, err := h.service.FindPostBySlug(r.Context(), chi.URLParam(r, "slug"))
+	if err != nil {
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{
+			"error": "Internal server error.",
+		})
+		return
+	}
+
+	if post == nil {
+		render.Status(r, http.StatusNotFound)
+		render.JSON(w, r, map[string]string{
+			"error": "Post does not exists.",
+		})
+		return
+	}
+
+	if moved {
+		http.Redirect(w, r, "/posts/by-slug/"+url.PathEscape(post.Slug), http.StatusMovedPermanently)
+		return
+	}
+
+	if err := h.reactions.AttachCounts(r.Context(), post); err != nil {
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{
+			"error": "Internal server error.",
+		})
+		return
+	}
+
 	render.Status(r, http.StatusOK)
 	render.JSON(w, r, map[string]interface{}{
 		"post": post,
@@ -144,8 +218,9 @@ func (h *postHandler) GetSinglePost(w http.ResponseWriter, r *http.Request) {
 // edit a post
 func (h *postHandler) EditPost(w http.ResponseWriter, r *http.Request) {
 	var req struct {
-		Title string `json:"title" validate:"required,min=3"`
-		Body  string `json:"body" validate:"required,min=3"`
+		Title  string `json:"title" validate:"required,min=3"`
+		Body   string `json:"body" validate:"required,min=3,max=100000"`
+		Format string `json:"format"`
 	}
 
 	// decode request body
@@ -165,6 +240,14 @@ func (h *postHandler) EditPost(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !validFormat(req.Format) {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, map[string]interface{}{
+			"error": map[string]string{"format": "Must be 'plain' or 'markdown'."},
+		})
+		return
+	}
+
 	postId := chi.URLParam(r, "id")
 
 	intId, err := strconv.Atoi(postId)
@@ -189,6 +272,10 @@ func (h *postHandler) EditPost(w http.ResponseWriter, r *http.Request) {
 	// update post
 	post.Title = req.Title
 	post.Body = req.Body
+	// the format is kept when omitted
+	if req.Format != "" {
+		post.Format = req.Format
+	}
 	if err := h.service.UpdatePost(r.Context(), post); err != nil {
 		render.Status(r, http.StatusInternalServerError)
 		render.JSON(w, r, map[string]string{
@@ -201,7 +288,9 @@ func (h *postHandler) EditPost(w http.ResponseWriter, r *http.Request) {
 	render.Status(r, http.StatusOK)
 	render.JSON(w, r, map[string]interface{}{
 		"title":   post.Title,
+		"slug":    post.Slug,
 		"body":    post.Body,
+		"format":  post.Format,
 		"message": "Post updated successfully.",
 	})
 }
@@ -241,3 +330,8 @@ func (h *postHandler) DeletePost(w http.ResponseWriter, r *http.Request) {
 		"message": "Post deleted successfully.",
 	})
 }
+
+// validFormat reports whether format is a post format; empty means the default.
+func validFormat(format string) bool {
+	return format == "" || format == models.PostFormatPlain || format == models.PostFormatMarkdown
+}
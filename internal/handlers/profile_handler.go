@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/achintha-dilshan/go-rest-api/internal/models"
+	"github.com/achintha-dilshan/go-rest-api/internal/services"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+)
+
+type profileHandler struct {
+	users     services.UserService
+	posts     services.PostService
+	reactions services.ReactionService
+}
+
+type ProfileHandler interface {
+	GetProfile(w http.ResponseWriter, r *http.Request)
+	GetProfilePosts(w http.ResponseWriter, r *http.Request)
+}
+
+func NewProfileHandler(users services.UserService, posts services.PostService, reactions services.ReactionService) ProfileHandler {
+	return &profileHandler{
+		users:     users,
+		posts:     posts,
+		reactions: reactions,
+	}
+}
+
+// get the public profile of a user
+func (h *profileHandler) GetProfile(w http.ResponseWriter, r *http.Request) {
+	user, ok := h.findUser(w, r)
+	if !ok {
+		return
+	}
+
+	postCount, err := h.posts.CountPostsByAuthor(r.Context(), user.Id)
+	if err != nil {
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{
+			"error": "Internal server error.",
+		})
+		return
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, map[string]interface{}{
+		"user": user.Profile(postCount),
+	})
+}
+
+// get the posts of a user
+func (h *profileHandler) GetProfilePosts(w http.ResponseWriter, r *http.Request) {
+	user, ok := h.findUser(w, r)
+	if !ok {
+		return
+	}
+
+	posts, err := h.posts.FindPostsByAuthor(r.Context(), user.Id)
+	if err != nil {
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{
+			"error": "Internal server error.",
+		})
+		return
+	}
+
+	if err := h.reactions.AttachCounts(r.Context(), posts...); err != nil {
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{
+			"error": "Internal server error.",
+		})
+		return
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, map[string]interface{}{
+		"posts": posts,
+	})
+}
+
+// findUser loads the user named in the URL, writing an error response if it
+// cannot. Suspended users have no public profile.
+func (h *profileHandler) findUser(w http.ResponseWriter, r *http.Request) (*models.User, bool) {
+	return findPublicUser(w, r, h.users)
+}
+
+// findPublicUser is findUser for the handlers serving other parts of a profile.
+func findPublicUser(w http.ResponseWriter, r *http.Request, users services.UserService) (*models.User, bool) {
+	userId, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, map[string]string{
+			"error": "Invalid user ID.",
+		})
+		return nil, false
+	}
+
+	user, err := users.FindUserById(r.Context(), int64(userId))
+	if err != nil {
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{
+			"error": "Internal server error.",
+		})
+		return nil, false
+	}
+
+	if user == nil || user.SuspendedAt != nil {
+		render.Status(r, http.StatusNotFound)
+		render.JSON(w, r, map[string]string{
+			"error": "User does not exists.",
+		})
+		return nil, false
+	}
+
+	return user, true
+}
@@ -0,0 +1,163 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/achintha-dilshan/go-rest-api/internal/models"
+	"github.com/achintha-dilshan/go-rest-api/internal/services"
+	"github.com/achintha-dilshan/go-rest-api/internal/types"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+)
+
+type reactionHandler struct {
+	reactions services.ReactionService
+	posts     services.PostService
+}
+
+type ReactionHandler interface {
+	AddReaction(w http.ResponseWriter, r *http.Request)
+	RemoveReaction(w http.ResponseWriter, r *http.Request)
+	GetReactions(w http.ResponseWriter, r *http.Request)
+}
+
+func NewReactionHandler(reactions services.ReactionService, posts services.PostService) ReactionHandler {
+	return &reactionHandler{
+		reactions: reactions,
+		posts:     posts,
+	}
+}
+
+// react to a post as the current user
+func (h *reactionHandler) AddReaction(w http.ResponseWriter, r *http.Request) {
+	h.changeReaction(w, r, h.reactions.AddReaction, "Reaction added.")
+}
+
+// take back a reaction of the current user
+func (h *reactionHandler) RemoveReaction(w http.ResponseWriter, r *http.Request) {
+	h.changeReaction(w, r, h.reactions.RemoveReaction, "Reaction removed.")
+}
+
+// list who reacted to a post, optionally for a single emoji
+func (h *reactionHandler) GetReactions(w http.ResponseWriter, r *http.Request) {
+	post, ok := h.findPost(w, r)
+	if !ok {
+		return
+	}
+
+	page, perPage := paginate(r)
+	reactions, total, err := h.reactions.FindReactions(r.Context(), post.Id, r.URL.Query().Get("emoji"), perPage, (page-1)*perPage)
+	if errors.Is(err, services.ErrInvalidReaction) {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, map[string]interface{}{
+			"error": map[string]string{"emoji": "Unsupported reaction."},
+		})
+		return
+	}
+	if err != nil {
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{
+			"error": "Internal server error.",
+		})
+		return
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, map[string]interface{}{
+		"reactions": reactions,
+		"page":      page,
+		"per_page":  perPage,
+		"total":     total,
+	})
+}
+
+// changeReaction applies change to the post and emoji in the URL, then
+// responds with the post's updated counts.
+func (h *reactionHandler) changeReaction(w http.ResponseWriter, r *http.Request, change func(ctx context.Context, post *models.Post, userId int64, emoji string) (string, error), message string) {
+	userID, ok := r.Context().Value(types.UserIDKey).(int)
+	if !ok {
+		render.Status(r, http.StatusUnauthorized)
+		render.JSON(w, r, map[string]string{
+			"error": "Ensure that you are logged in.",
+		})
+		return
+	}
+
+	post, ok := h.findPost(w, r)
+	if !ok {
+		return
+	}
+
+	// the router may hand over the emoji still percent-encoded
+	emoji, err := url.PathUnescape(chi.URLParam(r, "emoji"))
+	if err != nil {
+		err = services.ErrInvalidReaction
+	} else {
+		emoji, err = change(r.Context(), post, int64(userID), emoji)
+	}
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidReaction) {
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, map[string]interface{}{
+				"error": map[string]string{"emoji": "Unsupported reaction."},
+			})
+			return
+		}
+
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{
+			"error": "Internal server error.",
+		})
+		return
+	}
+
+	if err := h.reactions.AttachCounts(r.Context(), post); err != nil {
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{
+			"error": "Internal server error.",
+		})
+		return
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, map[string]interface{}{
+		"emoji":     emoji,
+		"reactions": post.Reactions,
+		"message":   message,
+	})
+}
+
+// findPost loads the post named in the URL, writing an error response if it cannot.
+func (h *reactionHandler) findPost(w http.ResponseWriter, r *http.Request) (*models.Post, bool) {
+	postId, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, map[string]string{
+			"error": "Invalid post ID.",
+		})
+		return nil, false
+	}
+
+	post, err := h.posts.FindPostById(r.Context(), int64(postId))
+	if err != nil {
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{
+			"error": "Internal server error.",
+		})
+		return nil, false
+	}
+
+	if post == nil {
+		render.Status(r, http.StatusNotFound)
+		render.JSON(w, r, map[string]string{
+			"error": "Post does not exists.",
+		})
+		return nil, false
+	}
+
+	return post, true
+}
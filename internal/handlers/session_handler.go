@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/achintha-dilshan/go-rest-api/internal/services"
+	"github.com/achintha-dilshan/go-rest-api/internal/types"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+)
+
+type sessionHandler struct {
+	service services.SessionService
+}
+
+type SessionHandler interface {
+	GetSessions(w http.ResponseWriter, r *http.Request)
+	RevokeSession(w http.ResponseWriter, r *http.Request)
+}
+
+func NewSessionHandler(service services.SessionService) SessionHandler {
+	return &sessionHandler{
+		service: service,
+	}
+}
+
+// get the active sessions of the logged-in user
+func (h *sessionHandler) GetSessions(w http.ResponseWriter, r *http.Request) {
+	// get user ID from the context
+	userID, ok := r.Context().Value(types.UserIDKey).(int)
+	if !ok {
+		render.Status(r, http.StatusUnauthorized)
+		render.JSON(w, r, map[string]string{
+			"error": "Ensure that you are logged in.",
+		})
+		return
+	}
+
+	sessions, err := h.service.FindUserSessions(r.Context(), int64(userID))
+	if err != nil {
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{
+			"error": "Internal server error.",
+		})
+		return
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, map[string]interface{}{
+		"sessions":           sessions,
+		"current_session_id": r.Context().Value(types.SessionIDKey),
+	})
+}
+
+// revoke one of the logged-in user's sessions
+func (h *sessionHandler) RevokeSession(w http.ResponseWriter, r *http.Request) {
+	// get user ID from the context
+	userID, ok := r.Context().Value(types.UserIDKey).(int)
+	if !ok {
+		render.Status(r, http.StatusUnauthorized)
+		render.JSON(w, r, map[string]string{
+			"error": "Ensure that you are logged in.",
+		})
+		return
+	}
+
+	sessionId, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, map[string]string{
+			"error": "Invalid session ID.",
+		})
+		return
+	}
+
+	// find the session
+	session, err := h.service.FindSessionById(r.Context(), int64(sessionId))
+	if err != nil {
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{
+			"error": "Internal server error.",
+		})
+		return
+	}
+
+	// sessions of other users are reported as missing
+	if session == nil || session.UserId != int64(userID) || session.RevokedAt != nil {
+		render.Status(r, http.StatusNotFound)
+		render.JSON(w, r, map[string]string{
+			"error": "Session does not exists.",
+		})
+		return
+	}
+
+	if err := h.service.RevokeSession(r.Context(), session.Id); err != nil {
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{
+			"error": "Internal server error.",
+		})
+		return
+	}
+
+	// send success response
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, map[string]interface{}{
+		"message": "Session revoked successfully.",
+	})
+}
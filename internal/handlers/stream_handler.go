@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/achintha-dilshan/go-rest-api/internal/services"
+	"github.com/achintha-dilshan/go-rest-api/internal/types"
+	"github.com/achintha-dilshan/go-rest-api/internal/utils/broker"
+	"github.com/go-chi/render"
+)
+
+// how often an idle stream sends a comment to keep proxies from closing it
+const streamHeartbeat = 15 * time.Second
+
+// how long clients wait before reconnecting, in milliseconds
+const streamRetry = 3000
+
+// how often the stream of a logged-in user checks their session is still active
+const streamSessionCheck = time.Minute
+
+type streamHandler struct {
+	events   *broker.Broker
+	sessions services.SessionService
+	users    services.UserService
+}
+
+type StreamHandler interface {
+	Stream(w http.ResponseWriter, r *http.Request)
+}
+
+func NewStreamHandler(events *broker.Broker, sessions services.SessionService, users services.UserService) StreamHandler {
+	return &streamHandler{
+		events:   events,
+		sessions: sessions,
+		users:    users,
+	}
+}
+
+// stream events as Server-Sent Events. Public events go to everyone, and a
+// logged-in user also gets their private ones. Clients resuming with
+// Last-Event-ID are sent what they missed; when that is no longer known a
+// "reset" event tells them to reload instead. A user's stream ends once
+// their session is revoked or they are suspended.
+func (h *streamHandler) Stream(w http.ResponseWriter, r *http.Request) {
+	var userId int64
+	if id, ok := r.Context().Value(types.UserIDKey).(int); ok {
+		userId = int64(id)
+	}
+	sessionId, _ := r.Context().Value(types.SessionIDKey).(int64)
+
+	var lastId *uint64
+	if header := r.Header.Get("Last-Event-ID"); header != "" {
+		id, err := strconv.ParseUint(header, 10, 64)
+		if err != nil {
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, map[string]string{
+				"error": "Invalid Last-Event-ID header.",
+			})
+			return
+		}
+		lastId = &id
+	}
+
+	rc := http.NewResponseController(w)
+	// streams outlive any write timeout set for ordinary requests
+	_ = rc.SetWriteDeadline(time.Time{})
+
+	sub, missed, complete := h.events.Subscribe(userId, lastId)
+	defer h.events.Unsubscribe(sub)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	fmt.Fprintf(w, "retry: %d\n\n", streamRetry)
+	if !complete {
+		fmt.Fprint(w, "event: reset\ndata: {}\n\n")
+	}
+	for _, event := range missed {
+		writeEvent(w, event)
+	}
+	if err := rc.Flush(); err != nil {
+		return
+	}
+
+	heartbeat := time.NewTicker(streamHeartbeat)
+	defer heartbeat.Stop()
+
+	// anonymous streams have no session to check
+	var sessionCheck <-chan time.Time
+	if userId != 0 {
+		ticker := time.NewTicker(streamSessionCheck)
+		defer ticker.Stop()
+		sessionCheck = ticker.C
+	}
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case event, ok := <-sub.Events:
+			if !ok {
+				// dropped for falling behind, or shutting down; the client resumes from its last ID
+				return
+			}
+			writeEvent(w, event)
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": ping\n\n")
+		case <-sessionCheck:
+			// a failed check keeps the stream, as the database may be back by the next one
+			if active, err := sessionActive(r.Context(), h.sessions, h.users, sessionId); err == nil && !active {
+				return
+			}
+			continue
+		}
+
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+// writeEvent writes an event in the text/event-stream format. Event data is
+// JSON, which never spans lines.
+func writeEvent(w http.ResponseWriter, event broker.Event) {
+	fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.Id, event.Type, event.Data)
+}
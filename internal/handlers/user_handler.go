@@ -3,35 +3,88 @@ package handlers
 import (
 	"encoding/json"
 	"net/http"
+	"strings"
 
 	"github.com/achintha-dilshan/go-rest-api/internal/services"
 	"github.com/achintha-dilshan/go-rest-api/internal/types"
+	"github.com/achintha-dilshan/go-rest-api/internal/utils/password"
 	"github.com/achintha-dilshan/go-rest-api/internal/utils/validator"
 	"github.com/go-chi/render"
-	"golang.org/x/crypto/bcrypt"
 )
 
 type userHandler struct {
 	service services.UserService
+	posts   services.PostService
+	policy  *password.Policy
+	hasher  password.Hasher
 }
 
 type UserHandler interface {
+	GetMe(w http.ResponseWriter, r *http.Request)
 	ResetPassword(w http.ResponseWriter, r *http.Request)
 	UpdateUser(w http.ResponseWriter, r *http.Request)
 	DeleteUser(w http.ResponseWriter, r *http.Request)
 }
 
-func NewUserHandler(service services.UserService) UserHandler {
+func NewUserHandler(service services.UserService, posts services.PostService, policy *password.Policy, hasher password.Hasher) UserHandler {
 	return &userHandler{
 		service: service,
+		posts:   posts,
+		policy:  policy,
+		hasher:  hasher,
 	}
 }
 
+// get the current user, including private details
+func (h *userHandler) GetMe(w http.ResponseWriter, r *http.Request) {
+	// get user ID from the context
+	userID, ok := r.Context().Value(types.UserIDKey).(int)
+	if !ok {
+		render.Status(r, http.StatusUnauthorized)
+		render.JSON(w, r, map[string]string{
+			"error": "Ensure that you are logged in.",
+		})
+		return
+	}
+
+	user, err := h.service.FindUserById(r.Context(), int64(userID))
+	if err != nil {
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{
+			"error": "Internal server error.",
+		})
+		return
+	}
+
+	if user == nil {
+		render.Status(r, http.StatusUnauthorized)
+		render.JSON(w, r, map[string]string{
+			"error": "Ensure that you are logged in.",
+		})
+		return
+	}
+
+	postCount, err := h.posts.CountPostsByAuthor(r.Context(), user.Id)
+	if err != nil {
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{
+			"error": "Internal server error.",
+		})
+		return
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, map[string]interface{}{
+		"user":       user,
+		"post_count": postCount,
+	})
+}
+
 // reset password
 func (h *userHandler) ResetPassword(w http.ResponseWriter, r *http.Request) {
 	var req struct {
 		OldPassword        string `json:"old_password" validate:"required"`
-		NewPassword        string `json:"new_password" validate:"required,min=3"`
+		NewPassword        string `json:"new_password" validate:"required"`
 		ConfirmNewPassword string `json:"confirm_new_password"`
 	}
 
@@ -73,7 +126,7 @@ func (h *userHandler) ResetPassword(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// compare old password with stored password
-	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.OldPassword)); err != nil {
+	if match, err := h.hasher.Verify(req.OldPassword, user.Password); err != nil || !match {
 		render.Status(r, http.StatusBadRequest)
 		render.JSON(w, r, map[string]string{
 			"error": "Old password is incorrect.",
@@ -99,8 +152,28 @@ func (h *userHandler) ResetPassword(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// check the new password against the password policy
+	message, err := h.policy.Validate(req.NewPassword, user.Name, user.Email)
+	if err != nil {
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{
+			"error": "Internal server error.",
+		})
+		return
+	}
+
+	if message != "" {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, map[string]interface{}{
+			"error": map[string]interface{}{
+				"new_password": message,
+			},
+		})
+		return
+	}
+
 	// Hash the new password
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.NewPassword), bcrypt.DefaultCost)
+	hashedPassword, err := h.hasher.Hash(req.NewPassword)
 	if err != nil {
 		render.Status(r, http.StatusInternalServerError)
 		render.JSON(w, r, map[string]string{
@@ -110,7 +183,8 @@ func (h *userHandler) ResetPassword(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// update user's password
-	user.Password = string(hashedPassword)
+	user.Password = hashedPassword
+	user.PasswordResetRequired = false
 	if err := h.service.UpdateUser(r.Context(), user); err != nil {
 		render.Status(r, http.StatusInternalServerError)
 		render.JSON(w, r, map[string]string{
@@ -129,8 +203,10 @@ func (h *userHandler) ResetPassword(w http.ResponseWriter, r *http.Request) {
 // update user
 func (h *userHandler) UpdateUser(w http.ResponseWriter, r *http.Request) {
 	var req struct {
-		Name  string `json:"name" validate:"required,min=3"`
-		Email string `json:"email" validate:"required,email"`
+		Name      string  `json:"name" validate:"required,min=3"`
+		Email     string  `json:"email" validate:"required,email"`
+		Bio       *string `json:"bio" validate:"max=500"`
+		AvatarURL *string `json:"avatar_url" validate:"url,max=2048"`
 	}
 
 	// decode request body
@@ -173,6 +249,13 @@ func (h *userHandler) UpdateUser(w http.ResponseWriter, r *http.Request) {
 	// updated user
 	user.Name = req.Name
 	user.Email = req.Email
+	// the profile fields are optional and left alone when omitted
+	if req.Bio != nil {
+		user.Bio = strings.TrimSpace(*req.Bio)
+	}
+	if req.AvatarURL != nil {
+		user.AvatarURL = *req.AvatarURL
+	}
 	if err := h.service.UpdateUser(r.Context(), user); err != nil {
 		render.Status(r, http.StatusInternalServerError)
 		render.JSON(w, r, map[string]string{
@@ -184,9 +267,11 @@ func (h *userHandler) UpdateUser(w http.ResponseWriter, r *http.Request) {
 	// send success response
 	render.Status(r, http.StatusOK)
 	render.JSON(w, r, map[string]interface{}{
-		"name":    user.Name,
-		"email":   user.Email,
-		"message": "User updated successfully.",
+		"name":       user.Name,
+		"email":      user.Email,
+		"bio":        user.Bio,
+		"avatar_url": user.AvatarURL,
+		"message":    "User updated successfully.",
 	})
 }
 
@@ -0,0 +1,342 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/achintha-dilshan/go-rest-api/internal/models"
+	"github.com/achintha-dilshan/go-rest-api/internal/services"
+	"github.com/achintha-dilshan/go-rest-api/internal/types"
+	"github.com/achintha-dilshan/go-rest-api/internal/utils/validator"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+)
+
+type webhookHandler struct {
+	service services.WebhookService
+}
+
+type WebhookHandler interface {
+	GetWebhooks(w http.ResponseWriter, r *http.Request)
+	CreateWebhook(w http.ResponseWriter, r *http.Request)
+	GetWebhook(w http.ResponseWriter, r *http.Request)
+	UpdateWebhook(w http.ResponseWriter, r *http.Request)
+	DeleteWebhook(w http.ResponseWriter, r *http.Request)
+	GetDeliveries(w http.ResponseWriter, r *http.Request)
+}
+
+func NewWebhookHandler(service services.WebhookService) WebhookHandler {
+	return &webhookHandler{
+		service: service,
+	}
+}
+
+// get the webhooks of the current user
+func (h *webhookHandler) GetWebhooks(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(types.UserIDKey).(int)
+	if !ok {
+		render.Status(r, http.StatusUnauthorized)
+		render.JSON(w, r, map[string]string{
+			"error": "Ensure that you are logged in.",
+		})
+		return
+	}
+
+	webhooks, err := h.service.FindWebhooksByUser(r.Context(), int64(userID))
+	if err != nil {
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{
+			"error": "Internal server error.",
+		})
+		return
+	}
+
+	if webhooks == nil {
+		webhooks = []*models.Webhook{}
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, map[string]interface{}{
+		"webhooks": webhooks,
+		"events":   services.WebhookEvents,
+	})
+}
+
+// create a webhook for the current user. The signing secret is only ever
+// shown in this response.
+func (h *webhookHandler) CreateWebhook(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		URL    string   `json:"url" validate:"required,url,max=2048"`
+		Events []string `json:"events"`
+	}
+
+	// decode request body
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, map[string]string{
+			"error": "Invalid JSON payload.",
+		})
+		return
+	}
+
+	// validate user inputs
+	validator := validator.New()
+	if err := validator.Validate(req); err != nil {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, err)
+		return
+	}
+
+	if len(req.Events) == 0 {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, map[string]interface{}{
+			"error": map[string]string{"events": "This field is required."},
+		})
+		return
+	}
+
+	userID, ok := r.Context().Value(types.UserIDKey).(int)
+	if !ok {
+		render.Status(r, http.StatusUnauthorized)
+		render.JSON(w, r, map[string]string{
+			"error": "Ensure that you are logged in.",
+		})
+		return
+	}
+
+	webhook := models.Webhook{
+		UserId: int64(userID),
+		URL:    req.URL,
+		Events: req.Events,
+		Active: true,
+	}
+	id, err := h.service.CreateWebhook(r.Context(), &webhook)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	created, err := h.service.FindWebhookById(r.Context(), id)
+	if err != nil || created == nil {
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{
+			"error": "Internal server error.",
+		})
+		return
+	}
+
+	render.Status(r, http.StatusCreated)
+	render.JSON(w, r, map[string]interface{}{
+		"webhook": created,
+		"secret":  webhook.Secret,
+		"message": "Webhook created successfully. Store the secret now; it will not be shown again.",
+	})
+}
+
+// get a webhook of the current user
+func (h *webhookHandler) GetWebhook(w http.ResponseWriter, r *http.Request) {
+	webhook, ok := h.findWebhook(w, r)
+	if !ok {
+		return
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, map[string]interface{}{
+		"webhook": webhook,
+	})
+}
+
+// update a webhook of the current user. Setting active to true turns a
+// webhook that was disabled for failing back on.
+func (h *webhookHandler) UpdateWebhook(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		URL    *string   `json:"url" validate:"url,max=2048"`
+		Events *[]string `json:"events"`
+		Active *bool     `json:"active"`
+	}
+
+	// decode request body
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, map[string]string{
+			"error": "Invalid JSON payload.",
+		})
+		return
+	}
+
+	// validate user inputs
+	validator := validator.New()
+	if err := validator.Validate(req); err != nil {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, err)
+		return
+	}
+
+	if req.URL != nil && *req.URL == "" {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, map[string]interface{}{
+			"error": map[string]string{"url": "This field is required."},
+		})
+		return
+	}
+
+	if req.Events != nil && len(*req.Events) == 0 {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, map[string]interface{}{
+			"error": map[string]string{"events": "This field is required."},
+		})
+		return
+	}
+
+	webhook, ok := h.findWebhook(w, r)
+	if !ok {
+		return
+	}
+
+	if req.URL != nil {
+		webhook.URL = *req.URL
+	}
+	if req.Events != nil {
+		webhook.Events = *req.Events
+	}
+	if req.Active != nil {
+		webhook.Active = *req.Active
+	}
+
+	if err := h.service.UpdateWebhook(r.Context(), webhook); err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	updated, err := h.service.FindWebhookById(r.Context(), webhook.Id)
+	if err != nil || updated == nil {
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{
+			"error": "Internal server error.",
+		})
+		return
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, map[string]interface{}{
+		"webhook": updated,
+		"message": "Webhook updated successfully.",
+	})
+}
+
+// delete a webhook of the current user
+func (h *webhookHandler) DeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	webhook, ok := h.findWebhook(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteWebhook(r.Context(), webhook.Id); err != nil {
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{
+			"error": "Internal server error.",
+		})
+		return
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, map[string]interface{}{
+		"message": "Webhook deleted successfully.",
+	})
+}
+
+// get the delivery log of a webhook of the current user, newest first
+func (h *webhookHandler) GetDeliveries(w http.ResponseWriter, r *http.Request) {
+	webhook, ok := h.findWebhook(w, r)
+	if !ok {
+		return
+	}
+
+	page, perPage := paginate(r)
+
+	deliveries, total, err := h.service.FindDeliveries(r.Context(), webhook.Id, perPage, (page-1)*perPage)
+	if err != nil {
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{
+			"error": "Internal server error.",
+		})
+		return
+	}
+
+	if deliveries == nil {
+		deliveries = []*models.WebhookDelivery{}
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, map[string]interface{}{
+		"deliveries": deliveries,
+		"page":       page,
+		"per_page":   perPage,
+		"total":      total,
+	})
+}
+
+// findWebhook loads the webhook in the URL, writing the error response when
+// it is missing or belongs to someone else.
+func (h *webhookHandler) findWebhook(w http.ResponseWriter, r *http.Request) (*models.Webhook, bool) {
+	userID, ok := r.Context().Value(types.UserIDKey).(int)
+	if !ok {
+		render.Status(r, http.StatusUnauthorized)
+		render.JSON(w, r, map[string]string{
+			"error": "Ensure that you are logged in.",
+		})
+		return nil, false
+	}
+
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, map[string]string{
+			"error": "Invalid webhook ID.",
+		})
+		return nil, false
+	}
+
+	webhook, err := h.service.FindWebhookById(r.Context(), id)
+	if err != nil {
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{
+			"error": "Internal server error.",
+		})
+		return nil, false
+	}
+
+	// other users' webhooks are reported missing rather than forbidden
+	if webhook == nil || webhook.UserId != int64(userID) {
+		render.Status(r, http.StatusNotFound)
+		render.JSON(w, r, map[string]string{
+			"error": "Webhook does not exists.",
+		})
+		return nil, false
+	}
+
+	return webhook, true
+}
+
+// writeError writes the response for an error saving a webhook.
+func (h *webhookHandler) writeError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, services.ErrUnknownWebhookEvent):
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, map[string]interface{}{
+			"error": map[string]string{"events": "Unknown webhook event."},
+		})
+	case errors.Is(err, services.ErrTooManyWebhooks):
+		render.Status(r, http.StatusConflict)
+		render.JSON(w, r, map[string]string{
+			"error": "You already have the most webhooks allowed.",
+		})
+	default:
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{
+			"error": "Internal server error.",
+		})
+	}
+}
@@ -0,0 +1,322 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/achintha-dilshan/go-rest-api/internal/services"
+	"github.com/achintha-dilshan/go-rest-api/internal/types"
+	"github.com/achintha-dilshan/go-rest-api/internal/utils/broker"
+	"github.com/achintha-dilshan/go-rest-api/internal/utils/hub"
+	"github.com/go-chi/render"
+	"github.com/gorilla/websocket"
+)
+
+// WebSocketProtocol is the subprotocol clients offer, alongside their token.
+const WebSocketProtocol = "realtime.v1"
+
+// limits of a WebSocket connection
+const (
+	wsWriteWait        = 10 * time.Second
+	wsPongWait         = 60 * time.Second
+	wsPingInterval     = 50 * time.Second
+	wsMaxMessageBytes  = 4096
+	wsMaxSubscriptions = 100
+)
+
+type wsHandler struct {
+	hub      *hub.Hub
+	events   *broker.Broker
+	posts    services.PostService
+	follows  services.FollowService
+	sessions services.SessionService
+	users    services.UserService
+	upgrader websocket.Upgrader
+}
+
+type WSHandler interface {
+	Connect(w http.ResponseWriter, r *http.Request)
+}
+
+// NewWSHandler creates the WebSocket handler. Browsers may connect from the
+// API's own origin or from appURL, which keeps other sites from using a
+// visitor's session cookie.
+func NewWSHandler(hub *hub.Hub, events *broker.Broker, posts services.PostService, follows services.FollowService, sessions services.SessionService, users services.UserService, appURL string) WSHandler {
+	allowed := ""
+	if u, err := url.Parse(appURL); err == nil {
+		allowed = u.Scheme + "://" + u.Host
+	}
+
+	return &wsHandler{
+		hub:      hub,
+		events:   events,
+		posts:    posts,
+		follows:  follows,
+		sessions: sessions,
+		users:    users,
+		upgrader: websocket.Upgrader{
+			Subprotocols: []string{WebSocketProtocol},
+			CheckOrigin: func(r *http.Request) bool {
+				origin := r.Header.Get("Origin")
+				if origin == "" || strings.EqualFold(origin, allowed) {
+					return true
+				}
+				u, err := url.Parse(origin)
+				return err == nil && strings.EqualFold(u.Host, r.Host)
+			},
+		},
+	}
+}
+
+// wsMessage is a message from a client.
+type wsMessage struct {
+	Type    string  `json:"type"`
+	PostId  int64   `json:"post_id,omitempty"`
+	UserIds []int64 `json:"user_ids,omitempty"`
+}
+
+// wsEvent is a broker event sent to a client.
+type wsEvent struct {
+	Type string          `json:"type"`
+	Id   uint64          `json:"id"`
+	Data json.RawMessage `json:"data"`
+}
+
+// postSet is the posts a connection is subscribed to.
+type postSet struct {
+	mu  sync.Mutex
+	ids map[int64]struct{}
+}
+
+func (s *postSet) has(id int64) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, ok := s.ids[id]
+	return ok
+}
+
+// open a WebSocket for the current user. It delivers their notifications as
+// they happen, the events of the posts they subscribe to, and presence
+// updates of the users they watch. The connection is closed once its session
+// is revoked or the user suspended.
+func (h *wsHandler) Connect(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(types.UserIDKey).(int)
+	if !ok {
+		render.Status(r, http.StatusUnauthorized)
+		render.JSON(w, r, map[string]string{
+			"error": "Ensure that you are logged in.",
+		})
+		return
+	}
+
+	// the upgrader answers failed handshakes itself
+	conn, err := h.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	client, err := h.hub.Join(int64(userID))
+	if err != nil {
+		closeWebSocket(conn, websocket.CloseGoingAway, "Server is shutting down.")
+		return
+	}
+	defer h.hub.Leave(client)
+
+	sub, _, _ := h.events.Subscribe(int64(userID), nil)
+	defer h.events.Unsubscribe(sub)
+
+	posts := &postSet{ids: make(map[int64]struct{})}
+	readDone := make(chan struct{})
+	go func() {
+		defer close(readDone)
+		h.read(r, conn, client, posts)
+	}()
+
+	sessionId, _ := r.Context().Value(types.SessionIDKey).(int64)
+	h.write(r, conn, client, sub, posts, sessionId, readDone)
+
+	// closing the connection ends the read loop
+	conn.Close()
+	<-readDone
+}
+
+// read handles the messages of a client until the connection closes.
+func (h *wsHandler) read(r *http.Request, conn *websocket.Conn, client *hub.Client, posts *postSet) {
+	conn.SetReadLimit(wsMaxMessageBytes)
+	conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	})
+
+	for {
+		var message wsMessage
+		if err := conn.ReadJSON(&message); err != nil {
+			if errors.As(err, new(*json.SyntaxError)) || errors.As(err, new(*json.UnmarshalTypeError)) {
+				h.reply(client, map[string]string{"type": "error", "error": "Invalid JSON payload."})
+				continue
+			}
+			return
+		}
+
+		switch message.Type {
+		case "subscribe":
+			h.subscribe(r, client, posts, message.PostId)
+		case "unsubscribe":
+			posts.mu.Lock()
+			delete(posts.ids, message.PostId)
+			posts.mu.Unlock()
+			h.reply(client, map[string]interface{}{"type": "unsubscribed", "post_id": message.PostId})
+		case "presence.watch":
+			if h.hub.Watching(client)+len(message.UserIds) > wsMaxSubscriptions {
+				h.reply(client, map[string]string{"type": "error", "error": "Too many users watched."})
+				continue
+			}
+			h.watch(r, client, message.UserIds)
+		case "presence.unwatch":
+			h.hub.Unwatch(client, message.UserIds)
+		default:
+			h.reply(client, map[string]string{"type": "error", "error": "Unknown message type."})
+		}
+	}
+}
+
+// subscribe adds a post to the posts a client gets events for. These are the
+// post's own post.* events; there are no comments yet, so a subscription is
+// not a comment thread until they exist.
+func (h *wsHandler) subscribe(r *http.Request, client *hub.Client, posts *postSet, postId int64) {
+	posts.mu.Lock()
+	count := len(posts.ids)
+	posts.mu.Unlock()
+
+	if count >= wsMaxSubscriptions {
+		h.reply(client, map[string]string{"type": "error", "error": "Too many subscriptions."})
+		return
+	}
+
+	post, err := h.posts.FindPostById(r.Context(), postId)
+	if err != nil {
+		h.reply(client, map[string]string{"type": "error", "error": "Internal server error."})
+		return
+	}
+	if post == nil {
+		h.reply(client, map[string]string{"type": "error", "error": "Post does not exists."})
+		return
+	}
+
+	posts.mu.Lock()
+	posts.ids[postId] = struct{}{}
+	posts.mu.Unlock()
+
+	h.reply(client, map[string]interface{}{"type": "subscribed", "post_id": postId})
+}
+
+// watch adds the users a client gets presence updates of. Only users the
+// client's user follows or is followed by can be watched; the others are
+// listed as denied.
+func (h *wsHandler) watch(r *http.Request, client *hub.Client, userIds []int64) {
+	connected, err := h.follows.FindConnectedIds(r.Context(), client.UserId, userIds)
+	if err != nil {
+		h.reply(client, map[string]string{"type": "error", "error": "Internal server error."})
+		return
+	}
+
+	allowed := make(map[int64]bool, len(connected))
+	for _, id := range connected {
+		allowed[id] = true
+	}
+
+	denied := []int64{}
+	for _, id := range userIds {
+		if !allowed[id] {
+			denied = append(denied, id)
+		}
+	}
+
+	online := h.hub.Watch(client, connected)
+	h.reply(client, map[string]interface{}{"type": "presence.state", "online": online, "denied": denied})
+}
+
+// write sends queued messages, events and pings to a client until the
+// connection ends, the client falls behind, the session ends, or the server
+// shuts down. The session is checked again with every ping.
+func (h *wsHandler) write(r *http.Request, conn *websocket.Conn, client *hub.Client, sub *broker.Subscription, posts *postSet, sessionId int64, readDone <-chan struct{}) {
+	ping := time.NewTicker(wsPingInterval)
+	defer ping.Stop()
+
+	for {
+		select {
+		case <-readDone:
+			return
+		case <-client.Done():
+			closeWebSocket(conn, websocket.CloseGoingAway, "Connection closed by the server.")
+			return
+		case message := <-client.Send:
+			if err := writeWebSocket(conn, message); err != nil {
+				return
+			}
+		case event, ok := <-sub.Events:
+			if !ok {
+				// the broker drops subscribers that fall behind
+				closeWebSocket(conn, websocket.CloseTryAgainLater, "Too slow to keep up.")
+				return
+			}
+			if !visibleTo(event, posts) {
+				continue
+			}
+			message, _ := json.Marshal(wsEvent{Type: event.Type, Id: event.Id, Data: event.Data})
+			if err := writeWebSocket(conn, message); err != nil {
+				return
+			}
+		case <-ping.C:
+			// a failed check keeps the connection, as the database may be back by the next one
+			if active, err := sessionActive(r.Context(), h.sessions, h.users, sessionId); err == nil && !active {
+				closeWebSocket(conn, websocket.ClosePolicyViolation, "Session has ended.")
+				return
+			}
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(wsWriteWait)); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// reply queues a message for a client.
+func (h *wsHandler) reply(client *hub.Client, message any) {
+	raw, err := json.Marshal(message)
+	if err == nil {
+		h.hub.Deliver(client, raw)
+	}
+}
+
+// visibleTo reports whether a connection gets an event: its user's private
+// events, and the post.* events of the posts it subscribed to.
+func visibleTo(event broker.Event, posts *postSet) bool {
+	if event.UserId != 0 {
+		return true
+	}
+
+	if !strings.HasPrefix(event.Type, "post.") {
+		return false
+	}
+
+	var post struct {
+		Id int64 `json:"id"`
+	}
+	return json.Unmarshal(event.Data, &post) == nil && posts.has(post.Id)
+}
+
+func writeWebSocket(conn *websocket.Conn, message []byte) error {
+	conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+	return conn.WriteMessage(websocket.TextMessage, message)
+}
+
+func closeWebSocket(conn *websocket.Conn, code int, reason string) {
+	conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, reason), time.Now().Add(wsWriteWait))
+}
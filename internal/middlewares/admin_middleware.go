@@ -0,0 +1,24 @@
+package middlewares
+
+import (
+	"net/http"
+
+	"github.com/achintha-dilshan/go-rest-api/internal/models"
+	"github.com/achintha-dilshan/go-rest-api/internal/types"
+	"github.com/go-chi/render"
+)
+
+// RequireAdmin only lets administrators through. It must run after AuthMiddleware.
+func RequireAdmin(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if role, _ := r.Context().Value(types.UserRoleKey).(string); role != models.RoleAdmin {
+			render.Status(r, http.StatusForbidden)
+			render.JSON(w, r, map[string]string{
+				"error": "Admin permission required.",
+			})
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
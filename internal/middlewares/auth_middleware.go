@@ -2,32 +2,121 @@ package middlewares
 
 import (
 	"context"
+	"log"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/achintha-dilshan/go-rest-api/config"
+	"github.com/achintha-dilshan/go-rest-api/internal/services"
 	"github.com/achintha-dilshan/go-rest-api/internal/types"
+	"github.com/achintha-dilshan/go-rest-api/internal/utils/cookie"
 	"github.com/go-chi/render"
 	"github.com/golang-jwt/jwt/v5"
 )
 
-func AuthMiddleware(next http.Handler) http.Handler {
+// sessions are only touched when they were last seen longer ago than this
+const sessionTouchInterval = time.Minute
+
+type authMiddleware struct {
+	sessions services.SessionService
+	users    services.UserService
+}
+
+type AuthMiddleware interface {
+	Authenticate(next http.Handler) http.Handler
+	AuthenticatePendingReset(next http.Handler) http.Handler
+	AuthenticateOptional(next http.Handler) http.Handler
+}
+
+func NewAuthMiddleware(sessions services.SessionService, users services.UserService) AuthMiddleware {
+	return &authMiddleware{
+		sessions: sessions,
+		users:    users,
+	}
+}
+
+// WebSocketTokenPrefix marks the WebSocket subprotocol that carries the
+// token, since browsers cannot set headers on WebSocket requests.
+const WebSocketTokenPrefix = "bearer."
+
+// extractToken reads the token from the Authorization header, from a
+// WebSocket subprotocol, or from the session cookie when cookie auth is enabled.
+func extractToken(r *http.Request) (string, string) {
+	authHeader := r.Header.Get("Authorization")
+	if authHeader == "" {
+		for _, protocol := range websocketProtocols(r) {
+			if token, ok := strings.CutPrefix(protocol, WebSocketTokenPrefix); ok && token != "" {
+				return token, ""
+			}
+		}
+
+		if cookie.Enabled() {
+			if c, err := r.Cookie(cookie.SessionName); err == nil && c.Value != "" {
+				return c.Value, ""
+			}
+		}
+		return "", "Authorization header missing."
+	}
+
+	// Extract token from "Bearer <token>"
+	tokenString := strings.TrimPrefix(authHeader, "Bearer ")
+	if tokenString == authHeader {
+		return "", "Invalid Authorization header format."
+	}
+
+	return tokenString, ""
+}
+
+// websocketProtocols returns the subprotocols a WebSocket handshake offers.
+func websocketProtocols(r *http.Request) []string {
+	if !strings.EqualFold(r.Header.Get("Upgrade"), "websocket") {
+		return nil
+	}
+
+	var protocols []string
+	for _, header := range r.Header.Values("Sec-WebSocket-Protocol") {
+		for _, protocol := range strings.Split(header, ",") {
+			protocols = append(protocols, strings.TrimSpace(protocol))
+		}
+	}
+	return protocols
+}
+
+// Authenticate validates the token, its session and the account before passing the request on.
+func (m *authMiddleware) Authenticate(next http.Handler) http.Handler {
+	return m.authenticate(next, false)
+}
+
+// AuthenticatePendingReset is Authenticate for the few routes a user who has
+// been told to change their password can still use.
+func (m *authMiddleware) AuthenticatePendingReset(next http.Handler) http.Handler {
+	return m.authenticate(next, true)
+}
+
+// AuthenticateOptional is Authenticate for routes that also serve anonymous
+// users. Requests without credentials pass through without a user; requests
+// with credentials must pass Authenticate.
+func (m *authMiddleware) AuthenticateOptional(next http.Handler) http.Handler {
+	authenticated := m.Authenticate(next)
+
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		authHeader := r.Header.Get("Authorization")
-		if authHeader == "" {
-			render.Status(r, http.StatusUnauthorized)
-			render.JSON(w, r, map[string]string{
-				"error": "Authorization header missing.",
-			})
+		if token, _ := extractToken(r); token == "" && r.Header.Get("Authorization") == "" {
+			next.ServeHTTP(w, r)
 			return
 		}
 
-		// Extract token from "Bearer <token>"
-		tokenString := strings.TrimPrefix(authHeader, "Bearer ")
-		if tokenString == authHeader {
+		authenticated.ServeHTTP(w, r)
+	})
+}
+
+func (m *authMiddleware) authenticate(next http.Handler, allowPendingReset bool) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tokenString, errMsg := extractToken(r)
+		if errMsg != "" {
 			render.Status(r, http.StatusUnauthorized)
 			render.JSON(w, r, map[string]string{
-				"error": "Invalid Authorization header format.",
+				"error": errMsg,
 			})
 			return
 		}
@@ -67,8 +156,103 @@ func AuthMiddleware(next http.Handler) http.Handler {
 			return
 		}
 
-		// Add user ID to the context
+		sessionID, ok := claims["sid"].(float64)
+		if !ok {
+			render.Status(r, http.StatusUnauthorized)
+			render.JSON(w, r, map[string]string{
+				"error": "Session ID missing in token.",
+			})
+			return
+		}
+
+		// Ensure the session still exists and has not been revoked
+		session, err := m.sessions.FindSessionById(r.Context(), int64(sessionID))
+		if err != nil {
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, map[string]string{
+				"error": "Internal server error.",
+			})
+			return
+		}
+
+		if session == nil || session.RevokedAt != nil || session.UserId != int64(userID) {
+			render.Status(r, http.StatusUnauthorized)
+			render.JSON(w, r, map[string]string{
+				"error": "Session has been revoked.",
+			})
+			return
+		}
+
+		// The session decides whether this is an impersonation; the token must agree
+		var actorID int64
+		if act, ok := claims["act"].(map[string]interface{}); ok {
+			if sub, ok := act["sub"].(float64); ok {
+				actorID = int64(sub)
+			}
+		}
+
+		if (session.ImpersonatorId == nil && actorID != 0) || (session.ImpersonatorId != nil && *session.ImpersonatorId != actorID) {
+			render.Status(r, http.StatusUnauthorized)
+			render.JSON(w, r, map[string]string{
+				"error": "Invalid token claims.",
+			})
+			return
+		}
+
+		// Record activity without writing on every request
+		if time.Since(session.LastSeenAt) > sessionTouchInterval {
+			if err := m.sessions.TouchSession(r.Context(), session.Id); err != nil {
+				render.Status(r, http.StatusInternalServerError)
+				render.JSON(w, r, map[string]string{
+					"error": "Internal server error.",
+				})
+				return
+			}
+		}
+
+		// Ensure the account is still allowed in
+		user, err := m.users.FindUserById(r.Context(), session.UserId)
+		if err != nil {
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, map[string]string{
+				"error": "Internal server error.",
+			})
+			return
+		}
+
+		if user == nil {
+			render.Status(r, http.StatusUnauthorized)
+			render.JSON(w, r, map[string]string{
+				"error": "Ensure that you are logged in.",
+			})
+			return
+		}
+
+		if user.SuspendedAt != nil {
+			render.Status(r, http.StatusForbidden)
+			render.JSON(w, r, map[string]string{
+				"error": "Account is suspended.",
+			})
+			return
+		}
+
+		if user.PasswordResetRequired && !allowPendingReset {
+			render.Status(r, http.StatusForbidden)
+			render.JSON(w, r, map[string]string{
+				"error": "Password reset required.",
+			})
+			return
+		}
+
+		// Add user and session details to the context
 		ctx := context.WithValue(r.Context(), types.UserIDKey, int(userID))
+		ctx = context.WithValue(ctx, types.SessionIDKey, session.Id)
+		ctx = context.WithValue(ctx, types.UserRoleKey, user.Role)
+
+		if actorID != 0 {
+			ctx = context.WithValue(ctx, types.ImpersonatorIDKey, actorID)
+			log.Printf("Impersonated request: admin %d as user %d: %s %s", actorID, user.Id, r.Method, r.URL.Path)
+		}
 
 		// Pass to the next handler
 		next.ServeHTTP(w, r.WithContext(ctx))
@@ -0,0 +1,25 @@
+package middlewares
+
+import (
+	"net/http"
+
+	"github.com/achintha-dilshan/go-rest-api/internal/types"
+	"github.com/go-chi/render"
+)
+
+// BlockImpersonation rejects sensitive operations, such as changing the
+// password or deleting the account, while an admin is impersonating the user.
+// It must run after AuthMiddleware.
+func BlockImpersonation(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := r.Context().Value(types.ImpersonatorIDKey).(int64); ok {
+			render.Status(r, http.StatusForbidden)
+			render.JSON(w, r, map[string]string{
+				"error": "This action is not allowed while impersonating a user.",
+			})
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
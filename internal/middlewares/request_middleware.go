@@ -0,0 +1,32 @@
+package middlewares
+
+import (
+	"context"
+	"net"
+	"net/http"
+
+	"github.com/achintha-dilshan/go-rest-api/internal/types"
+	"github.com/go-chi/chi/middleware"
+)
+
+// RequestMetadata stores the request ID and client IP in the context so the
+// services layer can attach them to audit log entries. It must run after
+// chi's RequestID middleware.
+func RequestMetadata(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestID := middleware.GetReqID(r.Context())
+		if requestID != "" {
+			w.Header().Set(middleware.RequestIDHeader, requestID)
+		}
+
+		ip, _, err := net.SplitHostPort(r.RemoteAddr)
+		if err != nil {
+			ip = r.RemoteAddr
+		}
+
+		ctx := context.WithValue(r.Context(), types.RequestIDKey, requestID)
+		ctx = context.WithValue(ctx, types.ClientIPKey, ip)
+
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
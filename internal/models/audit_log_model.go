@@ -0,0 +1,23 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type AuditLog struct {
+	Id      int64  `json:"id"`
+	ActorId *int64 `json:"actor_id"`
+	// ImpersonatorId is set when the actor was being impersonated by an admin.
+	ImpersonatorId *int64          `json:"impersonator_id,omitempty"`
+	Action         string          `json:"action"`
+	TargetType     string          `json:"target_type"`
+	TargetId       *int64          `json:"target_id"`
+	IPAddress      string          `json:"ip_address"`
+	RequestId      string          `json:"request_id"`
+	Metadata       json.RawMessage `json:"metadata,omitempty"`
+	// Before and After are snapshots of the target around the change.
+	Before    json.RawMessage `json:"before,omitempty"`
+	After     json.RawMessage `json:"after,omitempty"`
+	CreatedAt time.Time       `json:"createdAt"`
+}
@@ -0,0 +1,11 @@
+package models
+
+import "time"
+
+// FollowUser is a user in a list of followers or followed authors.
+type FollowUser struct {
+	Id         int64     `json:"id"`
+	Name       string    `json:"name"`
+	AvatarURL  string    `json:"avatar_url"`
+	FollowedAt time.Time `json:"followedAt"`
+}
@@ -0,0 +1,42 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// job statuses
+const (
+	JobPending   = "pending"
+	JobRunning   = "running"
+	JobSucceeded = "succeeded"
+	// JobDead marks a job that ran out of attempts; it stays until retried or pruned.
+	JobDead = "dead"
+)
+
+// Job is a unit of background work.
+type Job struct {
+	Id          int64           `json:"id"`
+	Queue       string          `json:"queue"`
+	Type        string          `json:"type"`
+	Payload     json.RawMessage `json:"payload"`
+	UniqueKey   string          `json:"unique_key,omitempty"`
+	Status      string          `json:"status"`
+	Attempts    int             `json:"attempts"`
+	MaxAttempts int             `json:"max_attempts"`
+	LastError   string          `json:"last_error,omitempty"`
+	RunAt       time.Time       `json:"runAt"`
+	LockedUntil *time.Time      `json:"lockedUntil"`
+	FinishedAt  *time.Time      `json:"finishedAt"`
+	CreatedAt   time.Time       `json:"createdAt"`
+	UpdatedAt   *time.Time      `json:"updatedAt"`
+}
+
+// OutboxEvent is a domain event recorded in the same transaction as the
+// change it describes, waiting to be handed to the jobs that react to it.
+type OutboxEvent struct {
+	Id        int64           `json:"id"`
+	Event     string          `json:"event"`
+	Payload   json.RawMessage `json:"data"`
+	CreatedAt time.Time       `json:"createdAt"`
+}
@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+type MagicLink struct {
+	Id        int64      `json:"id"`
+	UserId    int64      `json:"user_id"`
+	TokenHash string     `json:"-"`
+	ExpiresAt time.Time  `json:"expiresAt"`
+	UsedAt    *time.Time `json:"usedAt,omitempty"`
+	CreatedAt time.Time  `json:"createdAt"`
+}
@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// Media is an uploaded image, owned by a user and optionally attached to a post.
+type Media struct {
+	Id           int64     `json:"id"`
+	UserId       int64     `json:"user_id"`
+	PostId       *int64    `json:"post_id"`
+	StorageKey   string    `json:"-"`
+	ThumbnailKey string    `json:"-"`
+	URL          string    `json:"url"`
+	ThumbnailURL string    `json:"thumbnail_url"`
+	ContentType  string    `json:"content_type"`
+	Size         int64     `json:"size"`
+	Width        int       `json:"width"`
+	Height       int       `json:"height"`
+	CreatedAt    time.Time `json:"createdAt"`
+}
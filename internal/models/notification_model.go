@@ -0,0 +1,29 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// notification types. Comments on a post will notify its author too, once
+// posts have comments; there is no comment type until then.
+const (
+	NotificationReaction = "reaction"
+	NotificationMention  = "mention"
+	NotificationFollow   = "follow"
+)
+
+// NotificationTypes lists every notification type, each of which users can turn off.
+var NotificationTypes = []string{NotificationReaction, NotificationMention, NotificationFollow}
+
+type Notification struct {
+	Id        int64           `json:"id"`
+	UserId    int64           `json:"user_id"`
+	ActorId   *int64          `json:"actor_id"`
+	ActorName string          `json:"actor_name"`
+	Type      string          `json:"type"`
+	PostId    *int64          `json:"post_id"`
+	Data      json.RawMessage `json:"data,omitempty"`
+	ReadAt    *time.Time      `json:"readAt"`
+	CreatedAt time.Time       `json:"createdAt"`
+}
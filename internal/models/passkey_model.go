@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+type Passkey struct {
+	Id           int64      `json:"id"`
+	UserId       int64      `json:"user_id"`
+	Name         string     `json:"name"`
+	CredentialId []byte     `json:"credential_id"`
+	PublicKey    []byte     `json:"-"`
+	SignCount    uint32     `json:"sign_count"`
+	LastUsedAt   *time.Time `json:"lastUsedAt,omitempty"`
+	CreatedAt    time.Time  `json:"createdAt"`
+}
@@ -2,11 +2,25 @@ package models
 
 import "time"
 
+// post body formats
+const (
+	PostFormatPlain    = "plain"
+	PostFormatMarkdown = "markdown"
+)
+
 type Post struct {
-	Id        int64     `json:"id"`
-	AuthorId  int64     `json:"author_id"`
-	Title     string    `json:"title"`
-	Body      string    `json:"body"`
-	CreatedAt time.Time `json:"createdAt"`
-	UpdatedAt time.Time `json:"updatedAt"`
+	Id       int64  `json:"id"`
+	AuthorId int64  `json:"author_id"`
+	Title    string `json:"title"`
+	Slug     string `json:"slug"`
+	Body     string `json:"body"`
+	Format   string `json:"format"`
+	// BodyHTML, Excerpt and ReadingTime are derived from the body when read.
+	BodyHTML    string `json:"body_html,omitempty"`
+	Excerpt     string `json:"excerpt,omitempty"`
+	ReadingTime int    `json:"reading_time,omitempty"`
+	// Reactions counts the reactions to the post by emoji.
+	Reactions map[string]int64 `json:"reactions"`
+	CreatedAt time.Time        `json:"createdAt"`
+	UpdatedAt time.Time        `json:"updatedAt"`
 }
@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+// Reaction is a user's emoji reaction to a post.
+type Reaction struct {
+	PostId        int64     `json:"post_id"`
+	UserId        int64     `json:"user_id"`
+	UserName      string    `json:"user_name"`
+	UserAvatarURL string    `json:"user_avatar_url"`
+	Emoji         string    `json:"emoji"`
+	CreatedAt     time.Time `json:"createdAt"`
+}
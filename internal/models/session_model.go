@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+type Session struct {
+	Id     int64 `json:"id"`
+	UserId int64 `json:"user_id"`
+	// ImpersonatorId is the admin acting as the user in an impersonation session.
+	ImpersonatorId *int64     `json:"impersonator_id,omitempty"`
+	UserAgent      string     `json:"user_agent"`
+	IPAddress      string     `json:"ip_address"`
+	LastSeenAt     time.Time  `json:"lastSeenAt"`
+	RevokedAt      *time.Time `json:"revokedAt,omitempty"`
+	CreatedAt      time.Time  `json:"createdAt"`
+}
@@ -1,12 +1,54 @@
 package models
 
-import "time"
+import (
+	"strings"
+	"time"
+)
+
+// user roles
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+)
 
 type User struct {
+	Id                    int64      `json:"id"`
+	Name                  string     `json:"name"`
+	Email                 string     `json:"email"`
+	Bio                   string     `json:"bio"`
+	AvatarURL             string     `json:"avatar_url"`
+	Password              string     `json:"-"`
+	Role                  string     `json:"role"`
+	SuspendedAt           *time.Time `json:"suspendedAt,omitempty"`
+	PasswordResetRequired bool       `json:"password_reset_required"`
+	CreatedAt             time.Time  `json:"createdAt"`
+	UpdatedAt             time.Time  `json:"updatedAt"`
+}
+
+// UserProfile is the public view of a user; it never includes the email.
+type UserProfile struct {
 	Id        int64     `json:"id"`
 	Name      string    `json:"name"`
-	Email     string    `json:"email"`
-	Password  string    `json:"-"`
-	CreatedAt time.Time `json:"createdAt"`
-	UpdatedAt time.Time `json:"updatedAt"`
+	Bio       string    `json:"bio"`
+	AvatarURL string    `json:"avatar_url"`
+	PostCount int64     `json:"post_count"`
+	JoinedAt  time.Time `json:"joinedAt"`
+}
+
+// Profile returns the public view of the user.
+func (u *User) Profile(postCount int64) *UserProfile {
+	return &UserProfile{
+		Id:        u.Id,
+		Name:      u.Name,
+		Bio:       u.Bio,
+		AvatarURL: u.AvatarURL,
+		PostCount: postCount,
+		JoinedAt:  u.CreatedAt,
+	}
+}
+
+// NormalizeEmail returns the form emails are stored and looked up in, so
+// addresses differing only in case or surrounding space are the same user.
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
 }
@@ -0,0 +1,48 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// webhook delivery statuses
+const (
+	DeliveryPending   = "pending"
+	DeliverySucceeded = "succeeded"
+	DeliveryFailed    = "failed"
+)
+
+// Webhook is an endpoint a user has subscribed to events.
+type Webhook struct {
+	Id           int64      `json:"id"`
+	UserId       int64      `json:"user_id"`
+	URL          string     `json:"url"`
+	Secret       string     `json:"-"`
+	Events       []string   `json:"events"`
+	Active       bool       `json:"active"`
+	FailureCount int        `json:"failure_count"`
+	DisabledAt   *time.Time `json:"disabledAt"`
+	CreatedAt    time.Time  `json:"createdAt"`
+	UpdatedAt    *time.Time `json:"updatedAt"`
+}
+
+// WebhookDelivery is an event sent, or to be sent, to a webhook, along with
+// the outcome of its latest attempt.
+type WebhookDelivery struct {
+	Id            int64           `json:"id"`
+	WebhookId     int64           `json:"webhook_id"`
+	Event         string          `json:"event"`
+	Payload       json.RawMessage `json:"payload"`
+	Status        string          `json:"status"`
+	Attempts      int             `json:"attempts"`
+	ResponseCode  *int            `json:"response_code"`
+	Error         string          `json:"error,omitempty"`
+	DurationMs    *int64          `json:"duration_ms"`
+	NextAttemptAt *time.Time      `json:"nextAttemptAt"`
+	DeliveredAt   *time.Time      `json:"deliveredAt"`
+	CreatedAt     time.Time       `json:"createdAt"`
+
+	// the endpoint, filled in for sending
+	URL    string `json:"-"`
+	Secret string `json:"-"`
+}
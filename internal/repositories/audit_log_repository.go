@@ -0,0 +1,144 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/achintha-dilshan/go-rest-api/internal/models"
+)
+
+// AuditLogFilter narrows down audit log queries; zero values are ignored.
+type AuditLogFilter struct {
+	ActorId    *int64
+	Action     string
+	TargetType string
+	TargetId   *int64
+	RequestId  string
+	From       *time.Time
+	To         *time.Time
+}
+
+type auditLogRepository struct {
+	db *sql.DB
+}
+
+type AuditLogRepository interface {
+	Create(ctx context.Context, entry *models.AuditLog) (int64, error)
+	Find(ctx context.Context, filter AuditLogFilter, limit, offset int) ([]*models.AuditLog, int64, error)
+}
+
+func NewAuditLogRepository(db *sql.DB) AuditLogRepository {
+	return &auditLogRepository{db: db}
+}
+
+const auditLogColumns = "id, actor_id, impersonator_id, action, target_type, target_id, ip_address, request_id, metadata, before_data, after_data, created_at"
+
+// appends an entry to the audit log; entries are never updated or deleted
+func (r *auditLogRepository) Create(ctx context.Context, entry *models.AuditLog) (int64, error) {
+	query := "INSERT INTO audit_logs (actor_id, impersonator_id, action, target_type, target_id, ip_address, request_id, metadata, before_data, after_data) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)"
+	return insert(ctx, r.db, query, entry.ActorId, entry.ImpersonatorId, entry.Action, entry.TargetType, entry.TargetId, entry.IPAddress, entry.RequestId, nullJSON(entry.Metadata), nullJSON(entry.Before), nullJSON(entry.After))
+}
+
+// retrieves audit log entries matching the filter, newest first, with the total number of matches
+func (r *auditLogRepository) Find(ctx context.Context, filter AuditLogFilter, limit, offset int) ([]*models.AuditLog, int64, error) {
+	var entries []*models.AuditLog
+	var total int64
+
+	var conditions []string
+	var args []any
+	if filter.ActorId != nil {
+		conditions = append(conditions, "actor_id = ?")
+		args = append(args, *filter.ActorId)
+	}
+	if filter.Action != "" {
+		conditions = append(conditions, "action = ?")
+		args = append(args, filter.Action)
+	}
+	if filter.TargetType != "" {
+		conditions = append(conditions, "target_type = ?")
+		args = append(args, filter.TargetType)
+	}
+	if filter.TargetId != nil {
+		conditions = append(conditions, "target_id = ?")
+		args = append(args, *filter.TargetId)
+	}
+	if filter.RequestId != "" {
+		conditions = append(conditions, "request_id = ?")
+		args = append(args, filter.RequestId)
+	}
+	if filter.From != nil {
+		conditions = append(conditions, "created_at >= ?")
+		args = append(args, filter.From.UTC())
+	}
+	if filter.To != nil {
+		conditions = append(conditions, "created_at < ?")
+		args = append(args, filter.To.UTC())
+	}
+
+	where := ""
+	if len(conditions) > 0 {
+		where = " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	if err := conn(ctx, r.db).QueryRowContext(ctx, "SELECT COUNT(*) FROM audit_logs"+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query := "SELECT " + auditLogColumns + " FROM audit_logs" + where + " ORDER BY id DESC LIMIT ? OFFSET ?"
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, append(args, limit, offset)...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		entry, err := scanAuditLog(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		entries = append(entries, entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return entries, total, nil
+}
+
+func scanAuditLog(row rowScanner) (*models.AuditLog, error) {
+	var entry models.AuditLog
+	var actorId, impersonatorId, targetId sql.NullInt64
+	var metadata, before, after []byte
+
+	err := row.Scan(&entry.Id, &actorId, &impersonatorId, &entry.Action, &entry.TargetType, &targetId, &entry.IPAddress, &entry.RequestId, &metadata, &before, &after, &entry.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if actorId.Valid {
+		entry.ActorId = &actorId.Int64
+	}
+	if impersonatorId.Valid {
+		entry.ImpersonatorId = &impersonatorId.Int64
+	}
+	if targetId.Valid {
+		entry.TargetId = &targetId.Int64
+	}
+	entry.Metadata = metadata
+	entry.Before = before
+	entry.After = after
+
+	return &entry, nil
+}
+
+// nullJSON stores empty JSON values as NULL
+func nullJSON(value []byte) any {
+	if len(value) == 0 {
+		return nil
+	}
+	return string(value)
+}
@@ -0,0 +1,47 @@
+package repositories
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/achintha-dilshan/go-rest-api/internal/models"
+)
+
+func TestAuditLogRepository(t *testing.T) {
+	db := newTestDB(t)
+	repo := NewAuditLogRepository(db)
+	ctx := context.Background()
+
+	actorId := createTestUser(t, db)
+	targetId := int64(42)
+
+	_, err := repo.Create(ctx, &models.AuditLog{
+		ActorId: &actorId, Action: "user.suspended", TargetType: "user", TargetId: &targetId,
+		RequestId: "req-1", Metadata: json.RawMessage(`{"reason":"spam"}`),
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got '%v'", err)
+	}
+	repo.Create(ctx, &models.AuditLog{Action: "post.deleted", TargetType: "post"})
+
+	entries, total, err := repo.Find(ctx, AuditLogFilter{ActorId: &actorId}, 10, 0)
+	if err != nil || total != 1 || len(entries) != 1 {
+		t.Fatalf("Expected 1 entry, got %d of %d and error '%v'", len(entries), total, err)
+	}
+	if got := entries[0]; got.Action != "user.suspended" || *got.TargetId != targetId || string(got.Metadata) != `{"reason":"spam"}` || got.Before != nil {
+		t.Errorf("Expected the saved entry, got '%+v'", got)
+	}
+
+	from := time.Now().Add(-time.Hour)
+	if _, total, _ := repo.Find(ctx, AuditLogFilter{From: &from}, 10, 0); total != 2 {
+		t.Errorf("Expected '%d', got '%d'", 2, total)
+	}
+	if _, total, _ := repo.Find(ctx, AuditLogFilter{To: &from}, 10, 0); total != 0 {
+		t.Errorf("Expected '%d', got '%d'", 0, total)
+	}
+	if _, total, _ := repo.Find(ctx, AuditLogFilter{RequestId: "req-1", TargetType: "user"}, 10, 0); total != 1 {
+		t.Errorf("Expected '%d', got '%d'", 1, total)
+	}
+}
@@ -0,0 +1,186 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/jackc/pgx/v5/stdlib"
+	"modernc.org/sqlite"
+)
+
+// Dialect is the flavour of SQL a database speaks. Queries are written for
+// MySQL, with ? placeholders, and adjusted for the others as they run.
+type Dialect string
+
+const (
+	MySQL    Dialect = "mysql"
+	Postgres Dialect = "postgres"
+	SQLite   Dialect = "sqlite"
+)
+
+// dialectOf tells the dialect of a database from its driver.
+func dialectOf(db *sql.DB) Dialect {
+	switch db.Driver().(type) {
+	case *stdlib.Driver:
+		return Postgres
+	case *sqlite.Driver:
+		return SQLite
+	case *mysql.MySQLDriver:
+		return MySQL
+	}
+	return MySQL
+}
+
+// rebind rewrites the ? placeholders of a query into the $1, $2, ... that
+// PostgreSQL expects. Question marks in string literals are left alone.
+func (d Dialect) rebind(query string) string {
+	if d != Postgres || !strings.Contains(query, "?") {
+		return query
+	}
+
+	var b strings.Builder
+	b.Grow(len(query) + 8)
+
+	n, quoted := 0, false
+	for _, c := range query {
+		switch {
+		case c == '\'':
+			quoted = !quoted
+		case c == '?' && !quoted:
+			n++
+			b.WriteByte('$')
+			b.WriteString(strconv.Itoa(n))
+			continue
+		}
+		b.WriteRune(c)
+	}
+
+	return b.String()
+}
+
+// sqliteTime is the layout of CURRENT_TIMESTAMP in SQLite.
+const sqliteTime = "2006-01-02 15:04:05"
+
+// bind adjusts the arguments of a query. SQLite keeps times as text, so they
+// are written the way CURRENT_TIMESTAMP writes them and compare as times.
+func (d Dialect) bind(args []any) []any {
+	if d != SQLite {
+		return args
+	}
+
+	args = slices.Clone(args)
+	for i, arg := range args {
+		switch t := arg.(type) {
+		case time.Time:
+			args[i] = t.UTC().Format(sqliteTime)
+		case *time.Time:
+			if t != nil {
+				args[i] = t.UTC().Format(sqliteTime)
+			}
+		}
+	}
+
+	return args
+}
+
+// forUpdate returns the clause locking the rows a query reads until the
+// transaction ends. SQLite locks the whole database for writing instead.
+func (d Dialect) forUpdate() string {
+	if d == SQLite {
+		return ""
+	}
+	return " FOR UPDATE"
+}
+
+// skipLocked returns the clause locking the rows a query reads, of table
+// when it is not empty, and skipping rows another transaction has locked, so
+// workers can claim rows without waiting on each other.
+func (d Dialect) skipLocked(table string) string {
+	if d == SQLite {
+		return ""
+	}
+	if table != "" {
+		return " FOR UPDATE OF " + table + " SKIP LOCKED"
+	}
+	return " FOR UPDATE SKIP LOCKED"
+}
+
+// upsert returns the clause turning an INSERT into an update of columns when
+// a row with the same key exists.
+func (d Dialect) upsert(key []string, columns ...string) string {
+	set := make([]string, len(columns))
+	if d == MySQL {
+		for i, column := range columns {
+			set[i] = column + " = VALUES(" + column + ")"
+		}
+		return " ON DUPLICATE KEY UPDATE " + strings.Join(set, ", ")
+	}
+
+	for i, column := range columns {
+		set[i] = column + " = excluded." + column
+	}
+	return " ON CONFLICT (" + strings.Join(key, ", ") + ") DO UPDATE SET " + strings.Join(set, ", ")
+}
+
+// like returns the case-insensitive LIKE operator.
+func (d Dialect) like() string {
+	if d == Postgres {
+		return "ILIKE"
+	}
+	return "LIKE"
+}
+
+// querier runs statements, either on the database or in a transaction.
+type querier interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// dialectQuerier adjusts queries to the dialect before running them.
+type dialectQuerier struct {
+	q       querier
+	dialect Dialect
+}
+
+func (q dialectQuerier) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	return q.q.ExecContext(ctx, q.dialect.rebind(query), q.dialect.bind(args)...)
+}
+
+func (q dialectQuerier) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	return q.q.QueryContext(ctx, q.dialect.rebind(query), q.dialect.bind(args)...)
+}
+
+func (q dialectQuerier) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	return q.q.QueryRowContext(ctx, q.dialect.rebind(query), q.dialect.bind(args)...)
+}
+
+// insert runs an INSERT and returns the id of the new row, or sql.ErrNoRows
+// when an INSERT ... SELECT inserted nothing. PostgreSQL does not report the
+// id the way the others do, so it is asked to return it.
+func insert(ctx context.Context, db *sql.DB, query string, args ...any) (int64, error) {
+	if dialectOf(db) == Postgres {
+		var id int64
+		err := conn(ctx, db).QueryRowContext(ctx, query+" RETURNING id", args...).Scan(&id)
+		return id, err
+	}
+
+	result, err := conn(ctx, db).ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+
+	if inserted, err := result.RowsAffected(); err != nil || inserted == 0 {
+		if err == nil {
+			err = sql.ErrNoRows
+		}
+		return 0, err
+	}
+
+	return result.LastInsertId()
+}
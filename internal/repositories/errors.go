@@ -0,0 +1,49 @@
+package repositories
+
+import (
+	"errors"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/jackc/pgx/v5/pgconn"
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// ErrDuplicate is returned when a write conflicts with a unique key.
+var ErrDuplicate = errors.New("duplicate key")
+
+// isDuplicateKey reports whether err is a unique key violation.
+func isDuplicateKey(err error) bool {
+	var mysqlErr *mysql.MySQLError
+	var pgErr *pgconn.PgError
+	var sqliteErr *sqlite.Error
+
+	switch {
+	case errors.As(err, &mysqlErr):
+		return mysqlErr.Number == 1062
+	case errors.As(err, &pgErr):
+		return pgErr.Code == "23505"
+	case errors.As(err, &sqliteErr):
+		return sqliteErr.Code() == sqlite3.SQLITE_CONSTRAINT_UNIQUE || sqliteErr.Code() == sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY
+	}
+	return false
+}
+
+// isDeadlock reports whether err is the database giving up on a transaction
+// to break a deadlock, or after waiting too long for a lock; both succeed on
+// retry.
+func isDeadlock(err error) bool {
+	var mysqlErr *mysql.MySQLError
+	var pgErr *pgconn.PgError
+	var sqliteErr *sqlite.Error
+
+	switch {
+	case errors.As(err, &mysqlErr):
+		return mysqlErr.Number == 1213 || mysqlErr.Number == 1205
+	case errors.As(err, &pgErr):
+		return pgErr.Code == "40P01" || pgErr.Code == "40001" || pgErr.Code == "55P03"
+	case errors.As(err, &sqliteErr):
+		return sqliteErr.Code()&0xff == sqlite3.SQLITE_BUSY || sqliteErr.Code()&0xff == sqlite3.SQLITE_LOCKED
+	}
+	return false
+}
@@ -0,0 +1,171 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	"github.com/achintha-dilshan/go-rest-api/internal/models"
+)
+
+type followRepository struct {
+	db *sql.DB
+}
+
+type FollowRepository interface {
+	Follow(ctx context.Context, followerId, followeeId int64) (bool, error)
+	Unfollow(ctx context.Context, followerId, followeeId int64) error
+	IsFollowing(ctx context.Context, followerId, followeeId int64) (bool, error)
+	FindFollowers(ctx context.Context, userId int64, limit, offset int) ([]*models.FollowUser, int64, error)
+	FindFollowing(ctx context.Context, userId int64, limit, offset int) ([]*models.FollowUser, int64, error)
+	FindFolloweeIds(ctx context.Context, followerId int64, limit int) ([]int64, error)
+	FindConnectedIds(ctx context.Context, userId int64, ids []int64) ([]int64, error)
+}
+
+func NewFollowRepository(db *sql.DB) FollowRepository {
+	return &followRepository{db: db}
+}
+
+// records a follow, reporting whether it is new; following twice is not an error
+func (r *followRepository) Follow(ctx context.Context, followerId, followeeId int64) (bool, error) {
+	query := "INSERT INTO follows (follower_id, followee_id) VALUES (?, ?)"
+	err := savepoint(ctx, r.db, func() error {
+		_, err := conn(ctx, r.db).ExecContext(ctx, query, followerId, followeeId)
+		return err
+	})
+
+	if isDuplicateKey(err) {
+		return false, nil
+	}
+
+	return err == nil, err
+}
+
+// removes a follow
+func (r *followRepository) Unfollow(ctx context.Context, followerId, followeeId int64) error {
+	query := "DELETE FROM follows WHERE follower_id = ? AND followee_id = ?"
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, followerId, followeeId)
+
+	return err
+}
+
+// checks whether a user follows another
+func (r *followRepository) IsFollowing(ctx context.Context, followerId, followeeId int64) (bool, error) {
+	var following bool
+	query := "SELECT EXISTS(SELECT 1 FROM follows WHERE follower_id = ? AND followee_id = ?)"
+	err := conn(ctx, r.db).QueryRowContext(ctx, query, followerId, followeeId).Scan(&following)
+
+	return following, err
+}
+
+// retrieves the users following a user, most recent first
+func (r *followRepository) FindFollowers(ctx context.Context, userId int64, limit, offset int) ([]*models.FollowUser, int64, error) {
+	return r.findUsers(ctx, "f.followee_id", "f.follower_id", userId, limit, offset)
+}
+
+// retrieves the users a user follows, most recent first
+func (r *followRepository) FindFollowing(ctx context.Context, userId int64, limit, offset int) ([]*models.FollowUser, int64, error) {
+	return r.findUsers(ctx, "f.follower_id", "f.followee_id", userId, limit, offset)
+}
+
+// retrieves up to limit IDs of the users a user follows
+func (r *followRepository) FindFolloweeIds(ctx context.Context, followerId int64, limit int) ([]int64, error) {
+	var ids []int64
+	query := "SELECT followee_id FROM follows WHERE follower_id = ? LIMIT ?"
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, followerId, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+
+		ids = append(ids, id)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+// retrieves those of ids that the user follows or is followed by
+func (r *followRepository) FindConnectedIds(ctx context.Context, userId int64, ids []int64) ([]int64, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(ids)), ", ")
+	query := "SELECT followee_id FROM follows WHERE follower_id = ? AND followee_id IN (" + placeholders + ")" +
+		" UNION SELECT follower_id FROM follows WHERE followee_id = ? AND follower_id IN (" + placeholders + ")"
+
+	// the user and the ids, once for each direction
+	args := make([]any, 0, 2*len(ids)+2)
+	for i := 0; i < 2; i++ {
+		args = append(args, userId)
+		for _, id := range ids {
+			args = append(args, id)
+		}
+	}
+
+	var connected []int64
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+
+		connected = append(connected, id)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return connected, nil
+}
+
+// findUsers lists the users in column other of the follows matching column match
+func (r *followRepository) findUsers(ctx context.Context, match, other string, userId int64, limit, offset int) ([]*models.FollowUser, int64, error) {
+	var users []*models.FollowUser
+	var total int64
+
+	if err := conn(ctx, r.db).QueryRowContext(ctx, "SELECT COUNT(*) FROM follows f WHERE "+match+" = ?", userId).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query := "SELECT u.id, u.name, u.avatar_url, f.created_at FROM follows f JOIN users u ON u.id = " + other +
+		" WHERE " + match + " = ? ORDER BY f.created_at DESC, u.id LIMIT ? OFFSET ?"
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, userId, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var user models.FollowUser
+		if err := rows.Scan(&user.Id, &user.Name, &user.AvatarURL, &user.FollowedAt); err != nil {
+			return nil, 0, err
+		}
+
+		users = append(users, &user)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return users, total, nil
+}
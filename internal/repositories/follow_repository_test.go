@@ -0,0 +1,77 @@
+package repositories
+
+import (
+	"context"
+	"slices"
+	"testing"
+)
+
+func TestFollowRepository(t *testing.T) {
+	db := newTestDB(t)
+	repo := NewFollowRepository(db)
+	ctx := context.Background()
+
+	followerId := createTestUser(t, db)
+	followeeId := createTestUser(t, db)
+
+	if followed, err := repo.Follow(ctx, followerId, followeeId); err != nil || !followed {
+		t.Fatalf("Expected the follow to be added, got '%v' and error '%v'", followed, err)
+	}
+	if followed, err := repo.Follow(ctx, followerId, followeeId); err != nil || followed {
+		t.Errorf("Expected a repeated follow to be ignored, got '%v' and error '%v'", followed, err)
+	}
+	if _, err := repo.Follow(ctx, followerId, followerId); err == nil {
+		t.Errorf("Expected following oneself to fail")
+	}
+
+	if following, _ := repo.IsFollowing(ctx, followerId, followeeId); !following {
+		t.Errorf("Expected the user to be following")
+	}
+	if following, _ := repo.IsFollowing(ctx, followeeId, followerId); following {
+		t.Errorf("Expected the follow to go one way")
+	}
+
+	followers, total, err := repo.FindFollowers(ctx, followeeId, 10, 0)
+	if err != nil || total != 1 || len(followers) != 1 || followers[0].Id != followerId {
+		t.Errorf("Expected follower %d, got '%v' of %d and error '%v'", followerId, followers, total, err)
+	}
+
+	following, total, err := repo.FindFollowing(ctx, followerId, 10, 0)
+	if err != nil || total != 1 || len(following) != 1 || following[0].Id != followeeId {
+		t.Errorf("Expected followee %d, got '%v' of %d and error '%v'", followeeId, following, total, err)
+	}
+
+	if ids, _ := repo.FindFolloweeIds(ctx, followerId, 10); len(ids) != 1 || ids[0] != followeeId {
+		t.Errorf("Expected '%v', got '%v'", []int64{followeeId}, ids)
+	}
+
+	if err := repo.Unfollow(ctx, followerId, followeeId); err != nil {
+		t.Fatalf("Expected no error, got '%v'", err)
+	}
+	if following, _ := repo.IsFollowing(ctx, followerId, followeeId); following {
+		t.Errorf("Expected the user not to be following")
+	}
+}
+
+func TestFollowRepositoryFindConnectedIds(t *testing.T) {
+	db := newTestDB(t)
+	repo := NewFollowRepository(db)
+	ctx := context.Background()
+
+	userId := createTestUser(t, db)
+	followeeId := createTestUser(t, db)
+	followerId := createTestUser(t, db)
+	strangerId := createTestUser(t, db)
+
+	repo.Follow(ctx, userId, followeeId)
+	repo.Follow(ctx, followerId, userId)
+	repo.Follow(ctx, strangerId, followeeId)
+
+	ids, err := repo.FindConnectedIds(ctx, userId, []int64{followeeId, followerId, strangerId})
+	if err != nil {
+		t.Fatalf("Expected no error, got '%v'", err)
+	}
+	if len(ids) != 2 || !slices.Contains(ids, followeeId) || !slices.Contains(ids, followerId) {
+		t.Errorf("Expected '%v', got '%v'", []int64{followeeId, followerId}, ids)
+	}
+}
@@ -0,0 +1,239 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/achintha-dilshan/go-rest-api/internal/models"
+)
+
+// JobFilter narrows down job queries; zero values are ignored.
+type JobFilter struct {
+	Queue  string
+	Type   string
+	Status string
+}
+
+type jobRepository struct {
+	db *sql.DB
+}
+
+type JobRepository interface {
+	Create(ctx context.Context, job *models.Job) (int64, error)
+	FindById(ctx context.Context, id int64) (*models.Job, error)
+	Find(ctx context.Context, filter JobFilter, limit, offset int) ([]*models.Job, int64, error)
+	Claim(ctx context.Context, queue string, lease time.Duration) (*models.Job, error)
+	Complete(ctx context.Context, id int64) error
+	Reschedule(ctx context.Context, id int64, lastError string, retryIn time.Duration) error
+	Bury(ctx context.Context, id int64, lastError string) error
+	Requeue(ctx context.Context, id int64) (bool, error)
+	Prune(ctx context.Context, olderThan time.Duration) (int64, error)
+}
+
+func NewJobRepository(db *sql.DB) JobRepository {
+	return &jobRepository{db: db}
+}
+
+const jobColumns = "id, queue, type, payload, unique_key, status, attempts, max_attempts, last_error, run_at, locked_until, finished_at, created_at, updated_at"
+
+// inserts a new job, due at its RunAt or right away when that is zero. A
+// job whose unique key is taken is not inserted and ErrDuplicate is returned.
+func (r *jobRepository) Create(ctx context.Context, job *models.Job) (int64, error) {
+	var uniqueKey any
+	if job.UniqueKey != "" {
+		uniqueKey = job.UniqueKey
+	}
+	runAt := time.Now().UTC()
+	if !job.RunAt.IsZero() {
+		runAt = job.RunAt.UTC()
+	}
+
+	query := "INSERT INTO jobs (queue, type, payload, unique_key, max_attempts, run_at) VALUES (?, ?, ?, ?, ?, ?)"
+	var id int64
+	err := savepoint(ctx, r.db, func() error {
+		var err error
+		id, err = insert(ctx, r.db, query, job.Queue, job.Type, nullJSON(job.Payload), uniqueKey, job.MaxAttempts, runAt)
+		return err
+	})
+
+	if isDuplicateKey(err) {
+		return 0, ErrDuplicate
+	}
+
+	return id, err
+}
+
+// retrieves a job by ID
+func (r *jobRepository) FindById(ctx context.Context, id int64) (*models.Job, error) {
+	query := "SELECT " + jobColumns + " FROM jobs WHERE id = ?"
+	job, err := scanJob(conn(ctx, r.db).QueryRowContext(ctx, query, id))
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+
+	return job, err
+}
+
+// retrieves jobs matching the filter, newest first, with the total number of matches
+func (r *jobRepository) Find(ctx context.Context, filter JobFilter, limit, offset int) ([]*models.Job, int64, error) {
+	var jobs []*models.Job
+	var total int64
+
+	var conditions []string
+	var args []any
+	if filter.Queue != "" {
+		conditions = append(conditions, "queue = ?")
+		args = append(args, filter.Queue)
+	}
+	if filter.Type != "" {
+		conditions = append(conditions, "type = ?")
+		args = append(args, filter.Type)
+	}
+	if filter.Status != "" {
+		conditions = append(conditions, "status = ?")
+		args = append(args, filter.Status)
+	}
+
+	where := ""
+	if len(conditions) > 0 {
+		where = " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	if err := conn(ctx, r.db).QueryRowContext(ctx, "SELECT COUNT(*) FROM jobs"+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query := "SELECT " + jobColumns + " FROM jobs" + where + " ORDER BY id DESC LIMIT ? OFFSET ?"
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, append(args, limit, offset)...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		job, err := scanJob(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		jobs = append(jobs, job)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return jobs, total, nil
+}
+
+// claims the next due job of a queue, counting an attempt and locking it for
+// the length of the lease. A running job whose lease has run out belongs to
+// a worker that died, and is claimed again. Returns nil when nothing is due.
+func (r *jobRepository) Claim(ctx context.Context, queue string, lease time.Duration) (*models.Job, error) {
+	var job *models.Job
+
+	err := NewTxManager(r.db).WithinTx(ctx, func(ctx context.Context) error {
+		now := time.Now().UTC()
+
+		var id int64
+		query := "SELECT id FROM jobs WHERE queue = ? AND (status = ? AND run_at <= ? OR status = ? AND locked_until < ?) " +
+			"ORDER BY run_at, id LIMIT 1" + dialectOf(r.db).skipLocked("")
+		err := conn(ctx, r.db).QueryRowContext(ctx, query, queue, models.JobPending, now, models.JobRunning, now).Scan(&id)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		query = "UPDATE jobs SET status = ?, attempts = attempts + 1, locked_until = ? WHERE id = ?"
+		if _, err := conn(ctx, r.db).ExecContext(ctx, query, models.JobRunning, now.Add(lease), id); err != nil {
+			return err
+		}
+
+		job, err = scanJob(conn(ctx, r.db).QueryRowContext(ctx, "SELECT "+jobColumns+" FROM jobs WHERE id = ?", id))
+		return err
+	})
+
+	return job, err
+}
+
+// marks a job done
+func (r *jobRepository) Complete(ctx context.Context, id int64) error {
+	query := "UPDATE jobs SET status = ?, last_error = '', locked_until = NULL, finished_at = CURRENT_TIMESTAMP WHERE id = ?"
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, models.JobSucceeded, id)
+
+	return err
+}
+
+// puts a failed job back in its queue to run again after retryIn
+func (r *jobRepository) Reschedule(ctx context.Context, id int64, lastError string, retryIn time.Duration) error {
+	query := "UPDATE jobs SET status = ?, last_error = ?, locked_until = NULL, run_at = ? WHERE id = ?"
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, models.JobPending, lastError, time.Now().UTC().Add(retryIn), id)
+
+	return err
+}
+
+// dead-letters a job that will not be retried
+func (r *jobRepository) Bury(ctx context.Context, id int64, lastError string) error {
+	query := "UPDATE jobs SET status = ?, last_error = ?, locked_until = NULL, finished_at = CURRENT_TIMESTAMP WHERE id = ?"
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, models.JobDead, lastError, id)
+
+	return err
+}
+
+// gives a dead job a fresh set of attempts, reporting whether it was dead
+func (r *jobRepository) Requeue(ctx context.Context, id int64) (bool, error) {
+	query := "UPDATE jobs SET status = ?, attempts = 0, run_at = ?, finished_at = NULL WHERE id = ? AND status = ?"
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, models.JobPending, time.Now().UTC(), id, models.JobDead)
+	if err != nil {
+		return false, err
+	}
+
+	requeued, err := result.RowsAffected()
+	return requeued > 0, err
+}
+
+// deletes jobs that succeeded longer ago than olderThan, returning how many were
+// deleted; dead jobs are kept for inspection
+func (r *jobRepository) Prune(ctx context.Context, olderThan time.Duration) (int64, error) {
+	query := "DELETE FROM jobs WHERE status = ? AND finished_at < ?"
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, models.JobSucceeded, time.Now().UTC().Add(-olderThan))
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+// scanJob reads a single job row
+func scanJob(row rowScanner) (*models.Job, error) {
+	var job models.Job
+	var payload []byte
+	var uniqueKey sql.NullString
+	var lockedUntil, finishedAt, updatedAt sql.NullTime
+
+	err := row.Scan(&job.Id, &job.Queue, &job.Type, &payload, &uniqueKey, &job.Status, &job.Attempts, &job.MaxAttempts,
+		&job.LastError, &job.RunAt, &lockedUntil, &finishedAt, &job.CreatedAt, &updatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	job.Payload = payload
+	job.UniqueKey = uniqueKey.String
+	if lockedUntil.Valid {
+		job.LockedUntil = &lockedUntil.Time
+	}
+	if finishedAt.Valid {
+		job.FinishedAt = &finishedAt.Time
+	}
+	if updatedAt.Valid {
+		job.UpdatedAt = &updatedAt.Time
+	}
+
+	return &job, nil
+}
@@ -0,0 +1,144 @@
+package repositories
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/achintha-dilshan/go-rest-api/internal/models"
+)
+
+func TestJobRepositoryCreate(t *testing.T) {
+	db := newTestDB(t)
+	repo := NewJobRepository(db)
+	ctx := context.Background()
+
+	job := &models.Job{Queue: "default", Type: "cleanup", Payload: json.RawMessage(`{"days":7}`), UniqueKey: "cleanup:1", MaxAttempts: 3}
+	id, err := repo.Create(ctx, job)
+	if err != nil {
+		t.Fatalf("Expected no error, got '%v'", err)
+	}
+
+	if _, err := repo.Create(ctx, job); !errors.Is(err, ErrDuplicate) {
+		t.Errorf("Expected '%v', got '%v'", ErrDuplicate, err)
+	}
+
+	saved, err := repo.FindById(ctx, id)
+	if err != nil || saved == nil {
+		t.Fatalf("Expected the job, got '%v' and error '%v'", saved, err)
+	}
+	if saved.Status != models.JobPending || saved.UniqueKey != "cleanup:1" || string(saved.Payload) != `{"days":7}` {
+		t.Errorf("Expected the saved job, got '%+v'", saved)
+	}
+
+	jobs, total, err := repo.Find(ctx, JobFilter{Status: models.JobPending}, 10, 0)
+	if err != nil || total != 1 || len(jobs) != 1 {
+		t.Errorf("Expected 1 pending job, got %d of %d and error '%v'", len(jobs), total, err)
+	}
+}
+
+func TestJobRepositoryClaim(t *testing.T) {
+	db := newTestDB(t)
+	repo := NewJobRepository(db)
+	ctx := context.Background()
+
+	later, _ := repo.Create(ctx, &models.Job{Queue: "default", Type: "later", MaxAttempts: 3, RunAt: time.Now().Add(time.Hour)})
+	due, _ := repo.Create(ctx, &models.Job{Queue: "default", Type: "due", MaxAttempts: 3})
+	repo.Create(ctx, &models.Job{Queue: "mail", Type: "mail", MaxAttempts: 3})
+
+	job, err := repo.Claim(ctx, "default", time.Minute)
+	if err != nil || job == nil {
+		t.Fatalf("Expected a job, got '%v' and error '%v'", job, err)
+	}
+	if job.Id != due || job.Status != models.JobRunning || job.Attempts != 1 || job.LockedUntil == nil {
+		t.Errorf("Expected job %d running its first attempt, got '%+v'", due, job)
+	}
+
+	if job, err := repo.Claim(ctx, "default", time.Minute); err != nil || job != nil {
+		t.Errorf("Expected nothing due, got '%v' and error '%v'", job, err)
+	}
+
+	// a lease that ran out belongs to a worker that died
+	if _, err := repo.Claim(ctx, "mail", -time.Minute); err != nil {
+		t.Fatalf("Expected no error, got '%v'", err)
+	}
+	if job, _ := repo.Claim(ctx, "mail", time.Minute); job == nil || job.Attempts != 2 {
+		t.Errorf("Expected the expired job to be claimed again, got '%+v'", job)
+	}
+
+	if err := repo.Reschedule(ctx, due, "failed", -time.Second); err != nil {
+		t.Fatalf("Expected no error, got '%v'", err)
+	}
+	if job, _ := repo.Claim(ctx, "default", time.Minute); job == nil || job.Id != due || job.LastError != "failed" {
+		t.Errorf("Expected the rescheduled job, got '%+v'", job)
+	}
+
+	if err := repo.Complete(ctx, due); err != nil {
+		t.Fatalf("Expected no error, got '%v'", err)
+	}
+	if job, _ := repo.FindById(ctx, due); job.Status != models.JobSucceeded || job.FinishedAt == nil {
+		t.Errorf("Expected the job to have succeeded, got '%+v'", job)
+	}
+	if job, _ := repo.FindById(ctx, later); job.Status != models.JobPending {
+		t.Errorf("Expected the later job to be left alone, got '%+v'", job)
+	}
+}
+
+func TestJobRepositoryRequeueAndPrune(t *testing.T) {
+	db := newTestDB(t)
+	repo := NewJobRepository(db)
+	ctx := context.Background()
+
+	dead, _ := repo.Create(ctx, &models.Job{Queue: "default", Type: "dead", MaxAttempts: 1})
+	done, _ := repo.Create(ctx, &models.Job{Queue: "default", Type: "done", MaxAttempts: 1})
+
+	if requeued, _ := repo.Requeue(ctx, dead); requeued {
+		t.Errorf("Expected a pending job not to be requeued")
+	}
+
+	repo.Bury(ctx, dead, "gave up")
+	if requeued, err := repo.Requeue(ctx, dead); err != nil || !requeued {
+		t.Errorf("Expected the dead job to be requeued, got '%v' and error '%v'", requeued, err)
+	}
+	if job, _ := repo.FindById(ctx, dead); job.Status != models.JobPending || job.Attempts != 0 {
+		t.Errorf("Expected a fresh pending job, got '%+v'", job)
+	}
+
+	repo.Complete(ctx, done)
+	if pruned, _ := repo.Prune(ctx, time.Hour); pruned != 0 {
+		t.Errorf("Expected '%d', got '%d'", 0, pruned)
+	}
+	if pruned, err := repo.Prune(ctx, -time.Minute); err != nil || pruned != 1 {
+		t.Errorf("Expected 1 job pruned, got %d and error '%v'", pruned, err)
+	}
+}
+
+func TestOutboxRepository(t *testing.T) {
+	db := newTestDB(t)
+	repo := NewOutboxRepository(db)
+	ctx := context.Background()
+
+	repo.Add(ctx, "post.created", []byte(`{"id":1}`))
+	repo.Add(ctx, "post.deleted", []byte(`{"id":1}`))
+
+	events, err := repo.ClaimUnpublished(ctx, 10)
+	if err != nil || len(events) != 2 {
+		t.Fatalf("Expected 2 events, got %d and error '%v'", len(events), err)
+	}
+	if events[0].Event != "post.created" || string(events[0].Payload) != `{"id":1}` {
+		t.Errorf("Expected the first event, got '%+v'", events[0])
+	}
+
+	if err := repo.MarkPublished(ctx, []int64{events[0].Id}); err != nil {
+		t.Fatalf("Expected no error, got '%v'", err)
+	}
+	if events, _ := repo.ClaimUnpublished(ctx, 10); len(events) != 1 || events[0].Event != "post.deleted" {
+		t.Errorf("Expected only the unpublished event, got '%v'", events)
+	}
+
+	if pruned, err := repo.Prune(ctx, -time.Minute); err != nil || pruned != 1 {
+		t.Errorf("Expected 1 event pruned, got %d and error '%v'", pruned, err)
+	}
+}
@@ -0,0 +1,63 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/achintha-dilshan/go-rest-api/internal/models"
+)
+
+type magicLinkRepository struct {
+	db *sql.DB
+}
+
+type MagicLinkRepository interface {
+	Create(ctx context.Context, link *models.MagicLink) (int64, error)
+	FindByTokenHash(ctx context.Context, tokenHash string) (*models.MagicLink, error)
+	MarkUsed(ctx context.Context, id int64) (bool, error)
+}
+
+func NewMagicLinkRepository(db *sql.DB) MagicLinkRepository {
+	return &magicLinkRepository{db: db}
+}
+
+// inserts a new magic link into the database
+func (r *magicLinkRepository) Create(ctx context.Context, link *models.MagicLink) (int64, error) {
+	query := "INSERT INTO magic_link_tokens (user_id, token_hash, expires_at) VALUES (?, ?, ?)"
+	return insert(ctx, r.db, query, link.UserId, link.TokenHash, link.ExpiresAt)
+}
+
+// retrieves a magic link by the hash of its token
+func (r *magicLinkRepository) FindByTokenHash(ctx context.Context, tokenHash string) (*models.MagicLink, error) {
+	var link models.MagicLink
+	var usedAt sql.NullTime
+	query := "SELECT id, user_id, token_hash, expires_at, used_at, created_at FROM magic_link_tokens WHERE token_hash = ?"
+	err := conn(ctx, r.db).QueryRowContext(ctx, query, tokenHash).Scan(
+		&link.Id, &link.UserId, &link.TokenHash, &link.ExpiresAt, &usedAt, &link.CreatedAt,
+	)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+
+	if usedAt.Valid {
+		link.UsedAt = &usedAt.Time
+	}
+
+	return &link, err
+}
+
+// marks an unused, unexpired magic link as used and reports whether it was
+// this call that claimed it
+func (r *magicLinkRepository) MarkUsed(ctx context.Context, id int64) (bool, error) {
+	query := "UPDATE magic_link_tokens SET used_at = CURRENT_TIMESTAMP WHERE id = ? AND used_at IS NULL AND expires_at > ?"
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, id, time.Now().UTC())
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	return affected == 1, err
+}
@@ -0,0 +1,41 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/achintha-dilshan/go-rest-api/internal/models"
+)
+
+func TestMagicLinkRepository(t *testing.T) {
+	db := newTestDB(t)
+	repo := NewMagicLinkRepository(db)
+	ctx := context.Background()
+
+	userId := createTestUser(t, db)
+
+	id, err := repo.Create(ctx, &models.MagicLink{UserId: userId, TokenHash: "valid", ExpiresAt: time.Now().Add(15 * time.Minute)})
+	if err != nil {
+		t.Fatalf("Expected no error, got '%v'", err)
+	}
+	expired, _ := repo.Create(ctx, &models.MagicLink{UserId: userId, TokenHash: "expired", ExpiresAt: time.Now().Add(-time.Minute)})
+
+	link, err := repo.FindByTokenHash(ctx, "valid")
+	if err != nil || link == nil || link.Id != id || link.UsedAt != nil {
+		t.Fatalf("Expected unused link %d, got '%+v' and error '%v'", id, link, err)
+	}
+	if link, _ := repo.FindByTokenHash(ctx, "unknown"); link != nil {
+		t.Errorf("Expected no link, got '%+v'", link)
+	}
+
+	if used, err := repo.MarkUsed(ctx, id); err != nil || !used {
+		t.Errorf("Expected the link to be used, got '%v' and error '%v'", used, err)
+	}
+	if used, _ := repo.MarkUsed(ctx, id); used {
+		t.Errorf("Expected a used link not to be used again")
+	}
+	if used, _ := repo.MarkUsed(ctx, expired); used {
+		t.Errorf("Expected an expired link not to be used")
+	}
+}
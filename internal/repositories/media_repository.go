@@ -0,0 +1,98 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/achintha-dilshan/go-rest-api/internal/models"
+)
+
+type mediaRepository struct {
+	db *sql.DB
+}
+
+type MediaRepository interface {
+	Create(ctx context.Context, media *models.Media) (int64, error)
+	FindById(ctx context.Context, id int64) (*models.Media, error)
+	FindByPostId(ctx context.Context, postId int64) ([]*models.Media, error)
+	Delete(ctx context.Context, id int64) error
+}
+
+func NewMediaRepository(db *sql.DB) MediaRepository {
+	return &mediaRepository{db: db}
+}
+
+const mediaColumns = "id, user_id, post_id, storage_key, thumbnail_key, content_type, size_bytes, width, height, created_at"
+
+// inserts a new media record into the database
+func (r *mediaRepository) Create(ctx context.Context, media *models.Media) (int64, error) {
+	query := "INSERT INTO media (user_id, post_id, storage_key, thumbnail_key, content_type, size_bytes, width, height) VALUES (?, ?, ?, ?, ?, ?, ?, ?)"
+	return insert(ctx, r.db, query, media.UserId, media.PostId, media.StorageKey, media.ThumbnailKey, media.ContentType, media.Size, media.Width, media.Height)
+}
+
+// retrieves a media record by ID
+func (r *mediaRepository) FindById(ctx context.Context, id int64) (*models.Media, error) {
+	query := "SELECT " + mediaColumns + " FROM media WHERE id = ?"
+	media, err := scanMedia(conn(ctx, r.db).QueryRowContext(ctx, query, id))
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+
+	return media, err
+}
+
+// retrieves the media attached to a post
+func (r *mediaRepository) FindByPostId(ctx context.Context, postId int64) ([]*models.Media, error) {
+	var media []*models.Media
+	query := "SELECT " + mediaColumns + " FROM media WHERE post_id = ? ORDER BY id"
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, postId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		item, err := scanMedia(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		media = append(media, item)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return media, nil
+}
+
+// deletes a media record by ID
+func (r *mediaRepository) Delete(ctx context.Context, id int64) error {
+	query := "DELETE FROM media WHERE id = ?"
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, id)
+
+	return err
+}
+
+func scanMedia(row rowScanner) (*models.Media, error) {
+	var media models.Media
+	var postId sql.NullInt64
+
+	err := row.Scan(
+		&media.Id, &media.UserId, &postId, &media.StorageKey, &media.ThumbnailKey,
+		&media.ContentType, &media.Size, &media.Width, &media.Height, &media.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if postId.Valid {
+		media.PostId = &postId.Int64
+	}
+
+	return &media, nil
+}
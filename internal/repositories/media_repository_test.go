@@ -0,0 +1,50 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+
+	"github.com/achintha-dilshan/go-rest-api/internal/models"
+)
+
+func TestMediaRepository(t *testing.T) {
+	db := newTestDB(t)
+	repo := NewMediaRepository(db)
+	ctx := context.Background()
+
+	userId := createTestUser(t, db)
+	postId := createTestPost(t, db, userId, "pictured")
+
+	id, err := repo.Create(ctx, &models.Media{
+		UserId: userId, PostId: &postId, StorageKey: "a.png", ThumbnailKey: "a_thumb.png",
+		ContentType: "image/png", Size: 1024, Width: 640, Height: 480,
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got '%v'", err)
+	}
+
+	media, err := repo.FindById(ctx, id)
+	if err != nil || media == nil {
+		t.Fatalf("Expected the media, got '%v' and error '%v'", media, err)
+	}
+	if media.StorageKey != "a.png" || media.PostId == nil || *media.PostId != postId || media.Width != 640 {
+		t.Errorf("Expected the saved media, got '%+v'", media)
+	}
+
+	if media, _ := repo.FindByPostId(ctx, postId); len(media) != 1 {
+		t.Errorf("Expected '%d', got '%d'", 1, len(media))
+	}
+
+	// deleting the post keeps the upload
+	NewPostRepository(db).Delete(ctx, postId)
+	if media, _ := repo.FindById(ctx, id); media == nil || media.PostId != nil {
+		t.Errorf("Expected the media to be detached, got '%+v'", media)
+	}
+
+	if err := repo.Delete(ctx, id); err != nil {
+		t.Fatalf("Expected no error, got '%v'", err)
+	}
+	if media, _ := repo.FindById(ctx, id); media != nil {
+		t.Errorf("Expected the media to be deleted")
+	}
+}
@@ -0,0 +1,182 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/achintha-dilshan/go-rest-api/internal/models"
+)
+
+type notificationRepository struct {
+	db *sql.DB
+}
+
+type NotificationRepository interface {
+	Create(ctx context.Context, notification *models.Notification) (bool, error)
+	FindByUserId(ctx context.Context, userId int64, unreadOnly bool, limit, offset int) ([]*models.Notification, int64, error)
+	CountUnread(ctx context.Context, userId int64) (int64, error)
+	MarkRead(ctx context.Context, userId, id int64) (bool, error)
+	MarkAllRead(ctx context.Context, userId int64) (int64, error)
+	FindPreferences(ctx context.Context, userId int64) (map[string]bool, error)
+	SetPreference(ctx context.Context, userId int64, notificationType string, enabled bool) error
+}
+
+func NewNotificationRepository(db *sql.DB) NotificationRepository {
+	return &notificationRepository{db: db}
+}
+
+// inserts a notification unless the recipient does not exist or has turned
+// the type off, reporting whether it was inserted
+func (r *notificationRepository) Create(ctx context.Context, notification *models.Notification) (bool, error) {
+	query := "INSERT INTO notifications (user_id, actor_id, type, post_id, data) " +
+		"SELECT u.id, ?, ?, ?, ? FROM users u WHERE u.id = ? AND NOT EXISTS " +
+		"(SELECT 1 FROM notification_preferences p WHERE p.user_id = u.id AND p.type = ? AND p.enabled = FALSE)"
+	id, err := insert(ctx, r.db, query,
+		notification.ActorId, notification.Type, notification.PostId, nullJSON(notification.Data),
+		notification.UserId, notification.Type,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	notification.Id = id
+	return true, nil
+}
+
+// retrieves the notifications of a user, newest first
+func (r *notificationRepository) FindByUserId(ctx context.Context, userId int64, unreadOnly bool, limit, offset int) ([]*models.Notification, int64, error) {
+	var notifications []*models.Notification
+	var total int64
+
+	where := " WHERE n.user_id = ?"
+	if unreadOnly {
+		where += " AND n.read_at IS NULL"
+	}
+
+	if err := conn(ctx, r.db).QueryRowContext(ctx, "SELECT COUNT(*) FROM notifications n"+where, userId).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query := "SELECT n.id, n.user_id, n.actor_id, COALESCE(a.name, ''), n.type, n.post_id, n.data, n.read_at, n.created_at " +
+		"FROM notifications n LEFT JOIN users a ON a.id = n.actor_id" + where + " ORDER BY n.id DESC LIMIT ? OFFSET ?"
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, userId, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		notification, err := scanNotification(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		notifications = append(notifications, notification)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return notifications, total, nil
+}
+
+// counts the unread notifications of a user
+func (r *notificationRepository) CountUnread(ctx context.Context, userId int64) (int64, error) {
+	var count int64
+	query := "SELECT COUNT(*) FROM notifications WHERE user_id = ? AND read_at IS NULL"
+	err := conn(ctx, r.db).QueryRowContext(ctx, query, userId).Scan(&count)
+
+	return count, err
+}
+
+// marks a notification of a user read, reporting whether it exists
+func (r *notificationRepository) MarkRead(ctx context.Context, userId, id int64) (bool, error) {
+	var exists bool
+	query := "SELECT EXISTS(SELECT 1 FROM notifications WHERE id = ? AND user_id = ?)"
+	if err := conn(ctx, r.db).QueryRowContext(ctx, query, id, userId).Scan(&exists); err != nil || !exists {
+		return false, err
+	}
+
+	query = "UPDATE notifications SET read_at = CURRENT_TIMESTAMP WHERE id = ? AND user_id = ? AND read_at IS NULL"
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, id, userId)
+
+	return true, err
+}
+
+// marks every notification of a user read, returning how many were unread
+func (r *notificationRepository) MarkAllRead(ctx context.Context, userId int64) (int64, error) {
+	query := "UPDATE notifications SET read_at = CURRENT_TIMESTAMP WHERE user_id = ? AND read_at IS NULL"
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, userId)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+// retrieves the preferences a user has set, by notification type
+func (r *notificationRepository) FindPreferences(ctx context.Context, userId int64) (map[string]bool, error) {
+	preferences := make(map[string]bool)
+	query := "SELECT type, enabled FROM notification_preferences WHERE user_id = ?"
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, userId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var notificationType string
+		var enabled bool
+		if err := rows.Scan(&notificationType, &enabled); err != nil {
+			return nil, err
+		}
+
+		preferences[notificationType] = enabled
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return preferences, nil
+}
+
+// turns a notification type on or off for a user
+func (r *notificationRepository) SetPreference(ctx context.Context, userId int64, notificationType string, enabled bool) error {
+	query := "INSERT INTO notification_preferences (user_id, type, enabled) VALUES (?, ?, ?)" +
+		dialectOf(r.db).upsert([]string{"user_id", "type"}, "enabled")
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, userId, notificationType, enabled)
+
+	return err
+}
+
+func scanNotification(row rowScanner) (*models.Notification, error) {
+	var notification models.Notification
+	var actorId, postId sql.NullInt64
+	var readAt sql.NullTime
+	var data []byte
+
+	err := row.Scan(&notification.Id, &notification.UserId, &actorId, &notification.ActorName, &notification.Type, &postId, &data, &readAt, &notification.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if actorId.Valid {
+		notification.ActorId = &actorId.Int64
+	}
+	if postId.Valid {
+		notification.PostId = &postId.Int64
+	}
+	if readAt.Valid {
+		notification.ReadAt = &readAt.Time
+	}
+	notification.Data = data
+
+	return &notification, nil
+}
@@ -0,0 +1,86 @@
+package repositories
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/achintha-dilshan/go-rest-api/internal/models"
+)
+
+func TestNotificationRepository(t *testing.T) {
+	db := newTestDB(t)
+	repo := NewNotificationRepository(db)
+	ctx := context.Background()
+
+	userId := createTestUser(t, db)
+	actorId := createTestUser(t, db)
+	postId := createTestPost(t, db, userId, "noticed")
+
+	notification := &models.Notification{
+		UserId:  userId,
+		ActorId: &actorId,
+		Type:    models.NotificationReaction,
+		PostId:  &postId,
+		Data:    json.RawMessage(`{"emoji":"👍"}`),
+	}
+	if created, err := repo.Create(ctx, notification); err != nil || !created {
+		t.Fatalf("Expected the notification to be created, got '%v' and error '%v'", created, err)
+	}
+	if notification.Id == 0 {
+		t.Errorf("Expected the notification id to be set")
+	}
+
+	missing := &models.Notification{UserId: userId + 100, Type: models.NotificationFollow}
+	if created, err := repo.Create(ctx, missing); err != nil || created {
+		t.Errorf("Expected no notification for a missing user, got '%v' and error '%v'", created, err)
+	}
+
+	notifications, total, err := repo.FindByUserId(ctx, userId, true, 10, 0)
+	if err != nil || total != 1 || len(notifications) != 1 {
+		t.Fatalf("Expected 1 notification, got %d of %d and error '%v'", len(notifications), total, err)
+	}
+	if got := notifications[0]; got.ActorName == "" || *got.PostId != postId || string(got.Data) != `{"emoji":"👍"}` {
+		t.Errorf("Expected the saved notification, got '%+v'", got)
+	}
+
+	if marked, err := repo.MarkRead(ctx, userId, notification.Id); err != nil || !marked {
+		t.Errorf("Expected the notification to be marked read, got '%v' and error '%v'", marked, err)
+	}
+	if marked, _ := repo.MarkRead(ctx, actorId, notification.Id); marked {
+		t.Errorf("Expected another user's notification not to be found")
+	}
+	if count, _ := repo.CountUnread(ctx, userId); count != 0 {
+		t.Errorf("Expected '%d', got '%d'", 0, count)
+	}
+}
+
+func TestNotificationRepositoryPreferences(t *testing.T) {
+	db := newTestDB(t)
+	repo := NewNotificationRepository(db)
+	ctx := context.Background()
+
+	userId := createTestUser(t, db)
+
+	if err := repo.SetPreference(ctx, userId, models.NotificationFollow, false); err != nil {
+		t.Fatalf("Expected no error, got '%v'", err)
+	}
+	if created, _ := repo.Create(ctx, &models.Notification{UserId: userId, Type: models.NotificationFollow}); created {
+		t.Errorf("Expected a turned off type not to be created")
+	}
+
+	if err := repo.SetPreference(ctx, userId, models.NotificationFollow, true); err != nil {
+		t.Fatalf("Expected no error, got '%v'", err)
+	}
+	preferences, err := repo.FindPreferences(ctx, userId)
+	if err != nil || len(preferences) != 1 || !preferences[models.NotificationFollow] {
+		t.Errorf("Expected follow notifications turned on, got '%v' and error '%v'", preferences, err)
+	}
+
+	for i := 0; i < 2; i++ {
+		repo.Create(ctx, &models.Notification{UserId: userId, Type: models.NotificationFollow})
+	}
+	if count, err := repo.MarkAllRead(ctx, userId); err != nil || count != 2 {
+		t.Errorf("Expected 2 notifications marked read, got %d and error '%v'", count, err)
+	}
+}
@@ -0,0 +1,92 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/achintha-dilshan/go-rest-api/internal/models"
+)
+
+type outboxRepository struct {
+	db *sql.DB
+}
+
+type OutboxRepository interface {
+	Add(ctx context.Context, event string, payload []byte) error
+	ClaimUnpublished(ctx context.Context, limit int) ([]*models.OutboxEvent, error)
+	MarkPublished(ctx context.Context, ids []int64) error
+	Prune(ctx context.Context, olderThan time.Duration) (int64, error)
+}
+
+func NewOutboxRepository(db *sql.DB) OutboxRepository {
+	return &outboxRepository{db: db}
+}
+
+// records an event; called in the transaction of the change it describes
+func (r *outboxRepository) Add(ctx context.Context, event string, payload []byte) error {
+	query := "INSERT INTO outbox (event, payload) VALUES (?, ?)"
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, event, string(payload))
+
+	return err
+}
+
+// retrieves the oldest unpublished events, locking them until the
+// transaction ends; events locked by another transaction are skipped
+func (r *outboxRepository) ClaimUnpublished(ctx context.Context, limit int) ([]*models.OutboxEvent, error) {
+	var events []*models.OutboxEvent
+	query := "SELECT id, event, payload, created_at FROM outbox WHERE published_at IS NULL ORDER BY id LIMIT ?" + dialectOf(r.db).skipLocked("")
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var event models.OutboxEvent
+		var payload []byte
+		if err := rows.Scan(&event.Id, &event.Event, &payload, &event.CreatedAt); err != nil {
+			return nil, err
+		}
+		event.Payload = payload
+
+		events = append(events, &event)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return events, nil
+}
+
+// marks events as handed over
+func (r *outboxRepository) MarkPublished(ctx context.Context, ids []int64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	args := make([]any, len(ids))
+	for i, id := range ids {
+		args[i] = id
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(ids)), ", ")
+	query := "UPDATE outbox SET published_at = CURRENT_TIMESTAMP WHERE id IN (" + placeholders + ")"
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, args...)
+
+	return err
+}
+
+// deletes events published longer ago than olderThan, returning how many were deleted
+func (r *outboxRepository) Prune(ctx context.Context, olderThan time.Duration) (int64, error) {
+	query := "DELETE FROM outbox WHERE published_at < ?"
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, time.Now().UTC().Add(-olderThan))
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
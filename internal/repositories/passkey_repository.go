@@ -0,0 +1,121 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/achintha-dilshan/go-rest-api/internal/models"
+)
+
+type passkeyRepository struct {
+	db *sql.DB
+}
+
+type PasskeyRepository interface {
+	Create(ctx context.Context, passkey *models.Passkey) (int64, error)
+	FindById(ctx context.Context, id int64) (*models.Passkey, error)
+	FindByCredentialId(ctx context.Context, credentialId []byte) (*models.Passkey, error)
+	FindByUserId(ctx context.Context, userId int64) ([]*models.Passkey, error)
+	UpdateSignCount(ctx context.Context, id int64, signCount uint32) error
+	Delete(ctx context.Context, id int64) error
+}
+
+func NewPasskeyRepository(db *sql.DB) PasskeyRepository {
+	return &passkeyRepository{db: db}
+}
+
+const passkeyColumns = "id, user_id, name, credential_id, public_key, sign_count, last_used_at, created_at"
+
+// inserts a new passkey into the database
+func (r *passkeyRepository) Create(ctx context.Context, passkey *models.Passkey) (int64, error) {
+	query := "INSERT INTO passkeys (user_id, name, credential_id, public_key, sign_count) VALUES (?, ?, ?, ?, ?)"
+	return insert(ctx, r.db, query, passkey.UserId, passkey.Name, passkey.CredentialId, passkey.PublicKey, passkey.SignCount)
+}
+
+// retrieves a passkey by ID
+func (r *passkeyRepository) FindById(ctx context.Context, id int64) (*models.Passkey, error) {
+	query := "SELECT " + passkeyColumns + " FROM passkeys WHERE id = ?"
+	passkey, err := scanPasskey(conn(ctx, r.db).QueryRowContext(ctx, query, id))
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+
+	return passkey, err
+}
+
+// retrieves a passkey by its WebAuthn credential ID
+func (r *passkeyRepository) FindByCredentialId(ctx context.Context, credentialId []byte) (*models.Passkey, error) {
+	query := "SELECT " + passkeyColumns + " FROM passkeys WHERE credential_id = ?"
+	passkey, err := scanPasskey(conn(ctx, r.db).QueryRowContext(ctx, query, credentialId))
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+
+	return passkey, err
+}
+
+// retrieves all passkeys of a user
+func (r *passkeyRepository) FindByUserId(ctx context.Context, userId int64) ([]*models.Passkey, error) {
+	var passkeys []*models.Passkey
+	query := "SELECT " + passkeyColumns + " FROM passkeys WHERE user_id = ? ORDER BY id"
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, userId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		passkey, err := scanPasskey(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		passkeys = append(passkeys, passkey)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return passkeys, nil
+}
+
+// stores the latest sign counter after a successful login
+func (r *passkeyRepository) UpdateSignCount(ctx context.Context, id int64, signCount uint32) error {
+	query := "UPDATE passkeys SET sign_count = ?, last_used_at = CURRENT_TIMESTAMP WHERE id = ?"
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, signCount, id)
+
+	return err
+}
+
+// removes a passkey from the database
+func (r *passkeyRepository) Delete(ctx context.Context, id int64) error {
+	query := "DELETE FROM passkeys WHERE id = ?"
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, id)
+
+	return err
+}
+
+// scanPasskey reads a single passkey row
+func scanPasskey(row rowScanner) (*models.Passkey, error) {
+	var passkey models.Passkey
+	var lastUsedAt sql.NullTime
+
+	err := row.Scan(
+		&passkey.Id, &passkey.UserId, &passkey.Name, &passkey.CredentialId, &passkey.PublicKey,
+		&passkey.SignCount, &lastUsedAt, &passkey.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if lastUsedAt.Valid {
+		passkey.LastUsedAt = &lastUsedAt.Time
+	}
+
+	return &passkey, nil
+}
@@ -0,0 +1,49 @@
+package repositories
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/achintha-dilshan/go-rest-api/internal/models"
+)
+
+func TestPasskeyRepository(t *testing.T) {
+	db := newTestDB(t)
+	repo := NewPasskeyRepository(db)
+	ctx := context.Background()
+
+	userId := createTestUser(t, db)
+	credentialId := []byte{0x01, 0x02, 0xff}
+
+	id, err := repo.Create(ctx, &models.Passkey{UserId: userId, Name: "Laptop", CredentialId: credentialId, PublicKey: []byte{0x04}, SignCount: 1})
+	if err != nil {
+		t.Fatalf("Expected no error, got '%v'", err)
+	}
+
+	passkey, err := repo.FindByCredentialId(ctx, credentialId)
+	if err != nil || passkey == nil || passkey.Id != id {
+		t.Fatalf("Expected passkey %d, got '%+v' and error '%v'", id, passkey, err)
+	}
+	if !bytes.Equal(passkey.CredentialId, credentialId) || passkey.SignCount != 1 {
+		t.Errorf("Expected the saved passkey, got '%+v'", passkey)
+	}
+
+	if err := repo.UpdateSignCount(ctx, id, 4294967295); err != nil {
+		t.Fatalf("Expected no error, got '%v'", err)
+	}
+	if passkey, _ := repo.FindById(ctx, id); passkey.SignCount != 4294967295 || passkey.LastUsedAt == nil {
+		t.Errorf("Expected the sign count to be saved, got '%+v'", passkey)
+	}
+
+	if passkeys, _ := repo.FindByUserId(ctx, userId); len(passkeys) != 1 {
+		t.Errorf("Expected '%d', got '%d'", 1, len(passkeys))
+	}
+
+	if err := repo.Delete(ctx, id); err != nil {
+		t.Fatalf("Expected no error, got '%v'", err)
+	}
+	if passkey, _ := repo.FindById(ctx, id); passkey != nil {
+		t.Errorf("Expected the passkey to be deleted")
+	}
+}
@@ -4,8 +4,11 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"strconv"
+	"strings"
 
 	"github.com/achintha-dilshan/go-rest-api/internal/models"
+	"github.com/achintha-dilshan/go-rest-api/internal/utils/cursor"
 )
 
 type postRepository struct {
@@ -16,6 +19,15 @@ type PostRepository interface {
 	Create(ctx context.Context, post *models.Post) (int64, error)
 	FindAll(ctx context.Context) ([]*models.Post, error)
 	FindById(ctx context.Context, id int64) (*models.Post, error)
+	FindByAuthorId(ctx context.Context, authorId int64) ([]*models.Post, error)
+	CountByAuthorId(ctx context.Context, authorId int64) (int64, error)
+	FindFeedByAuthors(ctx context.Context, authorIds []int64, before *cursor.Cursor, limit int) ([]*models.Post, error)
+	FindFeedByFollower(ctx context.Context, followerId int64, before *cursor.Cursor, limit int) ([]*models.Post, error)
+	FindBySlug(ctx context.Context, slug string) (*models.Post, error)
+	FindIdByOldSlug(ctx context.Context, slug string) (int64, error)
+	FindTakenSlugs(ctx context.Context, base string, excludeId int64) ([]string, error)
+	AddOldSlug(ctx context.Context, postId int64, slug string) error
+	RemoveOldSlug(ctx context.Context, postId int64, slug string) error
 	Update(ctx context.Context, post *models.Post) error
 	Delete(ctx context.Context, id int64) error
 }
@@ -26,72 +38,237 @@ func NewPostRepository(db *sql.DB) PostRepository {
 	}
 }
 
+const postColumns = "id, author_id, title, slug, body, format, created_at, updated_at"
+
 // inserts a new post into the database
 func (r *postRepository) Create(ctx context.Context, post *models.Post) (int64, error) {
-	query := "INSERT INTO posts (author_id, title, body) VALUES (?, ?, ?)"
-	result, err := r.db.ExecContext(ctx, query, post.AuthorId, post.Title, post.Body)
+	query := "INSERT INTO posts (author_id, title, slug, body, format) VALUES (?, ?, ?, ?, ?)"
+	var id int64
+	err := savepoint(ctx, r.db, func() error {
+		var err error
+		id, err = insert(ctx, r.db, query, post.AuthorId, post.Title, post.Slug, post.Body, post.Format)
+		return err
+	})
 
-	if err != nil {
-		return 0, err
+	if isDuplicateKey(err) {
+		return 0, ErrDuplicate
 	}
 
-	return result.LastInsertId()
+	return id, err
 }
 
 // retrieve all posts
 func (r *postRepository) FindAll(ctx context.Context) ([]*models.Post, error) {
-	var posts []*models.Post
-	query := "SELECT id, author_id, title, body FROM posts"
+	query := "SELECT " + postColumns + " FROM posts"
+	return r.findPosts(ctx, query)
+}
+
+// retrieves a post by ID, locking it when called in a transaction
+func (r *postRepository) FindById(ctx context.Context, id int64) (*models.Post, error) {
+	query := "SELECT " + postColumns + " FROM posts WHERE id = ?" + forUpdate(ctx, r.db)
+	post, err := scanPost(conn(ctx, r.db).QueryRowContext(ctx, query, id))
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+
+	return post, err
+}
+
+// retrieves all posts of an author
+func (r *postRepository) FindByAuthorId(ctx context.Context, authorId int64) ([]*models.Post, error) {
+	query := "SELECT " + postColumns + " FROM posts WHERE author_id = ?"
+	return r.findPosts(ctx, query, authorId)
+}
+
+// retrieves a post by its current slug
+func (r *postRepository) FindBySlug(ctx context.Context, slug string) (*models.Post, error) {
+	query := "SELECT " + postColumns + " FROM posts WHERE slug = ?"
+	post, err := scanPost(conn(ctx, r.db).QueryRowContext(ctx, query, slug))
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+
+	return post, err
+}
 
-	rows, err := r.db.QueryContext(ctx, query)
+// retrieves the ID of the post a former slug belonged to, or 0 if there is none
+func (r *postRepository) FindIdByOldSlug(ctx context.Context, slug string) (int64, error) {
+	var id int64
+	query := "SELECT post_id FROM post_slugs WHERE slug = ?"
+	err := conn(ctx, r.db).QueryRowContext(ctx, query, slug).Scan(&id)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+
+	return id, err
+}
+
+// retrieves the slugs, current or former, of posts other than excludeId that
+// are base or start with base and a hyphen
+func (r *postRepository) FindTakenSlugs(ctx context.Context, base string, excludeId int64) ([]string, error) {
+	var slugs []string
+
+	pattern := escapeLike(base) + "-%"
+	query := "SELECT slug FROM posts WHERE (slug = ? OR slug LIKE ? ESCAPE '!') AND id <> ?" +
+		" UNION SELECT slug FROM post_slugs WHERE (slug = ? OR slug LIKE ? ESCAPE '!') AND post_id <> ?"
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, base, pattern, excludeId, base, pattern, excludeId)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
 	for rows.Next() {
-		var post models.Post
-		if err := rows.Scan(&post.Id, &post.AuthorId, &post.Title, &post.Body); err != nil {
+		var slug string
+		if err := rows.Scan(&slug); err != nil {
 			return nil, err
 		}
 
-		posts = append(posts, &post)
+		slugs = append(slugs, slug)
 	}
 
 	if err := rows.Err(); err != nil {
 		return nil, err
 	}
 
-	return posts, nil
+	return slugs, nil
 }
 
-// retrieves a post by ID
-func (r *postRepository) FindById(ctx context.Context, id int64) (*models.Post, error) {
-	var post models.Post
-	query := "SELECT id, author_id, title, body FROM posts WHERE id = ?"
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
-		&post.Id, &post.AuthorId, &post.Title, &post.Body,
-	)
+// keeps a former slug of a post so links to it still resolve
+func (r *postRepository) AddOldSlug(ctx context.Context, postId int64, slug string) error {
+	query := "INSERT INTO post_slugs (slug, post_id) VALUES (?, ?)"
+	err := savepoint(ctx, r.db, func() error {
+		_, err := conn(ctx, r.db).ExecContext(ctx, query, slug, postId)
+		return err
+	})
 
-	if errors.Is(err, sql.ErrNoRows) {
+	if isDuplicateKey(err) {
+		return ErrDuplicate
+	}
+	return err
+}
+
+// forgets a former slug of a post, when the post takes it back
+func (r *postRepository) RemoveOldSlug(ctx context.Context, postId int64, slug string) error {
+	query := "DELETE FROM post_slugs WHERE slug = ? AND post_id = ?"
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, slug, postId)
+
+	return err
+}
+
+// counts the posts of an author
+func (r *postRepository) CountByAuthorId(ctx context.Context, authorId int64) (int64, error) {
+	var count int64
+	query := "SELECT COUNT(*) FROM posts WHERE author_id = ?"
+	err := conn(ctx, r.db).QueryRowContext(ctx, query, authorId).Scan(&count)
+
+	return count, err
+}
+
+// retrieves the newest posts of the given authors older than before.
+// Each author's posts are read from their own index range and merged, which
+// stays cheap while the list of authors is short.
+func (r *postRepository) FindFeedByAuthors(ctx context.Context, authorIds []int64, before *cursor.Cursor, limit int) ([]*models.Post, error) {
+	if len(authorIds) == 0 {
 		return nil, nil
 	}
 
-	return &post, err
+	keyset, keysetArgs := feedKeyset(before)
+	parts := make([]string, len(authorIds))
+	var args []any
+	for i, authorId := range authorIds {
+		parts[i] = "SELECT * FROM (SELECT " + postColumns + " FROM posts WHERE author_id = ?" + keyset +
+			" ORDER BY created_at DESC, id DESC LIMIT ?) a" + strconv.Itoa(i)
+		args = append(args, authorId)
+		args = append(args, keysetArgs...)
+		args = append(args, limit)
+	}
+
+	query := "SELECT " + postColumns + " FROM (" + strings.Join(parts, " UNION ALL ") + ") feed ORDER BY created_at DESC, id DESC LIMIT ?"
+	return r.findPosts(ctx, query, append(args, limit)...)
+}
+
+// retrieves the newest posts by authors a user follows older than before.
+// Posts are walked newest first and checked against the follows, which
+// suits users following many authors.
+func (r *postRepository) FindFeedByFollower(ctx context.Context, followerId int64, before *cursor.Cursor, limit int) ([]*models.Post, error) {
+	keyset, keysetArgs := feedKeyset(before)
+	query := "SELECT " + postColumns + " FROM posts p WHERE EXISTS (SELECT 1 FROM follows f WHERE f.follower_id = ? AND f.followee_id = p.author_id)" +
+		keyset + " ORDER BY created_at DESC, id DESC LIMIT ?"
+
+	args := append([]any{followerId}, keysetArgs...)
+	return r.findPosts(ctx, query, append(args, limit)...)
 }
 
 // updates a post's details in the database
 func (r *postRepository) Update(ctx context.Context, post *models.Post) error {
-	query := "UPDATE posts SET title = ?, body = ? WHERE id = ?"
-	_, err := r.db.ExecContext(ctx, query, post.Title, post.Body, post.Id)
+	query := "UPDATE posts SET title = ?, slug = ?, body = ?, format = ? WHERE id = ?"
+	err := savepoint(ctx, r.db, func() error {
+		_, err := conn(ctx, r.db).ExecContext(ctx, query, post.Title, post.Slug, post.Body, post.Format, post.Id)
+		return err
+	})
 
+	if isDuplicateKey(err) {
+		return ErrDuplicate
+	}
 	return err
 }
 
 // removes a post from the database
 func (r *postRepository) Delete(ctx context.Context, id int64) error {
 	query := "DELETE FROM posts WHERE id = ?"
-	_, err := r.db.ExecContext(ctx, query, id)
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, id)
 
 	return err
 }
+
+// findPosts runs a query selecting postColumns and collects the posts
+func (r *postRepository) findPosts(ctx context.Context, query string, args ...any) ([]*models.Post, error) {
+	var posts []*models.Post
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		post, err := scanPost(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		posts = append(posts, post)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return posts, nil
+}
+
+// feedKeyset returns the condition selecting posts older than before
+func feedKeyset(before *cursor.Cursor) (string, []any) {
+	if before == nil {
+		return "", nil
+	}
+
+	return " AND (created_at < ? OR (created_at = ? AND id < ?))", []any{before.CreatedAt, before.CreatedAt, before.Id}
+}
+
+func scanPost(row rowScanner) (*models.Post, error) {
+	var post models.Post
+	var updatedAt sql.NullTime
+
+	err := row.Scan(&post.Id, &post.AuthorId, &post.Title, &post.Slug, &post.Body, &post.Format, &post.CreatedAt, &updatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	post.UpdatedAt = updatedAt.Time
+
+	return &post, nil
+}
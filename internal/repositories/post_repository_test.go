@@ -0,0 +1,198 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/achintha-dilshan/go-rest-api/internal/models"
+	"github.com/achintha-dilshan/go-rest-api/internal/utils/cursor"
+)
+
+func TestPostRepositoryCreateAndFind(t *testing.T) {
+	db := newTestDB(t)
+	repo := NewPostRepository(db)
+	ctx := context.Background()
+
+	authorId := createTestUser(t, db)
+	id := createTestPost(t, db, authorId, "hello-world")
+
+	post, err := repo.FindById(ctx, id)
+	if err != nil || post == nil {
+		t.Fatalf("Expected the post, got '%v' and error '%v'", post, err)
+	}
+	if post.AuthorId != authorId || post.Slug != "hello-world" || post.Format != models.PostFormatPlain {
+		t.Errorf("Expected the saved post, got '%+v'", post)
+	}
+
+	bySlug, err := repo.FindBySlug(ctx, "hello-world")
+	if err != nil || bySlug == nil || bySlug.Id != id {
+		t.Errorf("Expected post %d by slug, got '%v' and error '%v'", id, bySlug, err)
+	}
+
+	if count, _ := repo.CountByAuthorId(ctx, authorId); count != 1 {
+		t.Errorf("Expected '%d', got '%d'", 1, count)
+	}
+
+	_, err = repo.Create(ctx, &models.Post{AuthorId: authorId, Title: "Again", Slug: "hello-world", Body: "Body", Format: models.PostFormatPlain})
+	if !errors.Is(err, ErrDuplicate) {
+		t.Errorf("Expected '%v', got '%v'", ErrDuplicate, err)
+	}
+}
+
+func TestPostRepositoryUpdate(t *testing.T) {
+	db := newTestDB(t)
+	repo := NewPostRepository(db)
+	ctx := context.Background()
+
+	authorId := createTestUser(t, db)
+	createTestPost(t, db, authorId, "taken")
+	post, _ := repo.FindById(ctx, createTestPost(t, db, authorId, "draft"))
+
+	post.Title = "Published"
+	post.Slug = "published"
+	post.Format = models.PostFormatMarkdown
+	if err := repo.Update(ctx, post); err != nil {
+		t.Fatalf("Expected no error, got '%v'", err)
+	}
+
+	updated, _ := repo.FindById(ctx, post.Id)
+	if updated.Title != "Published" || updated.Slug != "published" || updated.Format != models.PostFormatMarkdown {
+		t.Errorf("Expected the changes to be saved, got '%+v'", updated)
+	}
+	if updated.UpdatedAt.IsZero() {
+		t.Errorf("Expected the update time to be set")
+	}
+
+	post.Slug = "taken"
+	if err := repo.Update(ctx, post); !errors.Is(err, ErrDuplicate) {
+		t.Errorf("Expected '%v', got '%v'", ErrDuplicate, err)
+	}
+}
+
+func TestPostRepositoryOldSlugs(t *testing.T) {
+	db := newTestDB(t)
+	repo := NewPostRepository(db)
+	ctx := context.Background()
+
+	authorId := createTestUser(t, db)
+	id := createTestPost(t, db, authorId, "current")
+	otherId := createTestPost(t, db, authorId, "other")
+
+	if err := repo.AddOldSlug(ctx, id, "former"); err != nil {
+		t.Fatalf("Expected no error, got '%v'", err)
+	}
+	if err := repo.AddOldSlug(ctx, id, "former"); !errors.Is(err, ErrDuplicate) {
+		t.Errorf("Expected '%v', got '%v'", ErrDuplicate, err)
+	}
+
+	if found, _ := repo.FindIdByOldSlug(ctx, "former"); found != id {
+		t.Errorf("Expected '%d', got '%d'", id, found)
+	}
+	if taken, _ := repo.FindTakenSlugs(ctx, "former", otherId); len(taken) != 1 {
+		t.Errorf("Expected a former slug to be taken for other posts, got '%v'", taken)
+	}
+	if taken, _ := repo.FindTakenSlugs(ctx, "former", id); len(taken) != 0 {
+		t.Errorf("Expected a post's own former slug to be free for it, got '%v'", taken)
+	}
+
+	if err := repo.RemoveOldSlug(ctx, id, "former"); err != nil {
+		t.Fatalf("Expected no error, got '%v'", err)
+	}
+	if found, _ := repo.FindIdByOldSlug(ctx, "former"); found != 0 {
+		t.Errorf("Expected '%d', got '%d'", 0, found)
+	}
+}
+
+func TestPostRepositoryFeed(t *testing.T) {
+	db := newTestDB(t)
+	repo := NewPostRepository(db)
+	follows := NewFollowRepository(db)
+	ctx := context.Background()
+
+	readerId := createTestUser(t, db)
+	aliceId := createTestUser(t, db)
+	bobId := createTestUser(t, db)
+	carolId := createTestUser(t, db)
+
+	a1 := createTestPost(t, db, aliceId, "a1")
+	b1 := createTestPost(t, db, bobId, "b1")
+	createTestPost(t, db, carolId, "c1")
+	a2 := createTestPost(t, db, aliceId, "a2")
+	b2 := createTestPost(t, db, bobId, "b2")
+
+	follows.Follow(ctx, readerId, aliceId)
+	follows.Follow(ctx, readerId, bobId)
+
+	// posts created within the same second are ordered by id
+	want := []int64{b2, a2, b1, a1}
+
+	byAuthors, err := repo.FindFeedByAuthors(ctx, []int64{aliceId, bobId}, nil, 10)
+	if err != nil {
+		t.Fatalf("Expected no error, got '%v'", err)
+	}
+	assertPostIds(t, byAuthors, want)
+
+	byFollower, err := repo.FindFeedByFollower(ctx, readerId, nil, 10)
+	if err != nil {
+		t.Fatalf("Expected no error, got '%v'", err)
+	}
+	assertPostIds(t, byFollower, want)
+
+	page, _ := repo.FindFeedByAuthors(ctx, []int64{aliceId, bobId}, nil, 2)
+	assertPostIds(t, page, want[:2])
+
+	last := page[len(page)-1]
+	before := &cursor.Cursor{CreatedAt: last.CreatedAt, Id: last.Id}
+
+	next, _ := repo.FindFeedByAuthors(ctx, []int64{aliceId, bobId}, before, 2)
+	assertPostIds(t, next, want[2:])
+
+	next, _ = repo.FindFeedByFollower(ctx, readerId, before, 2)
+	assertPostIds(t, next, want[2:])
+}
+
+// assertPostIds checks the posts are those with the given ids, in order.
+func assertPostIds(t *testing.T, posts []*models.Post, want []int64) {
+	t.Helper()
+
+	got := make([]int64, len(posts))
+	for i, post := range posts {
+		got[i] = post.Id
+	}
+
+	if len(got) != len(want) {
+		t.Fatalf("Expected posts '%v', got '%v'", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("Expected posts '%v', got '%v'", want, got)
+		}
+	}
+}
+
+func TestPostRepositoryFindTakenSlugs(t *testing.T) {
+	db := newTestDB(t)
+	repo := NewPostRepository(db)
+	ctx := context.Background()
+
+	authorId := createTestUser(t, db)
+	id := createTestPost(t, db, authorId, "hello")
+	createTestPost(t, db, authorId, "hello-2")
+	createTestPost(t, db, authorId, "hello-world")
+	createTestPost(t, db, authorId, "hellos")
+	if err := repo.AddOldSlug(ctx, id, "hello-3"); err != nil {
+		t.Fatalf("Expected no error, got '%v'", err)
+	}
+
+	taken, err := repo.FindTakenSlugs(ctx, "hello", 0)
+	if err != nil {
+		t.Fatalf("Expected no error, got '%v'", err)
+	}
+	sort.Strings(taken)
+	if strings.Join(taken, ",") != "hello,hello-2,hello-3,hello-world" {
+		t.Errorf("Expected '%s', got '%s'", "hello,hello-2,hello-3,hello-world", strings.Join(taken, ","))
+	}
+}
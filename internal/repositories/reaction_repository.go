@@ -0,0 +1,139 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	"github.com/achintha-dilshan/go-rest-api/internal/models"
+)
+
+// how many post IDs CountByPostIds puts in one query, well below the
+// placeholder limits of every database
+const reactionCountChunk = 500
+
+type reactionRepository struct {
+	db *sql.DB
+}
+
+type ReactionRepository interface {
+	Add(ctx context.Context, postId, userId int64, emoji string) (bool, error)
+	Remove(ctx context.Context, postId, userId int64, emoji string) error
+	CountByPostIds(ctx context.Context, postIds []int64) (map[int64]map[string]int64, error)
+	FindByPostId(ctx context.Context, postId int64, emoji string, limit, offset int) ([]*models.Reaction, int64, error)
+}
+
+func NewReactionRepository(db *sql.DB) ReactionRepository {
+	return &reactionRepository{db: db}
+}
+
+// adds a reaction, reporting whether it is new; reacting twice with the same
+// emoji is not an error
+func (r *reactionRepository) Add(ctx context.Context, postId, userId int64, emoji string) (bool, error) {
+	query := "INSERT INTO post_reactions (post_id, user_id, emoji) VALUES (?, ?, ?)"
+	err := savepoint(ctx, r.db, func() error {
+		_, err := conn(ctx, r.db).ExecContext(ctx, query, postId, userId, emoji)
+		return err
+	})
+
+	if isDuplicateKey(err) {
+		return false, nil
+	}
+
+	return err == nil, err
+}
+
+// removes a reaction
+func (r *reactionRepository) Remove(ctx context.Context, postId, userId int64, emoji string) error {
+	query := "DELETE FROM post_reactions WHERE post_id = ? AND user_id = ? AND emoji = ?"
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, postId, userId, emoji)
+
+	return err
+}
+
+// counts the reactions of each emoji on the given posts, querying them in
+// chunks of reactionCountChunk
+func (r *reactionRepository) CountByPostIds(ctx context.Context, postIds []int64) (map[int64]map[string]int64, error) {
+	counts := make(map[int64]map[string]int64)
+
+	for start := 0; start < len(postIds); start += reactionCountChunk {
+		end := min(start+reactionCountChunk, len(postIds))
+		if err := r.countChunk(ctx, postIds[start:end], counts); err != nil {
+			return nil, err
+		}
+	}
+
+	return counts, nil
+}
+
+// countChunk adds the reaction counts of the given posts to counts
+func (r *reactionRepository) countChunk(ctx context.Context, postIds []int64, counts map[int64]map[string]int64) error {
+	args := make([]any, len(postIds))
+	for i, id := range postIds {
+		args[i] = id
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(postIds)), ", ")
+	query := "SELECT post_id, emoji, COUNT(*) FROM post_reactions WHERE post_id IN (" + placeholders + ") GROUP BY post_id, emoji"
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var postId, count int64
+		var emoji string
+		if err := rows.Scan(&postId, &emoji, &count); err != nil {
+			return err
+		}
+
+		if counts[postId] == nil {
+			counts[postId] = make(map[string]int64)
+		}
+		counts[postId][emoji] = count
+	}
+
+	return rows.Err()
+}
+
+// retrieves who reacted to a post, newest first, optionally for one emoji
+func (r *reactionRepository) FindByPostId(ctx context.Context, postId int64, emoji string, limit, offset int) ([]*models.Reaction, int64, error) {
+	var reactions []*models.Reaction
+	var total int64
+
+	where := " WHERE r.post_id = ?"
+	args := []any{postId}
+	if emoji != "" {
+		where += " AND r.emoji = ?"
+		args = append(args, emoji)
+	}
+
+	if err := conn(ctx, r.db).QueryRowContext(ctx, "SELECT COUNT(*) FROM post_reactions r"+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query := "SELECT r.post_id, r.user_id, u.name, u.avatar_url, r.emoji, r.created_at FROM post_reactions r JOIN users u ON u.id = r.user_id" +
+		where + " ORDER BY r.created_at DESC, r.user_id LIMIT ? OFFSET ?"
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, append(args, limit, offset)...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var reaction models.Reaction
+		if err := rows.Scan(&reaction.PostId, &reaction.UserId, &reaction.UserName, &reaction.UserAvatarURL, &reaction.Emoji, &reaction.CreatedAt); err != nil {
+			return nil, 0, err
+		}
+
+		reactions = append(reactions, &reaction)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return reactions, total, nil
+}
@@ -0,0 +1,78 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+)
+
+func TestReactionRepository(t *testing.T) {
+	db := newTestDB(t)
+	repo := NewReactionRepository(db)
+	ctx := context.Background()
+
+	authorId := createTestUser(t, db)
+	readerId := createTestUser(t, db)
+	postId := createTestPost(t, db, authorId, "reacted")
+	otherId := createTestPost(t, db, authorId, "quiet")
+
+	for _, reaction := range []struct {
+		userId int64
+		emoji  string
+	}{
+		{authorId, "👍"}, {readerId, "👍"}, {readerId, "🎉"},
+	} {
+		if added, err := repo.Add(ctx, postId, reaction.userId, reaction.emoji); err != nil || !added {
+			t.Fatalf("Expected the reaction to be added, got '%v' and error '%v'", added, err)
+		}
+	}
+	if added, err := repo.Add(ctx, postId, readerId, "👍"); err != nil || added {
+		t.Errorf("Expected a repeated reaction to be ignored, got '%v' and error '%v'", added, err)
+	}
+
+	counts, err := repo.CountByPostIds(ctx, []int64{postId, otherId})
+	if err != nil {
+		t.Fatalf("Expected no error, got '%v'", err)
+	}
+	if counts[postId]["👍"] != 2 || counts[postId]["🎉"] != 1 || len(counts[otherId]) != 0 {
+		t.Errorf("Expected 2 thumbs up and 1 party popper, got '%v'", counts)
+	}
+
+	reactions, total, err := repo.FindByPostId(ctx, postId, "👍", 10, 0)
+	if err != nil || total != 2 || len(reactions) != 2 {
+		t.Errorf("Expected 2 reactions, got %d of %d and error '%v'", len(reactions), total, err)
+	}
+
+	if err := repo.Remove(ctx, postId, readerId, "👍"); err != nil {
+		t.Fatalf("Expected no error, got '%v'", err)
+	}
+	if counts, _ := repo.CountByPostIds(ctx, []int64{postId}); counts[postId]["👍"] != 1 {
+		t.Errorf("Expected '%d', got '%d'", 1, counts[postId]["👍"])
+	}
+}
+
+func TestReactionRepositoryCountManyPosts(t *testing.T) {
+	db := newTestDB(t)
+	repo := NewReactionRepository(db)
+	ctx := context.Background()
+
+	authorId := createTestUser(t, db)
+	firstId := createTestPost(t, db, authorId, "first")
+	lastId := createTestPost(t, db, authorId, "last")
+	repo.Add(ctx, firstId, authorId, "👍")
+	repo.Add(ctx, lastId, authorId, "🎉")
+
+	// more IDs than fit in one chunk, with the posts in different chunks
+	postIds := []int64{firstId}
+	for i := int64(1); i <= 2*reactionCountChunk; i++ {
+		postIds = append(postIds, lastId+i)
+	}
+	postIds = append(postIds, lastId)
+
+	counts, err := repo.CountByPostIds(ctx, postIds)
+	if err != nil {
+		t.Fatalf("Expected no error, got '%v'", err)
+	}
+	if counts[firstId]["👍"] != 1 || counts[lastId]["🎉"] != 1 {
+		t.Errorf("Expected a reaction on each post, got '%v'", counts)
+	}
+}
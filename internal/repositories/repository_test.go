@@ -0,0 +1,125 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/achintha-dilshan/go-rest-api/internal/models"
+	"github.com/pressly/goose/v3"
+)
+
+// newTestDB returns a fresh SQLite database with every migration applied.
+func newTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "test.db")
+	db, err := sql.Open("sqlite", "file:"+path+"?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)")
+	if err != nil {
+		t.Fatalf("Failed to open the database: %v", err)
+	}
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+
+	provider, err := goose.NewProvider(goose.DialectSQLite3, db, os.DirFS("../../database/migrations/sqlite"))
+	if err != nil {
+		t.Fatalf("Failed to load the migrations: %v", err)
+	}
+	if _, err := provider.Up(context.Background()); err != nil {
+		t.Fatalf("Failed to apply the migrations: %v", err)
+	}
+
+	return db
+}
+
+var testUsers int
+
+// createTestUser inserts a user with a unique name and email.
+func createTestUser(t *testing.T, db *sql.DB) int64 {
+	t.Helper()
+
+	testUsers++
+	id, err := NewUserRepository(db).Create(context.Background(), &models.User{
+		Name:     fmt.Sprintf("User %d", testUsers),
+		Email:    fmt.Sprintf("user%d@example.com", testUsers),
+		Password: "hash",
+		Role:     models.RoleUser,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create a user: %v", err)
+	}
+
+	return id
+}
+
+// createTestPost inserts a post by authorId with the given slug.
+func createTestPost(t *testing.T, db *sql.DB, authorId int64, slug string) int64 {
+	t.Helper()
+
+	id, err := NewPostRepository(db).Create(context.Background(), &models.Post{
+		AuthorId: authorId,
+		Title:    slug,
+		Slug:     slug,
+		Body:     "Body of " + slug,
+		Format:   models.PostFormatPlain,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create a post: %v", err)
+	}
+
+	return id
+}
+
+func TestMigrationsDown(t *testing.T) {
+	db := newTestDB(t)
+
+	provider, err := goose.NewProvider(goose.DialectSQLite3, db, os.DirFS("../../database/migrations/sqlite"))
+	if err != nil {
+		t.Fatalf("Failed to load the migrations: %v", err)
+	}
+	if _, err := provider.DownTo(context.Background(), 0); err != nil {
+		t.Fatalf("Failed to roll back the migrations: %v", err)
+	}
+	if _, err := provider.Up(context.Background()); err != nil {
+		t.Fatalf("Failed to apply the migrations again: %v", err)
+	}
+}
+
+func TestRebind(t *testing.T) {
+	cases := []struct {
+		dialect Dialect
+		query   string
+		want    string
+	}{
+		{MySQL, "SELECT * FROM users WHERE id = ?", "SELECT * FROM users WHERE id = ?"},
+		{SQLite, "SELECT * FROM users WHERE id = ?", "SELECT * FROM users WHERE id = ?"},
+		{Postgres, "SELECT * FROM users WHERE id = ? AND name = ?", "SELECT * FROM users WHERE id = $1 AND name = $2"},
+		{Postgres, "SELECT * FROM users WHERE name = '?' AND id = ?", "SELECT * FROM users WHERE name = '?' AND id = $1"},
+	}
+
+	for _, c := range cases {
+		if got := c.dialect.rebind(c.query); got != c.want {
+			t.Errorf("Expected '%s', got '%s'", c.want, got)
+		}
+	}
+}
+
+func TestUpsert(t *testing.T) {
+	cases := []struct {
+		dialect Dialect
+		want    string
+	}{
+		{MySQL, " ON DUPLICATE KEY UPDATE enabled = VALUES(enabled)"},
+		{Postgres, " ON CONFLICT (user_id, type) DO UPDATE SET enabled = excluded.enabled"},
+		{SQLite, " ON CONFLICT (user_id, type) DO UPDATE SET enabled = excluded.enabled"},
+	}
+
+	for _, c := range cases {
+		if got := c.dialect.upsert([]string{"user_id", "type"}, "enabled"); got != c.want {
+			t.Errorf("Expected '%s', got '%s'", c.want, got)
+		}
+	}
+}
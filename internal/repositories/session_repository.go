@@ -0,0 +1,125 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/achintha-dilshan/go-rest-api/internal/models"
+)
+
+type sessionRepository struct {
+	db *sql.DB
+}
+
+type SessionRepository interface {
+	Create(ctx context.Context, session *models.Session) (int64, error)
+	FindById(ctx context.Context, id int64) (*models.Session, error)
+	FindActiveByUserId(ctx context.Context, userId int64) ([]*models.Session, error)
+	Touch(ctx context.Context, id int64) error
+	Revoke(ctx context.Context, id int64) error
+	RevokeAllForUser(ctx context.Context, userId int64) error
+}
+
+func NewSessionRepository(db *sql.DB) SessionRepository {
+	return &sessionRepository{db: db}
+}
+
+// inserts a new session into the database
+func (r *sessionRepository) Create(ctx context.Context, session *models.Session) (int64, error) {
+	query := "INSERT INTO sessions (user_id, impersonator_id, user_agent, ip_address) VALUES (?, ?, ?, ?)"
+	return insert(ctx, r.db, query, session.UserId, session.ImpersonatorId, session.UserAgent, session.IPAddress)
+}
+
+// retrieves a session by ID
+func (r *sessionRepository) FindById(ctx context.Context, id int64) (*models.Session, error) {
+	query := "SELECT id, user_id, impersonator_id, user_agent, ip_address, last_seen_at, revoked_at, created_at FROM sessions WHERE id = ?"
+	session, err := scanSession(conn(ctx, r.db).QueryRowContext(ctx, query, id))
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+
+	return session, err
+}
+
+// retrieves the sessions of a user that have not been revoked
+func (r *sessionRepository) FindActiveByUserId(ctx context.Context, userId int64) ([]*models.Session, error) {
+	var sessions []*models.Session
+	query := `SELECT id, user_id, impersonator_id, user_agent, ip_address, last_seen_at, revoked_at, created_at FROM sessions
+		WHERE user_id = ? AND revoked_at IS NULL ORDER BY last_seen_at DESC`
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, userId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		session, err := scanSession(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		sessions = append(sessions, session)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return sessions, nil
+}
+
+// updates the last seen time of a session
+func (r *sessionRepository) Touch(ctx context.Context, id int64) error {
+	query := "UPDATE sessions SET last_seen_at = CURRENT_TIMESTAMP WHERE id = ?"
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, id)
+
+	return err
+}
+
+// marks a session as revoked
+func (r *sessionRepository) Revoke(ctx context.Context, id int64) error {
+	query := "UPDATE sessions SET revoked_at = CURRENT_TIMESTAMP WHERE id = ? AND revoked_at IS NULL"
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, id)
+
+	return err
+}
+
+// marks all active sessions of a user as revoked
+func (r *sessionRepository) RevokeAllForUser(ctx context.Context, userId int64) error {
+	query := "UPDATE sessions SET revoked_at = CURRENT_TIMESTAMP WHERE user_id = ? AND revoked_at IS NULL"
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, userId)
+
+	return err
+}
+
+// rowScanner is implemented by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// scanSession reads a single session row
+func scanSession(row rowScanner) (*models.Session, error) {
+	var session models.Session
+	var impersonatorId sql.NullInt64
+	var revokedAt sql.NullTime
+
+	err := row.Scan(
+		&session.Id, &session.UserId, &impersonatorId, &session.UserAgent, &session.IPAddress,
+		&session.LastSeenAt, &revokedAt, &session.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if impersonatorId.Valid {
+		session.ImpersonatorId = &impersonatorId.Int64
+	}
+	if revokedAt.Valid {
+		session.RevokedAt = &revokedAt.Time
+	}
+
+	return &session, nil
+}
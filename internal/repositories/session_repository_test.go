@@ -0,0 +1,57 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+
+	"github.com/achintha-dilshan/go-rest-api/internal/models"
+)
+
+func TestSessionRepository(t *testing.T) {
+	db := newTestDB(t)
+	repo := NewSessionRepository(db)
+	ctx := context.Background()
+
+	userId := createTestUser(t, db)
+	adminId := createTestUser(t, db)
+
+	id, err := repo.Create(ctx, &models.Session{UserId: userId, UserAgent: "curl", IPAddress: "127.0.0.1"})
+	if err != nil {
+		t.Fatalf("Expected no error, got '%v'", err)
+	}
+	impersonated, _ := repo.Create(ctx, &models.Session{UserId: userId, ImpersonatorId: &adminId})
+
+	session, err := repo.FindById(ctx, id)
+	if err != nil || session == nil {
+		t.Fatalf("Expected the session, got '%v' and error '%v'", session, err)
+	}
+	if session.UserAgent != "curl" || session.ImpersonatorId != nil || session.RevokedAt != nil {
+		t.Errorf("Expected the saved session, got '%+v'", session)
+	}
+	if session, _ := repo.FindById(ctx, impersonated); session.ImpersonatorId == nil || *session.ImpersonatorId != adminId {
+		t.Errorf("Expected the session to be impersonated by %d, got '%+v'", adminId, session)
+	}
+
+	if err := repo.Touch(ctx, id); err != nil {
+		t.Fatalf("Expected no error, got '%v'", err)
+	}
+
+	if err := repo.Revoke(ctx, id); err != nil {
+		t.Fatalf("Expected no error, got '%v'", err)
+	}
+	if session, _ := repo.FindById(ctx, id); session.RevokedAt == nil {
+		t.Errorf("Expected the session to be revoked")
+	}
+
+	sessions, err := repo.FindActiveByUserId(ctx, userId)
+	if err != nil || len(sessions) != 1 || sessions[0].Id != impersonated {
+		t.Errorf("Expected only session %d to be active, got '%v' and error '%v'", impersonated, sessions, err)
+	}
+
+	if err := repo.RevokeAllForUser(ctx, userId); err != nil {
+		t.Fatalf("Expected no error, got '%v'", err)
+	}
+	if sessions, _ := repo.FindActiveByUserId(ctx, userId); len(sessions) != 0 {
+		t.Errorf("Expected no active sessions, got %d", len(sessions))
+	}
+}
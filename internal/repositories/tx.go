@@ -0,0 +1,119 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/achintha-dilshan/go-rest-api/internal/utils/backoff"
+)
+
+// how often a transaction is run again after losing a deadlock
+const (
+	txAttempts       = 3
+	txRetryBaseDelay = 20 * time.Millisecond
+	txRetryMaxDelay  = 200 * time.Millisecond
+)
+
+type txKey struct{}
+
+// conn returns the transaction in ctx, or db when there is none, speaking
+// the dialect of db.
+func conn(ctx context.Context, db *sql.DB) querier {
+	var q querier = db
+	if tx, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		q = tx
+	}
+	return dialectQuerier{q: q, dialect: dialectOf(db)}
+}
+
+// forUpdate returns the clause locking the rows a query reads until the
+// transaction in ctx ends, so a row read before being changed cannot change
+// in between. Outside a transaction it is empty.
+func forUpdate(ctx context.Context, db *sql.DB) string {
+	if _, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return dialectOf(db).forUpdate()
+	}
+	return ""
+}
+
+type txManager struct {
+	db *sql.DB
+}
+
+// TxManager runs several repository calls as one unit of work.
+type TxManager interface {
+	WithinTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+func NewTxManager(db *sql.DB) TxManager {
+	return &txManager{db: db}
+}
+
+// run fn in a transaction, committing if it returns nil and rolling back
+// otherwise. Repositories given the context fn receives run their statements
+// in the transaction; inside another transaction, fn simply joins it.
+//
+// A transaction the database rolls back to break a deadlock, or that times
+// out waiting for a lock, is run again from the start, so fn must not have
+// effects outside the database.
+func (m *txManager) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return fn(ctx)
+	}
+
+	var err error
+	for attempt := 1; attempt <= txAttempts; attempt++ {
+		if err = m.run(ctx, fn); !isDeadlock(err) {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(backoff.Jitter(backoff.Exponential(attempt, txRetryBaseDelay, txRetryMaxDelay))):
+		}
+	}
+
+	return err
+}
+
+// run makes a single attempt at a transaction.
+func (m *txManager) run(ctx context.Context, fn func(ctx context.Context) error) error {
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := fn(context.WithValue(ctx, txKey{}, tx)); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// savepoint runs fn so that an error it returns, such as a duplicate key the
+// caller recovers from, leaves the transaction in ctx usable. PostgreSQL
+// refuses every statement after an error until the transaction ends, so fn
+// runs behind a savepoint there.
+func savepoint(ctx context.Context, db *sql.DB, fn func() error) error {
+	tx, ok := ctx.Value(txKey{}).(*sql.Tx)
+	if !ok || dialectOf(db) != Postgres {
+		return fn()
+	}
+
+	if _, err := tx.ExecContext(ctx, "SAVEPOINT statement"); err != nil {
+		return err
+	}
+
+	if err := fn(); err != nil {
+		if _, rollbackErr := tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT statement"); rollbackErr != nil {
+			return rollbackErr
+		}
+		return err
+	}
+
+	_, err := tx.ExecContext(ctx, "RELEASE SAVEPOINT statement")
+	return err
+}
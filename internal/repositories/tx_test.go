@@ -0,0 +1,81 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/achintha-dilshan/go-rest-api/internal/models"
+)
+
+func TestTxManagerCommit(t *testing.T) {
+	db := newTestDB(t)
+	users := NewUserRepository(db)
+	ctx := context.Background()
+
+	var id int64
+	err := NewTxManager(db).WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		id, err = users.Create(ctx, &models.User{Name: "Ada", Email: "ada@example.com", Password: "hash"})
+		return err
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got '%v'", err)
+	}
+
+	if user, _ := users.FindById(ctx, id); user == nil {
+		t.Errorf("Expected the user to be committed")
+	}
+}
+
+func TestTxManagerRollback(t *testing.T) {
+	db := newTestDB(t)
+	users := NewUserRepository(db)
+	tx := NewTxManager(db)
+	ctx := context.Background()
+
+	failure := errors.New("failure")
+	err := tx.WithinTx(ctx, func(ctx context.Context) error {
+		if _, err := users.Create(ctx, &models.User{Name: "Ada", Email: "ada@example.com", Password: "hash"}); err != nil {
+			return err
+		}
+
+		// a nested unit of work joins the outer one
+		return tx.WithinTx(ctx, func(ctx context.Context) error {
+			if _, err := users.Create(ctx, &models.User{Name: "Bob", Email: "bob@example.com", Password: "hash"}); err != nil {
+				return err
+			}
+			return failure
+		})
+	})
+	if !errors.Is(err, failure) {
+		t.Fatalf("Expected '%v', got '%v'", failure, err)
+	}
+
+	if _, total, _ := users.Search(ctx, "", 10, 0); total != 0 {
+		t.Errorf("Expected no users after the rollback, got %d", total)
+	}
+}
+
+func TestTxManagerDuplicateKeepsTx(t *testing.T) {
+	db := newTestDB(t)
+	follows := NewFollowRepository(db)
+	ctx := context.Background()
+
+	followerId := createTestUser(t, db)
+	followeeId := createTestUser(t, db)
+
+	err := NewTxManager(db).WithinTx(ctx, func(ctx context.Context) error {
+		follows.Follow(ctx, followerId, followeeId)
+		if followed, err := follows.Follow(ctx, followerId, followeeId); err != nil || followed {
+			t.Errorf("Expected a repeated follow to be ignored, got '%v' and error '%v'", followed, err)
+		}
+
+		// the transaction is still usable after the duplicate
+		_, err := follows.IsFollowing(ctx, followerId, followeeId)
+		return err
+	})
+	if err != nil {
+		t.Errorf("Expected no error, got '%v'", err)
+	}
+}
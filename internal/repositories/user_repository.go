@@ -4,7 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"errors"
-	"fmt"
+	"strings"
 
 	"github.com/achintha-dilshan/go-rest-api/internal/models"
 )
@@ -20,45 +20,40 @@ type UserRepository interface {
 	Delete(ctx context.Context, id int64) error
 	ExistsByEmail(ctx context.Context, email string) (bool, error)
 	FindByEmail(ctx context.Context, email string) (*models.User, error)
+	Search(ctx context.Context, term string, limit, offset int) ([]*models.User, int64, error)
+	SetSuspended(ctx context.Context, id int64, suspended bool) error
+	SetPasswordResetRequired(ctx context.Context, id int64, required bool) error
+	SetRole(ctx context.Context, id int64, role string) error
 }
 
 func NewUserRepository(db *sql.DB) UserRepository {
 	return &userRepository{db: db}
 }
 
+const userColumns = "id, name, email, bio, avatar_url, password, role, suspended_at, password_reset_required, created_at, updated_at"
+
 // inserts a new user into the database
 func (r *userRepository) Create(ctx context.Context, user *models.User) (int64, error) {
 	query := "INSERT INTO users (name, email, password) VALUES (?, ?, ?)"
-	result, err := r.db.ExecContext(ctx, query, user.Name, user.Email, user.Password)
-
-	if err != nil {
-		return 0, err
-	}
-
-	return result.LastInsertId()
+	return insert(ctx, r.db, query, user.Name, user.Email, user.Password)
 }
 
-// retrieves a user by ID
+// retrieves a user by ID, locking it when called in a transaction
 func (r *userRepository) FindById(ctx context.Context, id int64) (*models.User, error) {
-	var user models.User
-	query := "SELECT id, name, email, password FROM users WHERE id = ?"
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
-		&user.Id, &user.Name, &user.Email, &user.Password,
-	)
+	query := "SELECT " + userColumns + " FROM users WHERE id = ?" + forUpdate(ctx, r.db)
+	user, err := scanUser(conn(ctx, r.db).QueryRowContext(ctx, query, id))
 
 	if errors.Is(err, sql.ErrNoRows) {
 		return nil, nil
 	}
 
-	return &user, err
+	return user, err
 }
 
 // updates a user's details in the database
 func (r *userRepository) Update(ctx context.Context, user *models.User) error {
-	query := "UPDATE users SET name = ?, email = ?, password = ?, updated_at = NOW() WHERE id = ?"
-	re, err := r.db.ExecContext(ctx, query, user.Name, user.Email, user.Password, user.Id)
-
-	fmt.Println(re.LastInsertId())
+	query := "UPDATE users SET name = ?, email = ?, bio = ?, avatar_url = ?, password = ?, password_reset_required = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?"
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, user.Name, user.Email, user.Bio, user.AvatarURL, user.Password, user.PasswordResetRequired, user.Id)
 
 	return err
 }
@@ -66,31 +61,123 @@ func (r *userRepository) Update(ctx context.Context, user *models.User) error {
 // removes a user from the database
 func (r *userRepository) Delete(ctx context.Context, id int64) error {
 	query := "DELETE FROM users WHERE id = ?"
-	_, err := r.db.ExecContext(ctx, query, id)
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, id)
 
 	return err
 }
 
 // checks if a user exists by email
 func (r *userRepository) ExistsByEmail(ctx context.Context, email string) (bool, error) {
-	var exists int
+	var exists bool
 	query := "SELECT EXISTS(SELECT 1 FROM users WHERE email = ?)"
-	err := r.db.QueryRowContext(ctx, query, email).Scan(&exists)
+	err := conn(ctx, r.db).QueryRowContext(ctx, query, email).Scan(&exists)
 
-	return exists == 1, err
+	return exists, err
 }
 
 // retrieves a user by email
 func (r *userRepository) FindByEmail(ctx context.Context, email string) (*models.User, error) {
-	var user models.User
-	query := "SELECT id, name, email, password FROM users WHERE email = ?"
-	err := r.db.QueryRowContext(ctx, query, email).Scan(
-		&user.Id, &user.Name, &user.Email, &user.Password,
-	)
+	query := "SELECT " + userColumns + " FROM users WHERE email = ?"
+	user, err := scanUser(conn(ctx, r.db).QueryRowContext(ctx, query, email))
 
 	if errors.Is(err, sql.ErrNoRows) {
 		return nil, nil
 	}
 
-	return &user, err
+	return user, err
+}
+
+// retrieves a page of users whose name or email contains the term, along with the total number of matches
+func (r *userRepository) Search(ctx context.Context, term string, limit, offset int) ([]*models.User, int64, error) {
+	var users []*models.User
+	var total int64
+
+	where := ""
+	var args []any
+	if term != "" {
+		like := dialectOf(r.db).like()
+		where = " WHERE name " + like + " ? ESCAPE '!' OR email " + like + " ? ESCAPE '!'"
+		pattern := "%" + escapeLike(term) + "%"
+		args = append(args, pattern, pattern)
+	}
+
+	if err := conn(ctx, r.db).QueryRowContext(ctx, "SELECT COUNT(*) FROM users"+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query := "SELECT " + userColumns + " FROM users" + where + " ORDER BY id LIMIT ? OFFSET ?"
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, append(args, limit, offset)...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		users = append(users, user)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return users, total, nil
+}
+
+// suspends or unsuspends a user
+func (r *userRepository) SetSuspended(ctx context.Context, id int64, suspended bool) error {
+	query := "UPDATE users SET suspended_at = NULL WHERE id = ?"
+	if suspended {
+		query = "UPDATE users SET suspended_at = CURRENT_TIMESTAMP WHERE id = ? AND suspended_at IS NULL"
+	}
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, id)
+
+	return err
+}
+
+// sets whether a user has to change their password before doing anything else
+func (r *userRepository) SetPasswordResetRequired(ctx context.Context, id int64, required bool) error {
+	query := "UPDATE users SET password_reset_required = ? WHERE id = ?"
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, required, id)
+
+	return err
+}
+
+// changes the role of a user
+func (r *userRepository) SetRole(ctx context.Context, id int64, role string) error {
+	query := "UPDATE users SET role = ? WHERE id = ?"
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, role, id)
+
+	return err
+}
+
+// scanUser reads a single user row
+func scanUser(row rowScanner) (*models.User, error) {
+	var user models.User
+	var suspendedAt, updatedAt sql.NullTime
+
+	err := row.Scan(
+		&user.Id, &user.Name, &user.Email, &user.Bio, &user.AvatarURL, &user.Password, &user.Role,
+		&suspendedAt, &user.PasswordResetRequired, &user.CreatedAt, &updatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if suspendedAt.Valid {
+		user.SuspendedAt = &suspendedAt.Time
+	}
+	user.UpdatedAt = updatedAt.Time
+
+	return &user, nil
+}
+
+// escapeLike escapes the wildcard characters of a LIKE pattern with '!',
+// which every dialect accepts as an escape character
+func escapeLike(value string) string {
+	return strings.NewReplacer("!", "!!", "%", "!%", "_", "!_").Replace(value)
 }
@@ -0,0 +1,171 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+
+	"github.com/achintha-dilshan/go-rest-api/internal/models"
+)
+
+func TestUserRepositoryCreateAndFind(t *testing.T) {
+	db := newTestDB(t)
+	repo := NewUserRepository(db)
+	ctx := context.Background()
+
+	id, err := repo.Create(ctx, &models.User{Name: "Ada", Email: "ada@example.com", Password: "hash"})
+	if err != nil {
+		t.Fatalf("Expected no error, got '%v'", err)
+	}
+
+	user, err := repo.FindById(ctx, id)
+	if err != nil || user == nil {
+		t.Fatalf("Expected the user, got '%v' and error '%v'", user, err)
+	}
+	if user.Email != "ada@example.com" {
+		t.Errorf("Expected '%s', got '%s'", "ada@example.com", user.Email)
+	}
+	if user.Role != models.RoleUser {
+		t.Errorf("Expected '%s', got '%s'", models.RoleUser, user.Role)
+	}
+	if user.CreatedAt.IsZero() {
+		t.Errorf("Expected the creation time to be set")
+	}
+
+	byEmail, err := repo.FindByEmail(ctx, "ada@example.com")
+	if err != nil || byEmail == nil || byEmail.Id != id {
+		t.Errorf("Expected user %d by email, got '%v' and error '%v'", id, byEmail, err)
+	}
+
+	if exists, _ := repo.ExistsByEmail(ctx, "ada@example.com"); !exists {
+		t.Errorf("Expected the email to exist")
+	}
+	if exists, _ := repo.ExistsByEmail(ctx, "bob@example.com"); exists {
+		t.Errorf("Expected the email not to exist")
+	}
+
+	missing, err := repo.FindById(ctx, id+1)
+	if err != nil || missing != nil {
+		t.Errorf("Expected no user, got '%v' and error '%v'", missing, err)
+	}
+}
+
+func TestUserRepositoryUpdate(t *testing.T) {
+	db := newTestDB(t)
+	repo := NewUserRepository(db)
+	ctx := context.Background()
+
+	user, _ := repo.FindById(ctx, createTestUser(t, db))
+	user.Name = "Grace"
+	user.Bio = "Compilers."
+	user.PasswordResetRequired = true
+	if err := repo.Update(ctx, user); err != nil {
+		t.Fatalf("Expected no error, got '%v'", err)
+	}
+
+	updated, _ := repo.FindById(ctx, user.Id)
+	if updated.Name != "Grace" || updated.Bio != "Compilers." || !updated.PasswordResetRequired {
+		t.Errorf("Expected the changes to be saved, got '%+v'", updated)
+	}
+	if updated.UpdatedAt.IsZero() {
+		t.Errorf("Expected the update time to be set")
+	}
+}
+
+func TestUserRepositoryDelete(t *testing.T) {
+	db := newTestDB(t)
+	repo := NewUserRepository(db)
+	ctx := context.Background()
+
+	id := createTestUser(t, db)
+	postId := createTestPost(t, db, id, "hello")
+
+	if err := repo.Delete(ctx, id); err != nil {
+		t.Fatalf("Expected no error, got '%v'", err)
+	}
+
+	if user, _ := repo.FindById(ctx, id); user != nil {
+		t.Errorf("Expected the user to be deleted")
+	}
+	if post, _ := NewPostRepository(db).FindById(ctx, postId); post != nil {
+		t.Errorf("Expected the user's posts to be deleted with them")
+	}
+}
+
+func TestUserRepositorySearch(t *testing.T) {
+	db := newTestDB(t)
+	repo := NewUserRepository(db)
+	ctx := context.Background()
+
+	for _, user := range []*models.User{
+		{Name: "Ada Lovelace", Email: "ada@example.com", Password: "hash"},
+		{Name: "Grace Hopper", Email: "grace@example.com", Password: "hash"},
+		{Name: "100% Ada", Email: "percent@example.com", Password: "hash"},
+	} {
+		if _, err := repo.Create(ctx, user); err != nil {
+			t.Fatalf("Failed to create a user: %v", err)
+		}
+	}
+
+	cases := []struct {
+		term string
+		want int64
+	}{
+		{"", 3},
+		{"ada", 2},
+		{"GRACE", 1},
+		{"%", 1},
+		{"_", 0},
+		{"nobody", 0},
+	}
+
+	for _, c := range cases {
+		users, total, err := repo.Search(ctx, c.term, 10, 0)
+		if err != nil {
+			t.Fatalf("Expected no error, got '%v'", err)
+		}
+		if total != c.want || int64(len(users)) != c.want {
+			t.Errorf("Expected %d users for '%s', got %d of %d", c.want, c.term, len(users), total)
+		}
+	}
+
+	users, total, _ := repo.Search(ctx, "", 2, 2)
+	if total != 3 || len(users) != 1 {
+		t.Errorf("Expected the last of 3 users, got %d of %d", len(users), total)
+	}
+}
+
+func TestUserRepositorySetSuspended(t *testing.T) {
+	db := newTestDB(t)
+	repo := NewUserRepository(db)
+	ctx := context.Background()
+
+	id := createTestUser(t, db)
+
+	if err := repo.SetSuspended(ctx, id, true); err != nil {
+		t.Fatalf("Expected no error, got '%v'", err)
+	}
+	if user, _ := repo.FindById(ctx, id); user.SuspendedAt == nil {
+		t.Errorf("Expected the user to be suspended")
+	}
+
+	if err := repo.SetSuspended(ctx, id, false); err != nil {
+		t.Fatalf("Expected no error, got '%v'", err)
+	}
+	if user, _ := repo.FindById(ctx, id); user.SuspendedAt != nil {
+		t.Errorf("Expected the user not to be suspended")
+	}
+}
+
+func TestUserRepositoryEmailIgnoresCase(t *testing.T) {
+	db := newTestDB(t)
+	repo := NewUserRepository(db)
+	ctx := context.Background()
+
+	if _, err := repo.Create(ctx, &models.User{Name: "Ada", Email: "ada@example.com", Password: "hash"}); err != nil {
+		t.Fatalf("Expected no error, got '%v'", err)
+	}
+
+	if _, err := repo.Create(ctx, &models.User{Name: "Ada", Email: "Ada@Example.com", Password: "hash"}); err == nil {
+		t.Errorf("Expected an email differing only in case to be rejected")
+	}
+}
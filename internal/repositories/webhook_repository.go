@@ -0,0 +1,403 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/achintha-dilshan/go-rest-api/internal/models"
+)
+
+type webhookRepository struct {
+	db *sql.DB
+}
+
+type WebhookRepository interface {
+	Create(ctx context.Context, webhook *models.Webhook) (int64, error)
+	FindById(ctx context.Context, id int64) (*models.Webhook, error)
+	FindByUserId(ctx context.Context, userId int64) ([]*models.Webhook, error)
+	Update(ctx context.Context, webhook *models.Webhook) error
+	Delete(ctx context.Context, id int64) error
+	CreateDeliveries(ctx context.Context, event string, payload []byte) (int64, error)
+	FindDeliveries(ctx context.Context, webhookId int64, limit, offset int) ([]*models.WebhookDelivery, int64, error)
+	ClaimDeliveries(ctx context.Context, limit int, lease time.Duration) ([]*models.WebhookDelivery, error)
+	RecordAttempt(ctx context.Context, delivery *models.WebhookDelivery, retryIn time.Duration) error
+	RecordSuccess(ctx context.Context, webhookId int64) error
+	RecordFailure(ctx context.Context, webhookId int64, disableAfter int) (bool, error)
+}
+
+func NewWebhookRepository(db *sql.DB) WebhookRepository {
+	return &webhookRepository{db: db}
+}
+
+const webhookColumns = "id, user_id, url, secret, active, failure_count, disabled_at, created_at, updated_at"
+
+const deliveryColumns = "d.id, d.webhook_id, d.event, d.payload, d.status, d.attempts, d.response_code, d.error, d.duration_ms, d.next_attempt_at, d.delivered_at, d.created_at"
+
+// inserts a new webhook along with the events it subscribes to
+func (r *webhookRepository) Create(ctx context.Context, webhook *models.Webhook) (int64, error) {
+	var id int64
+
+	err := NewTxManager(r.db).WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		query := "INSERT INTO webhooks (user_id, url, secret) VALUES (?, ?, ?)"
+		if id, err = insert(ctx, r.db, query, webhook.UserId, webhook.URL, webhook.Secret); err != nil {
+			return err
+		}
+
+		return r.replaceEvents(ctx, id, webhook.Events)
+	})
+
+	return id, err
+}
+
+// retrieves a webhook by ID
+func (r *webhookRepository) FindById(ctx context.Context, id int64) (*models.Webhook, error) {
+	query := "SELECT " + webhookColumns + " FROM webhooks WHERE id = ?"
+	webhook, err := scanWebhook(conn(ctx, r.db).QueryRowContext(ctx, query, id))
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return webhook, r.attachEvents(ctx, webhook)
+}
+
+// retrieves the webhooks of a user
+func (r *webhookRepository) FindByUserId(ctx context.Context, userId int64) ([]*models.Webhook, error) {
+	var webhooks []*models.Webhook
+	query := "SELECT " + webhookColumns + " FROM webhooks WHERE user_id = ? ORDER BY id"
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, userId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		webhook, err := scanWebhook(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		webhooks = append(webhooks, webhook)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return webhooks, r.attachEvents(ctx, webhooks...)
+}
+
+// updates a webhook and replaces the events it subscribes to
+func (r *webhookRepository) Update(ctx context.Context, webhook *models.Webhook) error {
+	return NewTxManager(r.db).WithinTx(ctx, func(ctx context.Context) error {
+		query := "UPDATE webhooks SET url = ?, active = ?, failure_count = ?, disabled_at = ? WHERE id = ?"
+		if _, err := conn(ctx, r.db).ExecContext(ctx, query, webhook.URL, webhook.Active, webhook.FailureCount, webhook.DisabledAt, webhook.Id); err != nil {
+			return err
+		}
+
+		return r.replaceEvents(ctx, webhook.Id, webhook.Events)
+	})
+}
+
+// deletes a webhook and its deliveries
+func (r *webhookRepository) Delete(ctx context.Context, id int64) error {
+	query := "DELETE FROM webhooks WHERE id = ?"
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// queues an event for every active webhook subscribed to it, returning how
+// many deliveries were queued
+func (r *webhookRepository) CreateDeliveries(ctx context.Context, event string, payload []byte) (int64, error) {
+	query := "INSERT INTO webhook_deliveries (webhook_id, event, payload) " +
+		"SELECT w.id, e.event, ? FROM webhooks w JOIN webhook_events e ON e.webhook_id = w.id " +
+		"WHERE e.event = ? AND w.active = TRUE"
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, string(payload), event)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+// retrieves the deliveries of a webhook, newest first
+func (r *webhookRepository) FindDeliveries(ctx context.Context, webhookId int64, limit, offset int) ([]*models.WebhookDelivery, int64, error) {
+	var deliveries []*models.WebhookDelivery
+	var total int64
+
+	if err := conn(ctx, r.db).QueryRowContext(ctx, "SELECT COUNT(*) FROM webhook_deliveries WHERE webhook_id = ?", webhookId).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query := "SELECT " + deliveryColumns + " FROM webhook_deliveries d WHERE d.webhook_id = ? ORDER BY d.id DESC LIMIT ? OFFSET ?"
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, webhookId, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		delivery, err := scanDelivery(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		deliveries = append(deliveries, delivery)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return deliveries, total, nil
+}
+
+// claims up to limit deliveries that are due, along with their endpoints.
+// Claimed deliveries are not due again until the lease runs out, so other
+// workers skip them, and a worker that dies mid-attempt only delays them.
+func (r *webhookRepository) ClaimDeliveries(ctx context.Context, limit int, lease time.Duration) ([]*models.WebhookDelivery, error) {
+	var deliveries []*models.WebhookDelivery
+
+	err := NewTxManager(r.db).WithinTx(ctx, func(ctx context.Context) error {
+		deliveries = nil
+		now := time.Now().UTC()
+
+		query := "SELECT d.id FROM webhook_deliveries d JOIN webhooks w ON w.id = d.webhook_id " +
+			"WHERE d.status = ? AND d.next_attempt_at <= ? AND w.active = TRUE " +
+			"ORDER BY d.next_attempt_at LIMIT ?" + dialectOf(r.db).skipLocked("d")
+		rows, err := conn(ctx, r.db).QueryContext(ctx, query, models.DeliveryPending, now, limit)
+		if err != nil {
+			return err
+		}
+
+		var ids []any
+		for rows.Next() {
+			var id int64
+			if err := rows.Scan(&id); err != nil {
+				rows.Close()
+				return err
+			}
+			ids = append(ids, id)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		if len(ids) == 0 {
+			return nil
+		}
+
+		placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(ids)), ", ")
+		query = "UPDATE webhook_deliveries SET next_attempt_at = ? WHERE id IN (" + placeholders + ")"
+		if _, err := conn(ctx, r.db).ExecContext(ctx, query, append([]any{now.Add(lease)}, ids...)...); err != nil {
+			return err
+		}
+
+		query = "SELECT " + deliveryColumns + ", w.url, w.secret FROM webhook_deliveries d JOIN webhooks w ON w.id = d.webhook_id " +
+			"WHERE d.id IN (" + placeholders + ") ORDER BY d.id"
+		rows, err = conn(ctx, r.db).QueryContext(ctx, query, ids...)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var url, secret string
+			delivery, err := scanDelivery(rows, &url, &secret)
+			if err != nil {
+				return err
+			}
+			delivery.URL, delivery.Secret = url, secret
+
+			deliveries = append(deliveries, delivery)
+		}
+
+		return rows.Err()
+	})
+
+	return deliveries, err
+}
+
+// saves the outcome of an attempt. A delivery still pending is retried after retryIn.
+func (r *webhookRepository) RecordAttempt(ctx context.Context, delivery *models.WebhookDelivery, retryIn time.Duration) error {
+	var nextAttemptAt, deliveredAt *time.Time
+	now := time.Now().UTC()
+	switch delivery.Status {
+	case models.DeliveryPending:
+		next := now.Add(retryIn)
+		nextAttemptAt = &next
+	case models.DeliverySucceeded:
+		deliveredAt = &now
+	}
+
+	query := "UPDATE webhook_deliveries SET status = ?, attempts = ?, response_code = ?, error = ?, duration_ms = ?, " +
+		"next_attempt_at = ?, delivered_at = ? WHERE id = ?"
+	_, err := conn(ctx, r.db).ExecContext(ctx, query,
+		delivery.Status, delivery.Attempts, delivery.ResponseCode, delivery.Error, delivery.DurationMs,
+		nextAttemptAt, deliveredAt, delivery.Id,
+	)
+
+	return err
+}
+
+// clears the failure count of a webhook after a successful delivery
+func (r *webhookRepository) RecordSuccess(ctx context.Context, webhookId int64) error {
+	query := "UPDATE webhooks SET failure_count = 0 WHERE id = ? AND failure_count > 0"
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, webhookId)
+
+	return err
+}
+
+// counts a failed attempt against a webhook. Once disableAfter attempts in a
+// row have failed the webhook is disabled and its pending deliveries are
+// given up on; it reports whether that happened.
+func (r *webhookRepository) RecordFailure(ctx context.Context, webhookId int64, disableAfter int) (bool, error) {
+	var disabled int64
+
+	err := NewTxManager(r.db).WithinTx(ctx, func(ctx context.Context) error {
+		query := "UPDATE webhooks SET failure_count = failure_count + 1 WHERE id = ?"
+		if _, err := conn(ctx, r.db).ExecContext(ctx, query, webhookId); err != nil {
+			return err
+		}
+
+		query = "UPDATE webhooks SET active = FALSE, disabled_at = CURRENT_TIMESTAMP WHERE id = ? AND active = TRUE AND failure_count >= ?"
+		result, err := conn(ctx, r.db).ExecContext(ctx, query, webhookId, disableAfter)
+		if err != nil {
+			return err
+		}
+
+		if disabled, err = result.RowsAffected(); err != nil || disabled == 0 {
+			return err
+		}
+
+		query = "UPDATE webhook_deliveries SET status = ?, error = ?, next_attempt_at = NULL WHERE webhook_id = ? AND status = ?"
+		_, err = conn(ctx, r.db).ExecContext(ctx, query, models.DeliveryFailed, "Webhook disabled after repeated failures.", webhookId, models.DeliveryPending)
+		return err
+	})
+
+	return disabled > 0, err
+}
+
+// attachEvents fills in the events each webhook subscribes to.
+func (r *webhookRepository) attachEvents(ctx context.Context, webhooks ...*models.Webhook) error {
+	if len(webhooks) == 0 {
+		return nil
+	}
+
+	byId := make(map[int64]*models.Webhook, len(webhooks))
+	ids := make([]any, 0, len(webhooks))
+	for _, webhook := range webhooks {
+		webhook.Events = []string{}
+		byId[webhook.Id] = webhook
+		ids = append(ids, webhook.Id)
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(ids)), ", ")
+	query := "SELECT webhook_id, event FROM webhook_events WHERE webhook_id IN (" + placeholders + ") ORDER BY event"
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, ids...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var webhookId int64
+		var event string
+		if err := rows.Scan(&webhookId, &event); err != nil {
+			return err
+		}
+
+		byId[webhookId].Events = append(byId[webhookId].Events, event)
+	}
+
+	return rows.Err()
+}
+
+// replaceEvents sets the events a webhook subscribes to.
+func (r *webhookRepository) replaceEvents(ctx context.Context, webhookId int64, events []string) error {
+	if _, err := conn(ctx, r.db).ExecContext(ctx, "DELETE FROM webhook_events WHERE webhook_id = ?", webhookId); err != nil {
+		return err
+	}
+
+	for _, event := range events {
+		if _, err := conn(ctx, r.db).ExecContext(ctx, "INSERT INTO webhook_events (webhook_id, event) VALUES (?, ?)", webhookId, event); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// scanWebhook reads a single webhook row
+func scanWebhook(row rowScanner) (*models.Webhook, error) {
+	var webhook models.Webhook
+	var disabledAt, updatedAt sql.NullTime
+
+	err := row.Scan(&webhook.Id, &webhook.UserId, &webhook.URL, &webhook.Secret, &webhook.Active, &webhook.FailureCount, &disabledAt, &webhook.CreatedAt, &updatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if disabledAt.Valid {
+		webhook.DisabledAt = &disabledAt.Time
+	}
+	if updatedAt.Valid {
+		webhook.UpdatedAt = &updatedAt.Time
+	}
+
+	return &webhook, nil
+}
+
+// scanDelivery reads a single delivery row, along with any extra columns
+// selected after it
+func scanDelivery(row rowScanner, extra ...any) (*models.WebhookDelivery, error) {
+	var delivery models.WebhookDelivery
+	var responseCode, durationMs sql.NullInt64
+	var nextAttemptAt, deliveredAt sql.NullTime
+	var payload []byte
+
+	dest := append([]any{&delivery.Id, &delivery.WebhookId, &delivery.Event, &payload, &delivery.Status, &delivery.Attempts,
+		&responseCode, &delivery.Error, &durationMs, &nextAttemptAt, &deliveredAt, &delivery.CreatedAt}, extra...)
+	if err := row.Scan(dest...); err != nil {
+		return nil, err
+	}
+
+	delivery.Payload = payload
+	if responseCode.Valid {
+		code := int(responseCode.Int64)
+		delivery.ResponseCode = &code
+	}
+	if durationMs.Valid {
+		delivery.DurationMs = &durationMs.Int64
+	}
+	if nextAttemptAt.Valid {
+		delivery.NextAttemptAt = &nextAttemptAt.Time
+	}
+	if deliveredAt.Valid {
+		delivery.DeliveredAt = &deliveredAt.Time
+	}
+
+	return &delivery, nil
+}
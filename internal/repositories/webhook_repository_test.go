@@ -0,0 +1,139 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/achintha-dilshan/go-rest-api/internal/models"
+)
+
+func TestWebhookRepository(t *testing.T) {
+	db := newTestDB(t)
+	repo := NewWebhookRepository(db)
+	ctx := context.Background()
+
+	userId := createTestUser(t, db)
+
+	webhook := &models.Webhook{UserId: userId, URL: "https://example.com/hook", Secret: "secret", Events: []string{"post.created", "post.deleted"}}
+	id, err := repo.Create(ctx, webhook)
+	if err != nil {
+		t.Fatalf("Expected no error, got '%v'", err)
+	}
+
+	saved, err := repo.FindById(ctx, id)
+	if err != nil || saved == nil {
+		t.Fatalf("Expected the webhook, got '%v' and error '%v'", saved, err)
+	}
+	if !saved.Active || len(saved.Events) != 2 {
+		t.Errorf("Expected an active webhook with 2 events, got '%+v'", saved)
+	}
+
+	saved.URL = "https://example.com/other"
+	saved.Events = []string{"post.updated"}
+	if err := repo.Update(ctx, saved); err != nil {
+		t.Fatalf("Expected no error, got '%v'", err)
+	}
+
+	webhooks, err := repo.FindByUserId(ctx, userId)
+	if err != nil || len(webhooks) != 1 {
+		t.Fatalf("Expected 1 webhook, got %d and error '%v'", len(webhooks), err)
+	}
+	if got := webhooks[0]; got.URL != "https://example.com/other" || len(got.Events) != 1 || got.Events[0] != "post.updated" {
+		t.Errorf("Expected the changes to be saved, got '%+v'", got)
+	}
+
+	if err := repo.Delete(ctx, id); err != nil {
+		t.Fatalf("Expected no error, got '%v'", err)
+	}
+	if err := repo.Delete(ctx, id); err == nil {
+		t.Errorf("Expected deleting a missing webhook to fail")
+	}
+}
+
+func TestWebhookRepositoryDeliveries(t *testing.T) {
+	db := newTestDB(t)
+	repo := NewWebhookRepository(db)
+	ctx := context.Background()
+
+	userId := createTestUser(t, db)
+	id, _ := repo.Create(ctx, &models.Webhook{UserId: userId, URL: "https://example.com/hook", Secret: "secret", Events: []string{"post.created"}})
+	repo.Create(ctx, &models.Webhook{UserId: userId, URL: "https://example.com/other", Secret: "secret", Events: []string{"post.deleted"}})
+
+	queued, err := repo.CreateDeliveries(ctx, "post.created", []byte(`{"id":1}`))
+	if err != nil || queued != 1 {
+		t.Fatalf("Expected 1 delivery queued, got %d and error '%v'", queued, err)
+	}
+
+	deliveries, err := repo.ClaimDeliveries(ctx, 10, time.Minute)
+	if err != nil || len(deliveries) != 1 {
+		t.Fatalf("Expected 1 delivery, got %d and error '%v'", len(deliveries), err)
+	}
+	delivery := deliveries[0]
+	if delivery.WebhookId != id || delivery.URL != "https://example.com/hook" || string(delivery.Payload) != `{"id":1}` {
+		t.Errorf("Expected the queued delivery, got '%+v'", delivery)
+	}
+
+	if deliveries, _ := repo.ClaimDeliveries(ctx, 10, time.Minute); len(deliveries) != 0 {
+		t.Errorf("Expected a claimed delivery not to be claimed again, got %d", len(deliveries))
+	}
+
+	code := 500
+	delivery.Attempts = 1
+	delivery.ResponseCode = &code
+	if err := repo.RecordAttempt(ctx, delivery, -time.Second); err != nil {
+		t.Fatalf("Expected no error, got '%v'", err)
+	}
+	if deliveries, _ := repo.ClaimDeliveries(ctx, 10, time.Minute); len(deliveries) != 1 || deliveries[0].Attempts != 1 {
+		t.Errorf("Expected the delivery to be retried, got '%v'", deliveries)
+	}
+
+	delivery.Status = models.DeliverySucceeded
+	delivery.Attempts = 2
+	if err := repo.RecordAttempt(ctx, delivery, 0); err != nil {
+		t.Fatalf("Expected no error, got '%v'", err)
+	}
+
+	deliveries, total, err := repo.FindDeliveries(ctx, id, 10, 0)
+	if err != nil || total != 1 {
+		t.Fatalf("Expected 1 delivery, got %d and error '%v'", total, err)
+	}
+	if got := deliveries[0]; got.Status != models.DeliverySucceeded || got.DeliveredAt == nil || got.NextAttemptAt != nil {
+		t.Errorf("Expected a delivered delivery, got '%+v'", got)
+	}
+}
+
+func TestWebhookRepositoryRecordFailure(t *testing.T) {
+	db := newTestDB(t)
+	repo := NewWebhookRepository(db)
+	ctx := context.Background()
+
+	userId := createTestUser(t, db)
+	id, _ := repo.Create(ctx, &models.Webhook{UserId: userId, URL: "https://example.com/hook", Secret: "secret", Events: []string{"post.created"}})
+	repo.CreateDeliveries(ctx, "post.created", []byte(`{"id":1}`))
+
+	if disabled, err := repo.RecordFailure(ctx, id, 2); err != nil || disabled {
+		t.Errorf("Expected the webhook to stay active, got '%v' and error '%v'", disabled, err)
+	}
+	if err := repo.RecordSuccess(ctx, id); err != nil {
+		t.Fatalf("Expected no error, got '%v'", err)
+	}
+	if webhook, _ := repo.FindById(ctx, id); webhook.FailureCount != 0 {
+		t.Errorf("Expected '%d', got '%d'", 0, webhook.FailureCount)
+	}
+
+	repo.RecordFailure(ctx, id, 2)
+	if disabled, err := repo.RecordFailure(ctx, id, 2); err != nil || !disabled {
+		t.Errorf("Expected the webhook to be disabled, got '%v' and error '%v'", disabled, err)
+	}
+
+	webhook, _ := repo.FindById(ctx, id)
+	if webhook.Active || webhook.DisabledAt == nil {
+		t.Errorf("Expected a disabled webhook, got '%+v'", webhook)
+	}
+
+	deliveries, _, _ := repo.FindDeliveries(ctx, id, 10, 0)
+	if len(deliveries) != 1 || deliveries[0].Status != models.DeliveryFailed {
+		t.Errorf("Expected the pending delivery to have failed, got '%v'", deliveries)
+	}
+}
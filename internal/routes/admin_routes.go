@@ -0,0 +1,58 @@
+package routes
+
+import (
+	"database/sql"
+
+	"github.com/achintha-dilshan/go-rest-api/internal/handlers"
+	"github.com/achintha-dilshan/go-rest-api/internal/middlewares"
+	"github.com/achintha-dilshan/go-rest-api/internal/services"
+	"github.com/go-chi/chi/v5"
+)
+
+type adminRoutes struct {
+	services *services.Services
+	db       *sql.DB
+}
+
+type AdminRoutes interface {
+	Get() *chi.Mux
+}
+
+func NewAdminRoutes(services *services.Services, db *sql.DB) AdminRoutes {
+	return &adminRoutes{
+		services: services,
+		db:       db,
+	}
+}
+
+func (r *adminRoutes) Get() *chi.Mux {
+	router := chi.NewRouter()
+
+	s := r.services
+	handler := handlers.NewAdminHandler(s.User, s.Post, s.Session, s.Audit)
+	jobHandler := handlers.NewJobHandler(s.Job, s.Audit)
+	databaseHandler := handlers.NewDatabaseHandler(r.db.Stats)
+
+	router.Use(middlewares.NewAuthMiddleware(s.Session, s.User).Authenticate)
+	router.Use(middlewares.RequireAdmin)
+
+	router.Get("/users", handler.GetUsers)
+	router.Get("/users/{id}", handler.GetUser)
+	router.Get("/users/{id}/posts", handler.GetUserPosts)
+	router.Get("/users/{id}/sessions", handler.GetUserSessions)
+	router.Post("/users/{id}/suspend", handler.SuspendUser)
+	router.Post("/users/{id}/unsuspend", handler.UnsuspendUser)
+	router.Post("/users/{id}/force-password-reset", handler.ForcePasswordReset)
+	router.Delete("/users/{id}", handler.DeleteUser)
+	router.Post("/users/{id}/impersonate", handler.Impersonate)
+
+	router.Get("/audit-logs", handler.GetAuditLogs)
+
+	router.Get("/jobs", jobHandler.GetJobs)
+	router.Get("/jobs/{id}", jobHandler.GetJob)
+	router.Post("/jobs/{id}/retry", jobHandler.RetryJob)
+
+	router.Get("/database/stats", databaseHandler.GetStats)
+
+	return router
+}
@@ -1,35 +1,39 @@
 package routes
 
 import (
-	"database/sql"
-
 	"github.com/achintha-dilshan/go-rest-api/internal/handlers"
-	"github.com/achintha-dilshan/go-rest-api/internal/repositories"
+	"github.com/achintha-dilshan/go-rest-api/internal/middlewares"
 	"github.com/achintha-dilshan/go-rest-api/internal/services"
+	"github.com/achintha-dilshan/go-rest-api/internal/utils/password"
 	"github.com/go-chi/chi/v5"
 )
 
 type authRoutes struct {
-	db *sql.DB
+	services *services.Services
 }
 
 type AuthRoutes interface {
 	Get() *chi.Mux
 }
 
-func NewAuthRoutes(db *sql.DB) AuthRoutes {
-	return &authRoutes{db: db}
+func NewAuthRoutes(services *services.Services) AuthRoutes {
+	return &authRoutes{services: services}
 }
 
 func (r *authRoutes) Get() *chi.Mux {
 	router := chi.NewRouter()
 
-	repo := repositories.NewUserRepository(r.db)
-	service := services.NewUserService(repo)
-	handler := handlers.NewAuthHandler(service)
+	s := r.services
+	handler := handlers.NewAuthHandler(s.User, s.Session, s.MagicLink, s.Job, password.PolicyFromConfig(), password.HasherFromConfig())
+	passkeyHandler := handlers.NewPasskeyHandler(s.Passkey, s.User, s.Session, newWebAuthn())
 
 	router.Post("/login", handler.LoginUser)
 	router.Post("/register", handler.RegisterUser)
+	router.With(middlewares.NewAuthMiddleware(s.Session, s.User).AuthenticatePendingReset).Post("/logout", handler.LogoutUser)
+	router.Post("/magic-link", handler.RequestMagicLink)
+	router.Get("/magic-link/consume", handler.ConsumeMagicLink)
+	router.Post("/passkey/begin", passkeyHandler.BeginLogin)
+	router.Post("/passkey/finish", passkeyHandler.FinishLogin)
 
 	return router
 }
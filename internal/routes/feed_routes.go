@@ -0,0 +1,32 @@
+package routes
+
+import (
+	"github.com/achintha-dilshan/go-rest-api/internal/handlers"
+	"github.com/achintha-dilshan/go-rest-api/internal/middlewares"
+	"github.com/achintha-dilshan/go-rest-api/internal/services"
+	"github.com/go-chi/chi/v5"
+)
+
+type feedRoutes struct {
+	services *services.Services
+}
+
+type FeedRoutes interface {
+	Get() *chi.Mux
+}
+
+func NewFeedRoutes(services *services.Services) FeedRoutes {
+	return &feedRoutes{services: services}
+}
+
+func (r *feedRoutes) Get() *chi.Mux {
+	router := chi.NewRouter()
+
+	s := r.services
+	handler := handlers.NewFollowHandler(s.Follow, s.User, s.Reaction)
+	auth := middlewares.NewAuthMiddleware(s.Session, s.User)
+
+	router.With(auth.Authenticate).Get("/", handler.GetFeed)
+
+	return router
+}
@@ -0,0 +1,16 @@
+package routes
+
+import (
+	"github.com/achintha-dilshan/go-rest-api/config"
+	"github.com/achintha-dilshan/go-rest-api/internal/utils/webauthn"
+)
+
+// newWebAuthn creates the WebAuthn relying party from the configuration.
+func newWebAuthn() *webauthn.WebAuthn {
+	return webauthn.New(webauthn.Config{
+		RPID:   config.Env.WebAuthnRPID,
+		RPName: config.Env.WebAuthnRPName,
+		Origin: config.Env.WebAuthnOrigin,
+		Secret: []byte(config.Env.JWTSecret),
+	})
+}
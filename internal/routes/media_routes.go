@@ -0,0 +1,43 @@
+package routes
+
+import (
+	"net/http"
+
+	"github.com/achintha-dilshan/go-rest-api/config"
+	"github.com/achintha-dilshan/go-rest-api/internal/handlers"
+	"github.com/achintha-dilshan/go-rest-api/internal/middlewares"
+	"github.com/achintha-dilshan/go-rest-api/internal/services"
+	"github.com/achintha-dilshan/go-rest-api/internal/utils/storage"
+	"github.com/go-chi/chi/v5"
+)
+
+type mediaRoutes struct {
+	services *services.Services
+}
+
+type MediaRoutes interface {
+	Get() *chi.Mux
+}
+
+func NewMediaRoutes(services *services.Services) MediaRoutes {
+	return &mediaRoutes{services: services}
+}
+
+func (r *mediaRoutes) Get() *chi.Mux {
+	router := chi.NewRouter()
+
+	s := r.services
+	handler := handlers.NewMediaHandler(s.Media, s.Post, s.User, config.Env.MediaMaxBytes)
+	auth := middlewares.NewAuthMiddleware(s.Session, s.User)
+
+	router.With(auth.Authenticate).Post("/", handler.UploadMedia)
+	router.Get("/{id}", handler.GetMedia)
+	router.With(auth.Authenticate).Delete("/{id}", handler.DeleteMedia)
+
+	// files kept on the local filesystem are served by the API itself
+	if local, ok := s.Storage.(*storage.Local); ok {
+		router.Handle("/files/*", http.StripPrefix("/media/files", local.Handler()))
+	}
+
+	return router
+}
@@ -1,41 +1,52 @@
 package routes
 
 import (
-	"database/sql"
-
+	"github.com/achintha-dilshan/go-rest-api/config"
 	"github.com/achintha-dilshan/go-rest-api/internal/handlers"
 	"github.com/achintha-dilshan/go-rest-api/internal/middlewares"
-	"github.com/achintha-dilshan/go-rest-api/internal/repositories"
 	"github.com/achintha-dilshan/go-rest-api/internal/services"
+	"github.com/achintha-dilshan/go-rest-api/internal/utils/broker"
 	"github.com/go-chi/chi/v5"
 )
 
 type postRoutes struct {
-	db *sql.DB
+	services *services.Services
+	events   *broker.Broker
 }
 
 type PostRoutes interface {
 	Get() *chi.Mux
 }
 
-func NewPostRoutes(db *sql.DB) PostRoutes {
+func NewPostRoutes(services *services.Services, events *broker.Broker) PostRoutes {
 	return &postRoutes{
-		db: db,
+		services: services,
+		events:   events,
 	}
 }
 
 func (r *postRoutes) Get() *chi.Mux {
 	router := chi.NewRouter()
 
-	repo := repositories.NewPostRepository(r.db)
-	service := services.NewPostService(repo)
-	handler := handlers.NewPostHandler(service)
+	s := r.services
+	handler := handlers.NewPostHandler(s.Post, s.Reaction)
+	reactionHandler := handlers.NewReactionHandler(s.Reaction, s.Post)
+	mediaHandler := handlers.NewMediaHandler(s.Media, s.Post, s.User, config.Env.MediaMaxBytes)
+	streamHandler := handlers.NewStreamHandler(r.events, s.Session, s.User)
+
+	auth := middlewares.NewAuthMiddleware(s.Session, s.User)
 
 	router.Get("/", handler.GetAllPosts)
+	router.With(auth.AuthenticateOptional).Get("/stream", streamHandler.Stream)
 	router.Get("/{id}", handler.GetSinglePost)
-	router.With(middlewares.AuthMiddleware).Post("/", handler.CreatePost)
-	router.With(middlewares.AuthMiddleware).Patch("/{id}", handler.EditPost)
-	router.With(middlewares.AuthMiddleware).Delete("/{id}", handler.DeletePost)
+	router.Get("/by-slug/{slug}", handler.GetPostBySlug)
+	router.Get("/{id}/media", mediaHandler.GetPostMedia)
+	router.Get("/{id}/reactions", reactionHandler.GetReactions)
+	router.With(auth.Authenticate).Post("/", handler.CreatePost)
+	router.With(auth.Authenticate).Patch("/{id}", handler.EditPost)
+	router.With(auth.Authenticate).Delete("/{id}", handler.DeletePost)
+	router.With(auth.Authenticate).Put("/{id}/reactions/{emoji}", reactionHandler.AddReaction)
+	router.With(auth.Authenticate).Delete("/{id}/reactions/{emoji}", reactionHandler.RemoveReaction)
 
 	return router
 }
@@ -0,0 +1,39 @@
+package routes
+
+import (
+	"github.com/achintha-dilshan/go-rest-api/internal/handlers"
+	"github.com/achintha-dilshan/go-rest-api/internal/middlewares"
+	"github.com/achintha-dilshan/go-rest-api/internal/services"
+	"github.com/go-chi/chi/v5"
+)
+
+type profileRoutes struct {
+	services *services.Services
+}
+
+type ProfileRoutes interface {
+	Get() *chi.Mux
+}
+
+func NewProfileRoutes(services *services.Services) ProfileRoutes {
+	return &profileRoutes{services: services}
+}
+
+func (r *profileRoutes) Get() *chi.Mux {
+	router := chi.NewRouter()
+
+	s := r.services
+	handler := handlers.NewProfileHandler(s.User, s.Post, s.Reaction)
+	followHandler := handlers.NewFollowHandler(s.Follow, s.User, s.Reaction)
+
+	auth := middlewares.NewAuthMiddleware(s.Session, s.User)
+
+	router.Get("/{id}", handler.GetProfile)
+	router.Get("/{id}/posts", handler.GetProfilePosts)
+	router.Get("/{id}/followers", followHandler.GetFollowers)
+	router.Get("/{id}/following", followHandler.GetFollowing)
+	router.With(auth.Authenticate).Put("/{id}/follow", followHandler.Follow)
+	router.With(auth.Authenticate).Delete("/{id}/follow", followHandler.Unfollow)
+
+	return router
+}
@@ -1,38 +1,73 @@
 package routes
 
 import (
-	"database/sql"
-
+	"github.com/achintha-dilshan/go-rest-api/config"
 	"github.com/achintha-dilshan/go-rest-api/internal/handlers"
 	"github.com/achintha-dilshan/go-rest-api/internal/middlewares"
-	"github.com/achintha-dilshan/go-rest-api/internal/repositories"
 	"github.com/achintha-dilshan/go-rest-api/internal/services"
+	"github.com/achintha-dilshan/go-rest-api/internal/utils/password"
 	"github.com/go-chi/chi/v5"
 )
 
 type userRoutes struct {
-	db *sql.DB
+	services *services.Services
 }
 
 type UserRoutes interface {
 	Get() *chi.Mux
 }
 
-func NewUserRoutes(db *sql.DB) UserRoutes {
-	return &userRoutes{db: db}
+func NewUserRoutes(services *services.Services) UserRoutes {
+	return &userRoutes{services: services}
 }
 
 func (r *userRoutes) Get() *chi.Mux {
 	router := chi.NewRouter()
-	router.Use(middlewares.AuthMiddleware)
 
-	repo := repositories.NewUserRepository(r.db)
-	service := services.NewUserService(repo)
-	handler := handlers.NewUserHandler(service)
+	s := r.services
+	handler := handlers.NewUserHandler(s.User, s.Post, password.PolicyFromConfig(), password.HasherFromConfig())
+	sessionHandler := handlers.NewSessionHandler(s.Session)
+	passkeyHandler := handlers.NewPasskeyHandler(s.Passkey, s.User, s.Session, newWebAuthn())
+	mediaHandler := handlers.NewMediaHandler(s.Media, s.Post, s.User, config.Env.MediaMaxBytes)
+	notificationHandler := handlers.NewNotificationHandler(s.Notification)
+	webhookHandler := handlers.NewWebhookHandler(s.Webhook)
+
+	auth := middlewares.NewAuthMiddleware(s.Session, s.User)
+
+	// still reachable when an admin has required a password reset
+	router.With(auth.AuthenticatePendingReset, middlewares.BlockImpersonation).Patch("/password-reset", handler.ResetPassword)
+
+	router.Group(func(router chi.Router) {
+		router.Use(auth.Authenticate)
+
+		router.Get("/me", handler.GetMe)
+		router.Get("/sessions", sessionHandler.GetSessions)
+		router.Get("/passkeys", passkeyHandler.GetPasskeys)
+		router.Post("/avatar", mediaHandler.UploadAvatar)
+		router.Get("/notifications", notificationHandler.GetNotifications)
+		router.Post("/notifications/{id}/read", notificationHandler.MarkRead)
+		router.Post("/notifications/read-all", notificationHandler.MarkAllRead)
+		router.Get("/notifications/preferences", notificationHandler.GetPreferences)
+		router.Patch("/notifications/preferences", notificationHandler.UpdatePreferences)
+		router.Get("/webhooks", webhookHandler.GetWebhooks)
+		router.Get("/webhooks/{id}", webhookHandler.GetWebhook)
+		router.Get("/webhooks/{id}/deliveries", webhookHandler.GetDeliveries)
+
+		// account security stays with the real owner
+		router.Group(func(router chi.Router) {
+			router.Use(middlewares.BlockImpersonation)
 
-	router.Patch("/password-reset", handler.ResetPassword)
-	router.Patch("/update", handler.UpdateUser)
-	router.Delete("/delete", handler.DeleteUser)
+			router.Patch("/update", handler.UpdateUser)
+			router.Delete("/delete", handler.DeleteUser)
+			router.Delete("/sessions/{id}", sessionHandler.RevokeSession)
+			router.Post("/passkeys/register/begin", passkeyHandler.BeginRegistration)
+			router.Post("/passkeys/register/finish", passkeyHandler.FinishRegistration)
+			router.Delete("/passkeys/{id}", passkeyHandler.DeletePasskey)
+			router.Post("/webhooks", webhookHandler.CreateWebhook)
+			router.Patch("/webhooks/{id}", webhookHandler.UpdateWebhook)
+			router.Delete("/webhooks/{id}", webhookHandler.DeleteWebhook)
+		})
+	})
 
 	return router
 }
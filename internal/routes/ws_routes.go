@@ -0,0 +1,41 @@
+package routes
+
+import (
+	"github.com/achintha-dilshan/go-rest-api/config"
+	"github.com/achintha-dilshan/go-rest-api/internal/handlers"
+	"github.com/achintha-dilshan/go-rest-api/internal/middlewares"
+	"github.com/achintha-dilshan/go-rest-api/internal/services"
+	"github.com/achintha-dilshan/go-rest-api/internal/utils/broker"
+	"github.com/achintha-dilshan/go-rest-api/internal/utils/hub"
+	"github.com/go-chi/chi/v5"
+)
+
+type wsRoutes struct {
+	services *services.Services
+	events   *broker.Broker
+	hub      *hub.Hub
+}
+
+type WSRoutes interface {
+	Get() *chi.Mux
+}
+
+func NewWSRoutes(services *services.Services, events *broker.Broker, hub *hub.Hub) WSRoutes {
+	return &wsRoutes{
+		services: services,
+		events:   events,
+		hub:      hub,
+	}
+}
+
+func (r *wsRoutes) Get() *chi.Mux {
+	router := chi.NewRouter()
+
+	s := r.services
+	handler := handlers.NewWSHandler(r.hub, r.events, s.Post, s.Follow, s.Session, s.User, config.Env.AppURL)
+	auth := middlewares.NewAuthMiddleware(s.Session, s.User)
+
+	router.With(auth.Authenticate).Get("/", handler.Connect)
+
+	return router
+}
@@ -0,0 +1,104 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+
+	"github.com/achintha-dilshan/go-rest-api/internal/models"
+	"github.com/achintha-dilshan/go-rest-api/internal/repositories"
+	"github.com/achintha-dilshan/go-rest-api/internal/types"
+)
+
+// audit log actions
+const (
+	AuditUserLogin               = "user.login"
+	AuditUserLoginFailed         = "user.login_failed"
+	AuditUserUpdated             = "user.updated"
+	AuditUserPasswordChanged     = "user.password_changed"
+	AuditUserSuspended           = "user.suspended"
+	AuditUserUnsuspended         = "user.unsuspended"
+	AuditUserPasswordResetForced = "user.password_reset_forced"
+	AuditUserDeleted             = "user.deleted"
+	AuditUserImpersonated        = "user.impersonated"
+	AuditUserRoleChanged         = "user.role_changed"
+	AuditPostCreated             = "post.created"
+	AuditPostUpdated             = "post.updated"
+	AuditPostDeleted             = "post.deleted"
+	AuditJobRetried              = "job.retried"
+)
+
+// audit log target types
+const (
+	AuditTargetUser = "user"
+	AuditTargetPost = "post"
+	AuditTargetJob  = "job"
+)
+
+type auditService struct {
+	repository repositories.AuditLogRepository
+}
+
+type AuditService interface {
+	Record(ctx context.Context, entry *models.AuditLog, metadata any) error
+	FindAuditLogs(ctx context.Context, filter repositories.AuditLogFilter, limit, offset int) ([]*models.AuditLog, int64, error)
+}
+
+func NewAuditService(repository repositories.AuditLogRepository) AuditService {
+	return &auditService{repository: repository}
+}
+
+// record an entry in the audit log, with optional metadata stored as JSON.
+// The actor, IP address and request ID default to those of the current request.
+func (s *auditService) Record(ctx context.Context, entry *models.AuditLog, metadata any) error {
+	if metadata != nil {
+		raw, err := json.Marshal(metadata)
+		if err != nil {
+			return err
+		}
+		entry.Metadata = raw
+	}
+
+	if userID, ok := ctx.Value(types.UserIDKey).(int); ok && entry.ActorId == nil {
+		actorId := int64(userID)
+		entry.ActorId = &actorId
+	}
+
+	// mark entries made by an admin acting as the user
+	if impersonatorId, ok := ctx.Value(types.ImpersonatorIDKey).(int64); ok && entry.ImpersonatorId == nil {
+		entry.ImpersonatorId = &impersonatorId
+	}
+
+	if ip, ok := ctx.Value(types.ClientIPKey).(string); ok && entry.IPAddress == "" {
+		entry.IPAddress = ip
+	}
+
+	if requestID, ok := ctx.Value(types.RequestIDKey).(string); ok && entry.RequestId == "" {
+		entry.RequestId = requestID
+	}
+
+	_, err := s.repository.Create(ctx, entry)
+	return err
+}
+
+// find audit log entries matching the filter
+func (s *auditService) FindAuditLogs(ctx context.Context, filter repositories.AuditLogFilter, limit, offset int) ([]*models.AuditLog, int64, error) {
+	return s.repository.Find(ctx, filter, limit, offset)
+}
+
+// recordAudit records an action that has already happened, so a failure to
+// record it is logged rather than returned.
+func recordAudit(ctx context.Context, audit AuditService, entry *models.AuditLog, metadata any) {
+	if err := audit.Record(ctx, entry, metadata); err != nil {
+		log.Printf("Failed to record audit log entry %s: %v", entry.Action, err)
+	}
+}
+
+// snapshot captures the state of a record for the audit log.
+func snapshot(value any) json.RawMessage {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return nil
+	}
+	return raw
+}
@@ -0,0 +1,33 @@
+package services
+
+import (
+	"encoding/json"
+	"log"
+
+	"github.com/achintha-dilshan/go-rest-api/internal/utils/broker"
+)
+
+// event types published to the broker
+const (
+	EventPostCreated         = "post.created"
+	EventPostUpdated         = "post.updated"
+	EventPostDeleted         = "post.deleted"
+	EventNotificationCreated = "notification.created"
+)
+
+// event types only sent to webhooks
+const (
+	EventUserRegistered = "user.registered"
+)
+
+// publish sends an event with data encoded as JSON. A userId other than 0
+// keeps the event private to that user.
+func publish(events *broker.Broker, eventType string, userId int64, data any) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		log.Printf("Failed to encode %s event: %v", eventType, err)
+		return
+	}
+
+	events.Publish(eventType, userId, raw)
+}
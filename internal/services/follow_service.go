@@ -0,0 +1,118 @@
+package services
+
+import (
+	"context"
+	"errors"
+
+	"github.com/achintha-dilshan/go-rest-api/internal/models"
+	"github.com/achintha-dilshan/go-rest-api/internal/repositories"
+	"github.com/achintha-dilshan/go-rest-api/internal/utils/cursor"
+)
+
+// ErrSelfFollow is returned when a user tries to follow themselves.
+var ErrSelfFollow = errors.New("cannot follow yourself")
+
+// up to this many followed authors the feed merges each author's newest
+// posts; beyond it, the feed walks all posts newest first instead
+const feedFanInLimit = 100
+
+type followService struct {
+	follows       repositories.FollowRepository
+	posts         repositories.PostRepository
+	notifications NotificationService
+}
+
+type FollowService interface {
+	Follow(ctx context.Context, followerId, followeeId int64) error
+	Unfollow(ctx context.Context, followerId, followeeId int64) error
+	IsFollowing(ctx context.Context, followerId, followeeId int64) (bool, error)
+	FindFollowers(ctx context.Context, userId int64, limit, offset int) ([]*models.FollowUser, int64, error)
+	FindFollowing(ctx context.Context, userId int64, limit, offset int) ([]*models.FollowUser, int64, error)
+	FindFeed(ctx context.Context, userId int64, before *cursor.Cursor, limit int) ([]*models.Post, *cursor.Cursor, error)
+	FindConnectedIds(ctx context.Context, userId int64, ids []int64) ([]int64, error)
+}
+
+func NewFollowService(follows repositories.FollowRepository, posts repositories.PostRepository, notifications NotificationService) FollowService {
+	return &followService{
+		follows:       follows,
+		posts:         posts,
+		notifications: notifications,
+	}
+}
+
+// follow an author
+func (s *followService) Follow(ctx context.Context, followerId, followeeId int64) error {
+	if followerId == followeeId {
+		return ErrSelfFollow
+	}
+
+	added, err := s.follows.Follow(ctx, followerId, followeeId)
+	if err != nil {
+		return err
+	}
+
+	if added {
+		notify(ctx, s.notifications, &models.Notification{
+			UserId:  followeeId,
+			ActorId: &followerId,
+			Type:    models.NotificationFollow,
+		}, nil)
+	}
+
+	return nil
+}
+
+// stop following an author
+func (s *followService) Unfollow(ctx context.Context, followerId, followeeId int64) error {
+	return s.follows.Unfollow(ctx, followerId, followeeId)
+}
+
+// check whether a user follows an author
+func (s *followService) IsFollowing(ctx context.Context, followerId, followeeId int64) (bool, error) {
+	return s.follows.IsFollowing(ctx, followerId, followeeId)
+}
+
+// list the followers of a user
+func (s *followService) FindFollowers(ctx context.Context, userId int64, limit, offset int) ([]*models.FollowUser, int64, error) {
+	return s.follows.FindFollowers(ctx, userId, limit, offset)
+}
+
+// list the authors a user follows
+func (s *followService) FindFollowing(ctx context.Context, userId int64, limit, offset int) ([]*models.FollowUser, int64, error) {
+	return s.follows.FindFollowing(ctx, userId, limit, offset)
+}
+
+// find those of ids that a user follows or is followed by
+func (s *followService) FindConnectedIds(ctx context.Context, userId int64, ids []int64) ([]int64, error) {
+	return s.follows.FindConnectedIds(ctx, userId, ids)
+}
+
+// get a page of posts by the authors a user follows, newest first, along with
+// the cursor of the next page, which is nil on the last page
+func (s *followService) FindFeed(ctx context.Context, userId int64, before *cursor.Cursor, limit int) ([]*models.Post, *cursor.Cursor, error) {
+	authorIds, err := s.follows.FindFolloweeIds(ctx, userId, feedFanInLimit+1)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// one extra post tells whether there is another page
+	var posts []*models.Post
+	if len(authorIds) > feedFanInLimit {
+		posts, err = s.posts.FindFeedByFollower(ctx, userId, before, limit+1)
+	} else {
+		posts, err = s.posts.FindFeedByAuthors(ctx, authorIds, before, limit+1)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var next *cursor.Cursor
+	if len(posts) > limit {
+		posts = posts[:limit]
+		last := posts[len(posts)-1]
+		next = &cursor.Cursor{CreatedAt: last.CreatedAt, Id: last.Id}
+	}
+
+	summarize(posts)
+	return posts, next, nil
+}
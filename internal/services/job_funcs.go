@@ -0,0 +1,80 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/achintha-dilshan/go-rest-api/internal/models"
+	"github.com/achintha-dilshan/go-rest-api/internal/repositories"
+	"github.com/achintha-dilshan/go-rest-api/internal/utils/mailer"
+)
+
+// NewWebhookDispatchJob queues an outbox event for the webhooks subscribed to it.
+func NewWebhookDispatchJob(webhooks WebhookService) JobFunc {
+	return func(ctx context.Context, payload json.RawMessage) error {
+		var event models.OutboxEvent
+		if err := json.Unmarshal(payload, &event); err != nil {
+			return Permanent(err)
+		}
+
+		return webhooks.Dispatch(ctx, event.Event, event.Payload)
+	}
+}
+
+// MagicLinkJob is the payload of a sign-in link email.
+type MagicLinkJob struct {
+	Email string `json:"email"`
+}
+
+// NewMagicLinkJob issues and mails a sign-in link if the email belongs to a
+// user. Links are built on appURL.
+func NewMagicLinkJob(users UserService, magicLinks MagicLinkService, mail mailer.Mailer, appURL string) JobFunc {
+	return func(ctx context.Context, payload json.RawMessage) error {
+		var job MagicLinkJob
+		if err := json.Unmarshal(payload, &job); err != nil {
+			return Permanent(err)
+		}
+
+		user, err := users.FindUserByEmail(ctx, job.Email)
+		if err != nil || user == nil {
+			return err
+		}
+
+		token, err := magicLinks.IssueMagicLink(ctx, user.Id)
+		if err != nil {
+			return err
+		}
+
+		link := fmt.Sprintf("%s/auth/magic-link/consume?token=%s", strings.TrimRight(appURL, "/"), url.QueryEscape(token))
+		body := fmt.Sprintf("Hi %s,\n\nUse the link below to sign in. It expires in %d minutes and can only be used once.\n\n%s\n\nIf you did not request this, you can ignore this email.\n",
+			user.Name, int(MagicLinkTTL.Minutes()), link)
+
+		return mail.Send(user.Email, "Your sign-in link", body)
+	}
+}
+
+// NewCleanupJob deletes succeeded jobs and published outbox events older
+// than retention.
+func NewCleanupJob(jobs repositories.JobRepository, outbox repositories.OutboxRepository, retention time.Duration) JobFunc {
+	return func(ctx context.Context, _ json.RawMessage) error {
+		prunedJobs, err := jobs.Prune(ctx, retention)
+		if err != nil {
+			return err
+		}
+
+		prunedEvents, err := outbox.Prune(ctx, retention)
+		if err != nil {
+			return err
+		}
+
+		if prunedJobs > 0 || prunedEvents > 0 {
+			log.Printf("Pruned %d jobs and %d outbox events", prunedJobs, prunedEvents)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,119 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/achintha-dilshan/go-rest-api/internal/models"
+	"github.com/achintha-dilshan/go-rest-api/internal/repositories"
+)
+
+// job queues, each worked by its own pool
+const (
+	JobQueueDefault = "default"
+	JobQueueMail    = "mail"
+)
+
+// job types
+const (
+	JobWebhookDispatch = "webhook.dispatch"
+	JobMagicLink       = "mail.magic_link"
+	JobCleanup         = "maintenance.cleanup"
+)
+
+// DefaultJobAttempts is the number of attempts a job gets unless it says otherwise.
+const DefaultJobAttempts = 10
+
+// JobFunc does the work of a job. Returning an error retries the job later,
+// unless the error is wrapped with Permanent.
+type JobFunc func(ctx context.Context, payload json.RawMessage) error
+
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent marks a job error that retrying cannot fix, such as a malformed
+// payload, so the job is dead-lettered straight away.
+func Permanent(err error) error {
+	return &permanentError{err: err}
+}
+
+// isPermanent reports whether err was marked with Permanent.
+func isPermanent(err error) bool {
+	var permanent *permanentError
+	return errors.As(err, &permanent)
+}
+
+type jobService struct {
+	jobs   repositories.JobRepository
+	outbox repositories.OutboxRepository
+}
+
+type JobService interface {
+	Enqueue(ctx context.Context, job *models.Job, payload any) (int64, error)
+	Publish(ctx context.Context, event string, data any) error
+	FindJobs(ctx context.Context, filter repositories.JobFilter, limit, offset int) ([]*models.Job, int64, error)
+	FindJobById(ctx context.Context, id int64) (*models.Job, error)
+	RetryJob(ctx context.Context, id int64) (bool, error)
+}
+
+func NewJobService(jobs repositories.JobRepository, outbox repositories.OutboxRepository) JobService {
+	return &jobService{
+		jobs:   jobs,
+		outbox: outbox,
+	}
+}
+
+// queue a job with its payload encoded as JSON. The queue and attempts
+// default when unset, and a job with a unique key that is already queued is
+// skipped, returning repositories.ErrDuplicate. Inside a transaction the job
+// is only queued if the transaction commits.
+func (s *jobService) Enqueue(ctx context.Context, job *models.Job, payload any) (int64, error) {
+	if payload != nil {
+		raw, err := json.Marshal(payload)
+		if err != nil {
+			return 0, err
+		}
+		job.Payload = raw
+	}
+
+	if job.Queue == "" {
+		job.Queue = JobQueueDefault
+	}
+	if job.MaxAttempts == 0 {
+		job.MaxAttempts = DefaultJobAttempts
+	}
+
+	return s.jobs.Create(ctx, job)
+}
+
+// record a domain event with data encoded as JSON, for the jobs subscribed to
+// it. Call it in the transaction of the change, so the event is recorded if
+// and only if the change is.
+func (s *jobService) Publish(ctx context.Context, event string, data any) error {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	return s.outbox.Add(ctx, event, raw)
+}
+
+// find jobs matching the filter, newest first
+func (s *jobService) FindJobs(ctx context.Context, filter repositories.JobFilter, limit, offset int) ([]*models.Job, int64, error) {
+	return s.jobs.Find(ctx, filter, limit, offset)
+}
+
+// find job by id
+func (s *jobService) FindJobById(ctx context.Context, id int64) (*models.Job, error) {
+	return s.jobs.FindById(ctx, id)
+}
+
+// give a dead job a fresh set of attempts, reporting whether it was dead
+func (s *jobService) RetryJob(ctx context.Context, id int64) (bool, error) {
+	return s.jobs.Requeue(ctx, id)
+}
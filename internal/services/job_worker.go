@@ -0,0 +1,290 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/achintha-dilshan/go-rest-api/internal/models"
+	"github.com/achintha-dilshan/go-rest-api/internal/repositories"
+	"github.com/achintha-dilshan/go-rest-api/internal/utils/backoff"
+)
+
+// how often idle workers look for due jobs and new outbox events
+const (
+	jobPollInterval   = time.Second
+	scheduleInterval  = time.Minute
+	outboxBatchSize   = 100
+	jobRetryBaseDelay = 10 * time.Second
+	jobRetryMaxDelay  = time.Hour
+)
+
+// the longest error message kept on a job
+const maxJobError = 1000
+
+// JobOptions configures the job worker.
+type JobOptions struct {
+	// Workers is the number of jobs run at once from each queue.
+	Workers map[string]int
+	// Timeout limits how long a single attempt may run.
+	Timeout time.Duration
+}
+
+type jobSubscription struct {
+	queue   string
+	jobType string
+}
+
+type jobSchedule struct {
+	queue   string
+	jobType string
+	every   time.Duration
+}
+
+type jobWorker struct {
+	jobs        repositories.JobRepository
+	outbox      repositories.OutboxRepository
+	tx          repositories.TxManager
+	options     JobOptions
+	funcs       map[string]JobFunc
+	subscribers map[string][]jobSubscription
+	schedules   []jobSchedule
+}
+
+type JobWorker interface {
+	Handle(jobType string, fn JobFunc)
+	Subscribe(event, queue, jobType string)
+	Schedule(queue, jobType string, every time.Duration)
+	Run(ctx context.Context)
+}
+
+func NewJobWorker(jobs repositories.JobRepository, outbox repositories.OutboxRepository, tx repositories.TxManager, options JobOptions) JobWorker {
+	return &jobWorker{
+		jobs:        jobs,
+		outbox:      outbox,
+		tx:          tx,
+		options:     options,
+		funcs:       make(map[string]JobFunc),
+		subscribers: make(map[string][]jobSubscription),
+	}
+}
+
+// register the function that runs jobs of a type
+func (w *jobWorker) Handle(jobType string, fn JobFunc) {
+	w.funcs[jobType] = fn
+}
+
+// queue a job for every outbox event of a type; the job's payload is the
+// event, as a models.OutboxEvent
+func (w *jobWorker) Subscribe(event, queue, jobType string) {
+	w.subscribers[event] = append(w.subscribers[event], jobSubscription{queue: queue, jobType: jobType})
+}
+
+// queue a job once every interval. Each interval gets a single job however
+// many servers run the worker.
+func (w *jobWorker) Schedule(queue, jobType string, every time.Duration) {
+	w.schedules = append(w.schedules, jobSchedule{queue: queue, jobType: jobType, every: every})
+}
+
+// run jobs until ctx is done, returning once the jobs under way have finished
+func (w *jobWorker) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+
+	for queue, workers := range w.options.Workers {
+		for i := 0; i < workers; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				w.work(ctx, queue)
+			}()
+		}
+	}
+
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		w.every(ctx, jobPollInterval, w.relay)
+	}()
+	go func() {
+		defer wg.Done()
+		w.every(ctx, scheduleInterval, w.schedule)
+	}()
+
+	wg.Wait()
+}
+
+// work runs the jobs of a queue one at a time.
+func (w *jobWorker) work(ctx context.Context, queue string) {
+	lease := w.options.Timeout + time.Minute
+
+	for {
+		job, err := w.jobs.Claim(ctx, queue, lease)
+		if err != nil && ctx.Err() == nil {
+			log.Printf("Failed to claim a job from queue %s: %v", queue, err)
+		}
+
+		if job != nil {
+			w.run(ctx, job)
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(jobPollInterval):
+		}
+	}
+}
+
+// run makes an attempt at a job and records how it went.
+func (w *jobWorker) run(ctx context.Context, job *models.Job) {
+	var err error
+	fn, ok := w.funcs[job.Type]
+	switch {
+	case !ok:
+		err = Permanent(fmt.Errorf("no handler for job type %s", job.Type))
+	case job.Attempts > job.MaxAttempts:
+		// claimed again after its worker died on the last attempt
+		err = Permanent(errors.New("ran out of attempts"))
+	default:
+		runCtx, cancel := context.WithTimeout(ctx, w.options.Timeout)
+		err = call(runCtx, fn, job.Payload)
+		cancel()
+	}
+
+	// the outcome is saved even when shutting down
+	store := context.WithoutCancel(ctx)
+
+	if err == nil {
+		if err := w.jobs.Complete(store, job.Id); err != nil {
+			log.Printf("Failed to complete job %d: %v", job.Id, err)
+		}
+		return
+	}
+
+	message := err.Error()
+	if len(message) > maxJobError {
+		// drop any character cut in half
+		message = strings.ToValidUTF8(message[:maxJobError], "")
+	}
+
+	if isPermanent(err) || job.Attempts >= job.MaxAttempts {
+		log.Printf("Job %d (%s) is dead after %d attempts: %v", job.Id, job.Type, job.Attempts, err)
+		if err := w.jobs.Bury(store, job.Id, message); err != nil {
+			log.Printf("Failed to bury job %d: %v", job.Id, err)
+		}
+		return
+	}
+
+	retryIn := backoff.Exponential(job.Attempts, jobRetryBaseDelay, jobRetryMaxDelay)
+	if ctx.Err() != nil {
+		// interrupted by shutdown rather than failed; run it again on start
+		retryIn = 0
+	}
+	if err := w.jobs.Reschedule(store, job.Id, message, retryIn); err != nil {
+		log.Printf("Failed to reschedule job %d: %v", job.Id, err)
+	}
+}
+
+// relay hands outbox events to the jobs subscribed to them, in batches until
+// none are left. Claiming, queueing and marking events published happen in
+// one transaction, so each event is handed over exactly once.
+func (w *jobWorker) relay(ctx context.Context) {
+	for ctx.Err() == nil {
+		var relayed int
+
+		err := w.tx.WithinTx(ctx, func(ctx context.Context) error {
+			events, err := w.outbox.ClaimUnpublished(ctx, outboxBatchSize)
+			if err != nil {
+				return err
+			}
+
+			ids := make([]int64, 0, len(events))
+			for _, event := range events {
+				payload, err := json.Marshal(event)
+				if err != nil {
+					return err
+				}
+
+				for _, sub := range w.subscribers[event.Event] {
+					_, err := w.jobs.Create(ctx, &models.Job{
+						Queue:       sub.queue,
+						Type:        sub.jobType,
+						Payload:     payload,
+						UniqueKey:   fmt.Sprintf("outbox:%d:%s", event.Id, sub.jobType),
+						MaxAttempts: DefaultJobAttempts,
+					})
+					if err != nil && !errors.Is(err, repositories.ErrDuplicate) {
+						return err
+					}
+				}
+
+				ids = append(ids, event.Id)
+			}
+
+			relayed = len(events)
+			return w.outbox.MarkPublished(ctx, ids)
+		})
+		if err != nil {
+			if ctx.Err() == nil {
+				log.Printf("Failed to relay outbox events: %v", err)
+			}
+			return
+		}
+
+		if relayed < outboxBatchSize {
+			return
+		}
+	}
+}
+
+// schedule queues the scheduled jobs of the current interval.
+func (w *jobWorker) schedule(ctx context.Context) {
+	now := time.Now().UTC()
+
+	for _, schedule := range w.schedules {
+		slot := now.Truncate(schedule.every)
+		_, err := w.jobs.Create(ctx, &models.Job{
+			Queue:       schedule.queue,
+			Type:        schedule.jobType,
+			UniqueKey:   fmt.Sprintf("schedule:%s:%d", schedule.jobType, slot.Unix()),
+			MaxAttempts: DefaultJobAttempts,
+		})
+		if err != nil && !errors.Is(err, repositories.ErrDuplicate) && ctx.Err() == nil {
+			log.Printf("Failed to schedule job %s: %v", schedule.jobType, err)
+		}
+	}
+}
+
+// every calls fn right away and then at each interval until ctx is done.
+func (w *jobWorker) every(ctx context.Context, interval time.Duration, fn func(ctx context.Context)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		fn(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// call runs a job function, turning a panic into an error so one bad job
+// cannot take the worker down.
+func call(ctx context.Context, fn JobFunc, payload json.RawMessage) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+
+	return fn(ctx, payload)
+}
@@ -0,0 +1,83 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/achintha-dilshan/go-rest-api/internal/models"
+	"github.com/achintha-dilshan/go-rest-api/internal/repositories"
+)
+
+// MagicLinkTTL is how long a sign-in link can be used.
+const MagicLinkTTL = 15 * time.Minute
+
+// ErrInvalidMagicLink is returned for unknown, expired or already used links.
+var ErrInvalidMagicLink = errors.New("invalid or expired magic link")
+
+type magicLinkService struct {
+	repository repositories.MagicLinkRepository
+}
+
+type MagicLinkService interface {
+	IssueMagicLink(ctx context.Context, userId int64) (string, error)
+	ConsumeMagicLink(ctx context.Context, token string) (int64, error)
+}
+
+func NewMagicLinkService(repository repositories.MagicLinkRepository) MagicLinkService {
+	return &magicLinkService{repository: repository}
+}
+
+// issue a new single-use sign-in token for a user; only its hash is stored
+func (s *magicLinkService) IssueMagicLink(ctx context.Context, userId int64) (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	token := base64.RawURLEncoding.EncodeToString(b)
+
+	_, err := s.repository.Create(ctx, &models.MagicLink{
+		UserId:    userId,
+		TokenHash: hashToken(token),
+		ExpiresAt: time.Now().UTC().Add(MagicLinkTTL),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
+
+// consume a sign-in token and return the ID of the user it belongs to
+func (s *magicLinkService) ConsumeMagicLink(ctx context.Context, token string) (int64, error) {
+	link, err := s.repository.FindByTokenHash(ctx, hashToken(token))
+	if err != nil {
+		return 0, err
+	}
+
+	if link == nil {
+		return 0, ErrInvalidMagicLink
+	}
+
+	// the update only succeeds once and only before the link expires
+	claimed, err := s.repository.MarkUsed(ctx, link.Id)
+	if err != nil {
+		return 0, err
+	}
+
+	if !claimed {
+		return 0, ErrInvalidMagicLink
+	}
+
+	return link.UserId, nil
+}
+
+// hashToken returns the hex encoded SHA-256 hash of a token.
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
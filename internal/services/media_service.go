@@ -0,0 +1,160 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log"
+
+	"github.com/achintha-dilshan/go-rest-api/internal/models"
+	"github.com/achintha-dilshan/go-rest-api/internal/repositories"
+	"github.com/achintha-dilshan/go-rest-api/internal/utils/imaging"
+	"github.com/achintha-dilshan/go-rest-api/internal/utils/storage"
+)
+
+// file extensions for the content types that are stored
+var mediaExtensions = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/gif":  ".gif",
+	"image/webp": ".webp",
+}
+
+// MediaOptions sets the sizes images are scaled down to, in pixels.
+type MediaOptions struct {
+	ThumbnailSize int
+	AvatarSize    int
+}
+
+type mediaService struct {
+	repository repositories.MediaRepository
+	storage    storage.Storage
+	options    MediaOptions
+}
+
+type MediaService interface {
+	UploadMedia(ctx context.Context, userId int64, postId *int64, data []byte) (*models.Media, error)
+	UploadAvatar(ctx context.Context, userId int64, data []byte) (*models.Media, error)
+	FindMediaById(ctx context.Context, id int64) (*models.Media, error)
+	FindPostMedia(ctx context.Context, postId int64) ([]*models.Media, error)
+	DeleteMedia(ctx context.Context, media *models.Media) error
+}
+
+func NewMediaService(repository repositories.MediaRepository, storage storage.Storage, options MediaOptions) MediaService {
+	return &mediaService{
+		repository: repository,
+		storage:    storage,
+		options:    options,
+	}
+}
+
+// store an uploaded image with a thumbnail, optionally attached to a post.
+// Images that cannot be identified are rejected with the imaging errors.
+func (s *mediaService) UploadMedia(ctx context.Context, userId int64, postId *int64, data []byte) (*models.Media, error) {
+	return s.upload(ctx, userId, postId, data, s.options.ThumbnailSize)
+}
+
+// store an uploaded avatar, with a thumbnail at the avatar size
+func (s *mediaService) UploadAvatar(ctx context.Context, userId int64, data []byte) (*models.Media, error) {
+	return s.upload(ctx, userId, nil, data, s.options.AvatarSize)
+}
+
+func (s *mediaService) upload(ctx context.Context, userId int64, postId *int64, data []byte, thumbnailSize int) (*models.Media, error) {
+	info, err := imaging.Probe(data)
+	if err != nil {
+		return nil, err
+	}
+
+	thumbnail, thumbnailInfo, err := imaging.Thumbnail(data, thumbnailSize)
+	if err != nil {
+		return nil, err
+	}
+
+	name, err := randomName()
+	if err != nil {
+		return nil, err
+	}
+
+	media := &models.Media{
+		UserId:       userId,
+		PostId:       postId,
+		StorageKey:   fmt.Sprintf("media/%d/%s%s", userId, name, mediaExtensions[info.ContentType]),
+		ThumbnailKey: fmt.Sprintf("media/%d/%s_thumb%s", userId, name, mediaExtensions[thumbnailInfo.ContentType]),
+		ContentType:  info.ContentType,
+		Size:         int64(len(data)),
+		Width:        info.Width,
+		Height:       info.Height,
+	}
+
+	if err := s.storage.Put(ctx, media.StorageKey, data, info.ContentType); err != nil {
+		return nil, err
+	}
+
+	if err := s.storage.Put(ctx, media.ThumbnailKey, thumbnail, thumbnailInfo.ContentType); err != nil {
+		s.removeFiles(ctx, media)
+		return nil, err
+	}
+
+	id, err := s.repository.Create(ctx, media)
+	if err != nil {
+		s.removeFiles(ctx, media)
+		return nil, err
+	}
+
+	media.Id = id
+	s.withURLs(media)
+	return media, nil
+}
+
+// find media by id
+func (s *mediaService) FindMediaById(ctx context.Context, id int64) (*models.Media, error) {
+	media, err := s.repository.FindById(ctx, id)
+	if media != nil {
+		s.withURLs(media)
+	}
+	return media, err
+}
+
+// find the media attached to a post
+func (s *mediaService) FindPostMedia(ctx context.Context, postId int64) ([]*models.Media, error) {
+	media, err := s.repository.FindByPostId(ctx, postId)
+	for _, item := range media {
+		s.withURLs(item)
+	}
+	return media, err
+}
+
+// delete a media record and its files
+func (s *mediaService) DeleteMedia(ctx context.Context, media *models.Media) error {
+	if err := s.repository.Delete(ctx, media.Id); err != nil {
+		return err
+	}
+
+	s.removeFiles(ctx, media)
+	return nil
+}
+
+// removeFiles deletes the stored files of a media record. A file left behind
+// only wastes space, so failures are logged rather than returned.
+func (s *mediaService) removeFiles(ctx context.Context, media *models.Media) {
+	for _, key := range []string{media.StorageKey, media.ThumbnailKey} {
+		if err := s.storage.Delete(ctx, key); err != nil {
+			log.Printf("Failed to delete stored file %s: %v", key, err)
+		}
+	}
+}
+
+func (s *mediaService) withURLs(media *models.Media) {
+	media.URL = s.storage.URL(media.StorageKey)
+	media.ThumbnailURL = s.storage.URL(media.ThumbnailKey)
+}
+
+// randomName returns an unguessable file name
+func randomName() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
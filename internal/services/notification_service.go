@@ -0,0 +1,131 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"slices"
+	"time"
+
+	"github.com/achintha-dilshan/go-rest-api/internal/models"
+	"github.com/achintha-dilshan/go-rest-api/internal/repositories"
+	"github.com/achintha-dilshan/go-rest-api/internal/utils/broker"
+)
+
+// ErrUnknownNotificationType is returned for a preference on a type that does not exist.
+var ErrUnknownNotificationType = errors.New("unknown notification type")
+
+// the most users a single post notifies of a mention
+const maxMentions = 20
+
+type notificationService struct {
+	repository repositories.NotificationRepository
+	events     *broker.Broker
+}
+
+type NotificationService interface {
+	Notify(ctx context.Context, notification *models.Notification, data any) error
+	FindNotifications(ctx context.Context, userId int64, unreadOnly bool, limit, offset int) ([]*models.Notification, int64, error)
+	CountUnread(ctx context.Context, userId int64) (int64, error)
+	MarkRead(ctx context.Context, userId, id int64) (bool, error)
+	MarkAllRead(ctx context.Context, userId int64) (int64, error)
+	FindPreferences(ctx context.Context, userId int64) (map[string]bool, error)
+	UpdatePreferences(ctx context.Context, userId int64, preferences map[string]bool) error
+}
+
+func NewNotificationService(repository repositories.NotificationRepository, events *broker.Broker) NotificationService {
+	return &notificationService{
+		repository: repository,
+		events:     events,
+	}
+}
+
+// notify a user of something another user did, with optional data to
+// describe it. Users are never notified of their own actions, nor of types
+// they have turned off.
+func (s *notificationService) Notify(ctx context.Context, notification *models.Notification, data any) error {
+	if notification.ActorId != nil && *notification.ActorId == notification.UserId {
+		return nil
+	}
+
+	if data != nil {
+		raw, err := json.Marshal(data)
+		if err != nil {
+			return err
+		}
+		notification.Data = raw
+	}
+
+	created, err := s.repository.Create(ctx, notification)
+	if err != nil || !created {
+		return err
+	}
+
+	// close enough to the stored time for the live copy
+	notification.CreatedAt = time.Now().UTC()
+
+	publish(s.events, EventNotificationCreated, notification.UserId, notification)
+	return nil
+}
+
+// list the notifications of a user, newest first
+func (s *notificationService) FindNotifications(ctx context.Context, userId int64, unreadOnly bool, limit, offset int) ([]*models.Notification, int64, error) {
+	return s.repository.FindByUserId(ctx, userId, unreadOnly, limit, offset)
+}
+
+// count the unread notifications of a user
+func (s *notificationService) CountUnread(ctx context.Context, userId int64) (int64, error) {
+	return s.repository.CountUnread(ctx, userId)
+}
+
+// mark a notification read, reporting whether the user has it
+func (s *notificationService) MarkRead(ctx context.Context, userId, id int64) (bool, error) {
+	return s.repository.MarkRead(ctx, userId, id)
+}
+
+// mark every notification of a user read
+func (s *notificationService) MarkAllRead(ctx context.Context, userId int64) (int64, error) {
+	return s.repository.MarkAllRead(ctx, userId)
+}
+
+// get whether each notification type is on for a user; types are on until turned off
+func (s *notificationService) FindPreferences(ctx context.Context, userId int64) (map[string]bool, error) {
+	stored, err := s.repository.FindPreferences(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	preferences := make(map[string]bool, len(models.NotificationTypes))
+	for _, notificationType := range models.NotificationTypes {
+		enabled, ok := stored[notificationType]
+		preferences[notificationType] = !ok || enabled
+	}
+
+	return preferences, nil
+}
+
+// turn notification types on or off for a user
+func (s *notificationService) UpdatePreferences(ctx context.Context, userId int64, preferences map[string]bool) error {
+	for notificationType := range preferences {
+		if !slices.Contains(models.NotificationTypes, notificationType) {
+			return ErrUnknownNotificationType
+		}
+	}
+
+	for notificationType, enabled := range preferences {
+		if err := s.repository.SetPreference(ctx, userId, notificationType, enabled); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// notify sends a notification, logging rather than returning failures so
+// they never undo the action being notified about.
+func notify(ctx context.Context, notifications NotificationService, notification *models.Notification, data any) {
+	if err := notifications.Notify(ctx, notification, data); err != nil {
+		log.Printf("Failed to send %s notification to user %d: %v", notification.Type, notification.UserId, err)
+	}
+}
@@ -0,0 +1,55 @@
+package services
+
+import (
+	"context"
+
+	"github.com/achintha-dilshan/go-rest-api/internal/models"
+	"github.com/achintha-dilshan/go-rest-api/internal/repositories"
+)
+
+type passkeyService struct {
+	repository repositories.PasskeyRepository
+}
+
+type PasskeyService interface {
+	CreatePasskey(ctx context.Context, passkey *models.Passkey) (int64, error)
+	FindPasskeyById(ctx context.Context, id int64) (*models.Passkey, error)
+	FindPasskeyByCredentialId(ctx context.Context, credentialId []byte) (*models.Passkey, error)
+	FindUserPasskeys(ctx context.Context, userId int64) ([]*models.Passkey, error)
+	UpdateSignCount(ctx context.Context, id int64, signCount uint32) error
+	DeletePasskey(ctx context.Context, id int64) error
+}
+
+func NewPasskeyService(repository repositories.PasskeyRepository) PasskeyService {
+	return &passkeyService{repository: repository}
+}
+
+// create a new passkey
+func (s *passkeyService) CreatePasskey(ctx context.Context, passkey *models.Passkey) (int64, error) {
+	return s.repository.Create(ctx, passkey)
+}
+
+// find passkey by id
+func (s *passkeyService) FindPasskeyById(ctx context.Context, id int64) (*models.Passkey, error) {
+	return s.repository.FindById(ctx, id)
+}
+
+// find passkey by credential id
+func (s *passkeyService) FindPasskeyByCredentialId(ctx context.Context, credentialId []byte) (*models.Passkey, error) {
+	return s.repository.FindByCredentialId(ctx, credentialId)
+}
+
+// find the passkeys of a user
+func (s *passkeyService) FindUserPasskeys(ctx context.Context, userId int64) ([]*models.Passkey, error) {
+	return s.repository.FindByUserId(ctx, userId)
+}
+
+// update the sign counter of a passkey
+func (s *passkeyService) UpdateSignCount(ctx context.Context, id int64, signCount uint32) error {
+	return s.repository.UpdateSignCount(ctx, id, signCount)
+}
+
+// delete a passkey
+func (s *passkeyService) DeletePasskey(ctx context.Context, id int64) error {
+	return s.repository.Delete(ctx, id)
+}
@@ -2,50 +2,310 @@ package services
 
 import (
 	"context"
+	"errors"
+	"slices"
+	"strconv"
 
 	"github.com/achintha-dilshan/go-rest-api/internal/models"
 	"github.com/achintha-dilshan/go-rest-api/internal/repositories"
+	"github.com/achintha-dilshan/go-rest-api/internal/utils/broker"
+	"github.com/achintha-dilshan/go-rest-api/internal/utils/markup"
+	"github.com/achintha-dilshan/go-rest-api/internal/utils/slug"
 )
 
+// ExcerptLength is the number of characters in a post excerpt.
+const ExcerptLength = 200
+
+// number of times a post is saved before giving up on a slug collision
+const slugAttempts = 3
+
 type postService struct {
-	repository repositories.PostRepository
+	repository    repositories.PostRepository
+	tx            repositories.TxManager
+	audit         AuditService
+	notifications NotificationService
+	jobs          JobService
+	events        *broker.Broker
 }
 
 type PostService interface {
 	CreatePost(ctx context.Context, post *models.Post) (int64, error)
 	FindAll(ctx context.Context) ([]*models.Post, error)
 	FindPostById(ctx context.Context, id int64) (*models.Post, error)
+	FindPostsByAuthor(ctx context.Context, authorId int64) ([]*models.Post, error)
+	CountPostsByAuthor(ctx context.Context, authorId int64) (int64, error)
+	FindPostBySlug(ctx context.Context, slug string) (*models.Post, bool, error)
 	UpdatePost(ctx context.Context, post *models.Post) error
 	DeletePost(ctx context.Context, id int64) error
 }
 
-func NewPostService(repository repositories.PostRepository) PostService {
+func NewPostService(repository repositories.PostRepository, tx repositories.TxManager, audit AuditService, notifications NotificationService, jobs JobService, events *broker.Broker) PostService {
 	return &postService{
-		repository: repository,
+		repository:    repository,
+		tx:            tx,
+		audit:         audit,
+		notifications: notifications,
+		jobs:          jobs,
+		events:        events,
 	}
 }
 
-// create a new post
+// create a new post, recording a post.created event with it
 func (s *postService) CreatePost(ctx context.Context, post *models.Post) (int64, error) {
-	return s.repository.Create(ctx, post)
+	var after models.Post
+	var err error
+
+	// another post may claim the same slug between the check and the insert
+	for attempt := 0; attempt < slugAttempts; attempt++ {
+		post.Slug, err = s.uniqueSlug(ctx, slug.Make(post.Title), 0)
+		if err != nil {
+			return 0, err
+		}
+
+		err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+			id, err := s.repository.Create(ctx, post)
+			if err != nil {
+				return err
+			}
+
+			after = *post
+			after.Id = id
+			return s.jobs.Publish(ctx, EventPostCreated, stored(&after))
+		})
+		if !errors.Is(err, repositories.ErrDuplicate) {
+			break
+		}
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	id := after.Id
+	recordAudit(ctx, s.audit, &models.AuditLog{
+		Action:     AuditPostCreated,
+		TargetType: AuditTargetPost,
+		TargetId:   &id,
+		After:      snapshot(stored(&after)),
+	}, nil)
+
+	s.notifyMentions(ctx, &after, nil)
+	publish(s.events, EventPostCreated, 0, stored(&after))
+
+	return id, nil
 }
 
-// find all posts
+// find all posts, with excerpts for listing
 func (s *postService) FindAll(ctx context.Context) ([]*models.Post, error) {
-	return s.repository.FindAll(ctx)
+	posts, err := s.repository.FindAll(ctx)
+	summarize(posts)
+	return posts, err
 }
 
-// find post by id
+// find post by id, with its body rendered to HTML
 func (s *postService) FindPostById(ctx context.Context, id int64) (*models.Post, error) {
-	return s.repository.FindById(ctx, id)
+	post, err := s.repository.FindById(ctx, id)
+	if err != nil || post == nil {
+		return post, err
+	}
+
+	return post, renderBody(post)
+}
+
+// find the posts of an author, with excerpts for listing
+func (s *postService) FindPostsByAuthor(ctx context.Context, authorId int64) ([]*models.Post, error) {
+	posts, err := s.repository.FindByAuthorId(ctx, authorId)
+	summarize(posts)
+	return posts, err
+}
+
+// count the posts of an author
+func (s *postService) CountPostsByAuthor(ctx context.Context, authorId int64) (int64, error) {
+	return s.repository.CountByAuthorId(ctx, authorId)
 }
 
-// update a post
+// find a post by its slug. A former slug finds the post too, with moved set
+// so callers can redirect to the current one.
+func (s *postService) FindPostBySlug(ctx context.Context, postSlug string) (*models.Post, bool, error) {
+	post, err := s.repository.FindBySlug(ctx, postSlug)
+	if err != nil {
+		return nil, false, err
+	}
+	if post != nil {
+		return post, false, renderBody(post)
+	}
+
+	id, err := s.repository.FindIdByOldSlug(ctx, postSlug)
+	if err != nil || id == 0 {
+		return nil, false, err
+	}
+
+	post, err = s.FindPostById(ctx, id)
+	return post, post != nil, err
+}
+
+// update a post, recording a post.updated event with it. A new title gets a
+// new slug, and the old one is kept so existing links keep working.
 func (s *postService) UpdatePost(ctx context.Context, post *models.Post) error {
-	return s.repository.Update(ctx, post)
+	var before *models.Post
+	var err error
+
+	// another post may claim the new slug between the check and the update
+	for attempt := 0; attempt < slugAttempts; attempt++ {
+		// the post stays locked from reading its current slug until it is replaced
+		err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+			var err error
+			if before, err = s.repository.FindById(ctx, post.Id); err != nil {
+				return err
+			}
+			if before == nil {
+				return s.repository.Update(ctx, post)
+			}
+
+			post.Slug = before.Slug
+			if base := slug.Make(post.Title); base != slug.Make(before.Title) {
+				if post.Slug, err = s.uniqueSlug(ctx, base, post.Id); err != nil {
+					return err
+				}
+			}
+
+			if post.Slug != before.Slug {
+				if err := s.repository.AddOldSlug(ctx, post.Id, before.Slug); err != nil && !errors.Is(err, repositories.ErrDuplicate) {
+					return err
+				}
+				// the post may be taking back one of its own former slugs
+				if err := s.repository.RemoveOldSlug(ctx, post.Id, post.Slug); err != nil {
+					return err
+				}
+			}
+
+			if err := s.repository.Update(ctx, post); err != nil {
+				return err
+			}
+
+			return s.jobs.Publish(ctx, EventPostUpdated, stored(post))
+		})
+		if !errors.Is(err, repositories.ErrDuplicate) {
+			break
+		}
+	}
+	if err != nil || before == nil {
+		return err
+	}
+
+	recordAudit(ctx, s.audit, &models.AuditLog{
+		Action:     AuditPostUpdated,
+		TargetType: AuditTargetPost,
+		TargetId:   &post.Id,
+		Before:     snapshot(before),
+		After:      snapshot(stored(post)),
+	}, nil)
+
+	s.notifyMentions(ctx, post, before)
+	publish(s.events, EventPostUpdated, 0, stored(post))
+
+	return nil
 }
 
-// delete a post
+// delete a post, recording a post.deleted event with it
 func (s *postService) DeletePost(ctx context.Context, id int64) error {
-	return s.repository.Delete(ctx, id)
+	var before *models.Post
+
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		if before, err = s.repository.FindById(ctx, id); err != nil || before == nil {
+			return err
+		}
+
+		if err := s.repository.Delete(ctx, id); err != nil {
+			return err
+		}
+
+		return s.jobs.Publish(ctx, EventPostDeleted, map[string]int64{"id": id})
+	})
+	if err != nil || before == nil {
+		return err
+	}
+
+	recordAudit(ctx, s.audit, &models.AuditLog{
+		Action:     AuditPostDeleted,
+		TargetType: AuditTargetPost,
+		TargetId:   &id,
+		Before:     snapshot(before),
+	}, nil)
+
+	publish(s.events, EventPostDeleted, 0, map[string]int64{"id": id})
+
+	return nil
+}
+
+// notifyMentions notifies the users mentioned in a post, skipping those
+// already mentioned in its previous version.
+func (s *postService) notifyMentions(ctx context.Context, post, previous *models.Post) {
+	var mentioned []int64
+	if previous != nil {
+		mentioned = markup.Mentions(previous.Body, maxMentions)
+	}
+
+	for _, userId := range markup.Mentions(post.Body, maxMentions) {
+		if slices.Contains(mentioned, userId) {
+			continue
+		}
+
+		notify(ctx, s.notifications, &models.Notification{
+			UserId:  userId,
+			ActorId: &post.AuthorId,
+			Type:    models.NotificationMention,
+			PostId:  &post.Id,
+		}, nil)
+	}
+}
+
+// renderBody fills in the HTML body of a post, along with its summary.
+func renderBody(post *models.Post) error {
+	html, err := markup.Render(post.Body, post.Format == models.PostFormatMarkdown)
+	if err != nil {
+		return err
+	}
+
+	post.BodyHTML = html
+	summarize([]*models.Post{post})
+	return nil
+}
+
+// summarize fills in the excerpt and reading time of posts.
+func summarize(posts []*models.Post) {
+	for _, post := range posts {
+		text := markup.PlainText(post.Body, post.Format == models.PostFormatMarkdown)
+		post.Excerpt = markup.Excerpt(text, ExcerptLength)
+		post.ReadingTime = markup.ReadingTime(text)
+	}
+}
+
+// stored returns a copy of the post without the fields derived on read.
+func stored(post *models.Post) *models.Post {
+	clone := *post
+	clone.BodyHTML, clone.Excerpt, clone.ReadingTime = "", "", 0
+	return &clone
+}
+
+// uniqueSlug returns base, or base with the lowest numeric suffix, that no
+// post other than postId uses now or has used before.
+func (s *postService) uniqueSlug(ctx context.Context, base string, postId int64) (string, error) {
+	slugs, err := s.repository.FindTakenSlugs(ctx, base, postId)
+	if err != nil {
+		return "", err
+	}
+
+	taken := make(map[string]bool, len(slugs))
+	for _, used := range slugs {
+		taken[used] = true
+	}
+
+	// at most len(slugs) candidates can be taken
+	candidate := base
+	for n := 2; taken[candidate]; n++ {
+		candidate = base + "-" + strconv.Itoa(n)
+	}
+
+	return candidate, nil
 }
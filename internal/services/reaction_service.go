@@ -0,0 +1,140 @@
+package services
+
+import (
+	"context"
+	"errors"
+
+	"github.com/achintha-dilshan/go-rest-api/internal/models"
+	"github.com/achintha-dilshan/go-rest-api/internal/repositories"
+)
+
+// ErrInvalidReaction is returned for an emoji that is not on the allowlist.
+var ErrInvalidReaction = errors.New("invalid reaction")
+
+// the emoji posts can be reacted with, by name
+var reactionEmoji = map[string]string{
+	"like":      "👍",
+	"love":      "❤️",
+	"laugh":     "😂",
+	"wow":       "😮",
+	"sad":       "😢",
+	"celebrate": "🎉",
+	"fire":      "🔥",
+}
+
+// emoji that are accepted in place of an allowlisted one
+var reactionVariants = map[string]string{
+	"❤": "❤️", // without the emoji presentation selector
+}
+
+type reactionService struct {
+	repository    repositories.ReactionRepository
+	notifications NotificationService
+}
+
+type ReactionService interface {
+	AddReaction(ctx context.Context, post *models.Post, userId int64, emoji string) (string, error)
+	RemoveReaction(ctx context.Context, post *models.Post, userId int64, emoji string) (string, error)
+	FindReactions(ctx context.Context, postId int64, emoji string, limit, offset int) ([]*models.Reaction, int64, error)
+	AttachCounts(ctx context.Context, posts ...*models.Post) error
+}
+
+func NewReactionService(repository repositories.ReactionRepository, notifications NotificationService) ReactionService {
+	return &reactionService{
+		repository:    repository,
+		notifications: notifications,
+	}
+}
+
+// react to a post, returning the stored emoji. The author is notified of
+// new reactions only, so repeating one does not notify them again.
+func (s *reactionService) AddReaction(ctx context.Context, post *models.Post, userId int64, emoji string) (string, error) {
+	emoji, ok := NormalizeReaction(emoji)
+	if !ok {
+		return "", ErrInvalidReaction
+	}
+
+	added, err := s.repository.Add(ctx, post.Id, userId, emoji)
+	if err != nil {
+		return "", err
+	}
+
+	if added {
+		notify(ctx, s.notifications, &models.Notification{
+			UserId:  post.AuthorId,
+			ActorId: &userId,
+			Type:    models.NotificationReaction,
+			PostId:  &post.Id,
+		}, map[string]string{"emoji": emoji})
+	}
+
+	return emoji, nil
+}
+
+// take back a reaction, returning the removed emoji
+func (s *reactionService) RemoveReaction(ctx context.Context, post *models.Post, userId int64, emoji string) (string, error) {
+	emoji, ok := NormalizeReaction(emoji)
+	if !ok {
+		return "", ErrInvalidReaction
+	}
+
+	return emoji, s.repository.Remove(ctx, post.Id, userId, emoji)
+}
+
+// list who reacted to a post; an empty emoji lists every reaction
+func (s *reactionService) FindReactions(ctx context.Context, postId int64, emoji string, limit, offset int) ([]*models.Reaction, int64, error) {
+	if emoji != "" {
+		var ok bool
+		if emoji, ok = NormalizeReaction(emoji); !ok {
+			return nil, 0, ErrInvalidReaction
+		}
+	}
+
+	return s.repository.FindByPostId(ctx, postId, emoji, limit, offset)
+}
+
+// fill in the reaction counts of the posts with one query
+func (s *reactionService) AttachCounts(ctx context.Context, posts ...*models.Post) error {
+	ids := make([]int64, 0, len(posts))
+	for _, post := range posts {
+		if post != nil {
+			ids = append(ids, post.Id)
+		}
+	}
+
+	counts, err := s.repository.CountByPostIds(ctx, ids)
+	if err != nil {
+		return err
+	}
+
+	for _, post := range posts {
+		if post == nil {
+			continue
+		}
+
+		post.Reactions = counts[post.Id]
+		if post.Reactions == nil {
+			post.Reactions = map[string]int64{}
+		}
+	}
+
+	return nil
+}
+
+// NormalizeReaction maps a reaction name or emoji to its allowlisted emoji.
+func NormalizeReaction(value string) (string, bool) {
+	if emoji, ok := reactionEmoji[value]; ok {
+		return emoji, true
+	}
+	if emoji, ok := reactionVariants[value]; ok {
+		return emoji, true
+	}
+
+	for _, emoji := range reactionEmoji {
+		if emoji == value {
+			return emoji, true
+		}
+	}
+
+	return "", false
+}
@@ -0,0 +1,57 @@
+package services
+
+import (
+	"database/sql"
+
+	"github.com/achintha-dilshan/go-rest-api/config"
+	"github.com/achintha-dilshan/go-rest-api/internal/repositories"
+	"github.com/achintha-dilshan/go-rest-api/internal/utils/broker"
+	"github.com/achintha-dilshan/go-rest-api/internal/utils/storage"
+)
+
+// Services holds one instance of each service, wired to each other and to
+// the repositories, so routes and commands share a single graph.
+type Services struct {
+	Audit        AuditService
+	Job          JobService
+	Notification NotificationService
+	User         UserService
+	Post         PostService
+	Session      SessionService
+	Follow       FollowService
+	Reaction     ReactionService
+	MagicLink    MagicLinkService
+	Passkey      PasskeyService
+	Webhook      WebhookService
+	Media        MediaService
+
+	// Storage is where the media service keeps uploads.
+	Storage storage.Storage
+}
+
+// New builds every service on db. events may be nil outside the API server,
+// where nobody listens for live events.
+func New(db *sql.DB, events *broker.Broker) *Services {
+	txManager := repositories.NewTxManager(db)
+	postRepository := repositories.NewPostRepository(db)
+	store := storage.FromConfig()
+
+	s := &Services{Storage: store}
+	s.Audit = NewAuditService(repositories.NewAuditLogRepository(db))
+	s.Job = NewJobService(repositories.NewJobRepository(db), repositories.NewOutboxRepository(db))
+	s.Notification = NewNotificationService(repositories.NewNotificationRepository(db), events)
+	s.User = NewUserService(repositories.NewUserRepository(db), postRepository, txManager, s.Audit, s.Job, events)
+	s.Post = NewPostService(postRepository, txManager, s.Audit, s.Notification, s.Job, events)
+	s.Session = NewSessionService(repositories.NewSessionRepository(db), s.Audit)
+	s.Follow = NewFollowService(repositories.NewFollowRepository(db), postRepository, s.Notification)
+	s.Reaction = NewReactionService(repositories.NewReactionRepository(db), s.Notification)
+	s.MagicLink = NewMagicLinkService(repositories.NewMagicLinkRepository(db))
+	s.Passkey = NewPasskeyService(repositories.NewPasskeyRepository(db))
+	s.Webhook = NewWebhookService(repositories.NewWebhookRepository(db))
+	s.Media = NewMediaService(repositories.NewMediaRepository(db), store, MediaOptions{
+		ThumbnailSize: config.Env.MediaThumbnailSize,
+		AvatarSize:    config.Env.AvatarSize,
+	})
+
+	return s
+}
@@ -0,0 +1,105 @@
+package services
+
+import (
+	"context"
+	"errors"
+
+	"github.com/achintha-dilshan/go-rest-api/internal/models"
+	"github.com/achintha-dilshan/go-rest-api/internal/repositories"
+)
+
+type sessionService struct {
+	repository repositories.SessionRepository
+	audit      AuditService
+}
+
+type SessionService interface {
+	CreateSession(ctx context.Context, session *models.Session) (int64, error)
+	CreateImpersonationSession(ctx context.Context, session *models.Session) (int64, error)
+	FindSessionById(ctx context.Context, id int64) (*models.Session, error)
+	FindUserSessions(ctx context.Context, userId int64) ([]*models.Session, error)
+	TouchSession(ctx context.Context, id int64) error
+	RevokeSession(ctx context.Context, id int64) error
+	RevokeUserSessions(ctx context.Context, userId int64) error
+}
+
+func NewSessionService(repository repositories.SessionRepository, audit AuditService) SessionService {
+	return &sessionService{
+		repository: repository,
+		audit:      audit,
+	}
+}
+
+// create a new session, recording the login in the audit log
+func (s *sessionService) CreateSession(ctx context.Context, session *models.Session) (int64, error) {
+	id, err := s.repository.Create(ctx, session)
+	if err != nil {
+		return 0, err
+	}
+
+	// impersonation sessions are audited by CreateImpersonationSession
+	if session.ImpersonatorId == nil {
+		recordAudit(ctx, s.audit, &models.AuditLog{
+			ActorId:    &session.UserId,
+			Action:     AuditUserLogin,
+			TargetType: AuditTargetUser,
+			TargetId:   &session.UserId,
+			IPAddress:  session.IPAddress,
+		}, map[string]interface{}{
+			"session_id": id,
+			"user_agent": session.UserAgent,
+		})
+	}
+
+	return id, nil
+}
+
+// create a session for an admin acting as a user, recording the
+// impersonation in the audit log
+func (s *sessionService) CreateImpersonationSession(ctx context.Context, session *models.Session) (int64, error) {
+	if session.ImpersonatorId == nil {
+		return 0, errors.New("an impersonation session needs an impersonator")
+	}
+
+	id, err := s.repository.Create(ctx, session)
+	if err != nil {
+		return 0, err
+	}
+
+	recordAudit(ctx, s.audit, &models.AuditLog{
+		ActorId:    session.ImpersonatorId,
+		Action:     AuditUserImpersonated,
+		TargetType: AuditTargetUser,
+		TargetId:   &session.UserId,
+		IPAddress:  session.IPAddress,
+	}, map[string]int64{
+		"session_id": id,
+	})
+
+	return id, nil
+}
+
+// find session by id
+func (s *sessionService) FindSessionById(ctx context.Context, id int64) (*models.Session, error) {
+	return s.repository.FindById(ctx, id)
+}
+
+// find the active sessions of a user
+func (s *sessionService) FindUserSessions(ctx context.Context, userId int64) ([]*models.Session, error) {
+	return s.repository.FindActiveByUserId(ctx, userId)
+}
+
+// refresh the last seen time of a session
+func (s *sessionService) TouchSession(ctx context.Context, id int64) error {
+	return s.repository.Touch(ctx, id)
+}
+
+// revoke a session
+func (s *sessionService) RevokeSession(ctx context.Context, id int64) error {
+	return s.repository.Revoke(ctx, id)
+}
+
+// revoke all sessions of a user
+func (s *sessionService) RevokeUserSessions(ctx context.Context, userId int64) error {
+	return s.repository.RevokeAllForUser(ctx, userId)
+}
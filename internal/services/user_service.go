@@ -2,31 +2,67 @@ package services
 
 import (
 	"context"
+	"time"
 
 	"github.com/achintha-dilshan/go-rest-api/internal/models"
 	"github.com/achintha-dilshan/go-rest-api/internal/repositories"
+	"github.com/achintha-dilshan/go-rest-api/internal/utils/broker"
 )
 
 type userService struct {
 	repository repositories.UserRepository
+	posts      repositories.PostRepository
+	tx         repositories.TxManager
+	audit      AuditService
+	jobs       JobService
+	events     *broker.Broker
 }
 
 type UserService interface {
 	CreateUser(ctx context.Context, user *models.User) (int64, error)
 	FindUserById(ctx context.Context, id int64) (*models.User, error)
 	UpdateUser(ctx context.Context, user *models.User) error
+	UpgradePasswordHash(ctx context.Context, user *models.User) error
+	RecordFailedLogin(ctx context.Context, email string, user *models.User)
 	DeleteUser(ctx context.Context, id int64) error
 	ExistUserByEmail(ctx context.Context, email string) (bool, error)
 	FindUserByEmail(ctx context.Context, email string) (*models.User, error)
+	SearchUsers(ctx context.Context, term string, limit, offset int) ([]*models.User, int64, error)
+	SuspendUser(ctx context.Context, id int64) error
+	UnsuspendUser(ctx context.Context, id int64) error
+	RequirePasswordReset(ctx context.Context, id int64) error
+	SetUserRole(ctx context.Context, id int64, role string) error
 }
 
-func NewUserService(repository repositories.UserRepository) UserService {
-	return &userService{repository: repository}
+func NewUserService(repository repositories.UserRepository, posts repositories.PostRepository, tx repositories.TxManager, audit AuditService, jobs JobService, events *broker.Broker) UserService {
+	return &userService{
+		repository: repository,
+		posts:      posts,
+		tx:         tx,
+		audit:      audit,
+		jobs:       jobs,
+		events:     events,
+	}
 }
 
-// create a new user
+// create a new user, recording a user.registered event with their public profile
 func (s *userService) CreateUser(ctx context.Context, user *models.User) (int64, error) {
-	return s.repository.Create(ctx, user)
+	var id int64
+	user.Email = models.NormalizeEmail(user.Email)
+
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		if id, err = s.repository.Create(ctx, user); err != nil {
+			return err
+		}
+
+		registered := *user
+		registered.Id = id
+		registered.CreatedAt = time.Now().UTC()
+		return s.jobs.Publish(ctx, EventUserRegistered, registered.Profile(0))
+	})
+
+	return id, err
 }
 
 // find user by id
@@ -34,22 +70,188 @@ func (s *userService) FindUserById(ctx context.Context, id int64) (*models.User,
 	return s.repository.FindById(ctx, id)
 }
 
-// update a user
+// update a user, recording a password change or profile update in the audit log
 func (s *userService) UpdateUser(ctx context.Context, user *models.User) error {
+	var before *models.User
+	user.Email = models.NormalizeEmail(user.Email)
+
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		if before, err = s.repository.FindById(ctx, user.Id); err != nil {
+			return err
+		}
+
+		return s.repository.Update(ctx, user)
+	})
+	if err != nil {
+		return err
+	}
+
+	if before == nil {
+		return nil
+	}
+
+	action := AuditUserUpdated
+	if before.Password != user.Password {
+		action = AuditUserPasswordChanged
+	}
+
+	beforeData, afterData := snapshot(before), snapshot(user)
+	if action == AuditUserUpdated && string(beforeData) == string(afterData) {
+		return nil
+	}
+
+	recordAudit(ctx, s.audit, &models.AuditLog{
+		Action:     action,
+		TargetType: AuditTargetUser,
+		TargetId:   &user.Id,
+		Before:     beforeData,
+		After:      afterData,
+	}, nil)
+
+	return nil
+}
+
+// store a password hash upgraded at login; the password itself is unchanged
+// so this is not audited
+func (s *userService) UpgradePasswordHash(ctx context.Context, user *models.User) error {
 	return s.repository.Update(ctx, user)
 }
 
-// delete a user
+// record a failed login attempt, with the user when the email is known
+func (s *userService) RecordFailedLogin(ctx context.Context, email string, user *models.User) {
+	entry := &models.AuditLog{
+		Action:     AuditUserLoginFailed,
+		TargetType: AuditTargetUser,
+	}
+
+	reason := "unknown_email"
+	if user != nil {
+		entry.TargetId = &user.Id
+		reason = "wrong_password"
+	}
+
+	recordAudit(ctx, s.audit, entry, map[string]string{
+		"email":  email,
+		"reason": reason,
+	})
+}
+
+// delete a user along with their posts, recording a post.deleted event and
+// audit log entry for each post
 func (s *userService) DeleteUser(ctx context.Context, id int64) error {
-	return s.repository.Delete(ctx, id)
+	var before *models.User
+	var posts []*models.Post
+
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		if before, err = s.repository.FindById(ctx, id); err != nil {
+			return err
+		}
+
+		if posts, err = s.posts.FindByAuthorId(ctx, id); err != nil {
+			return err
+		}
+
+		for _, post := range posts {
+			if err := s.posts.Delete(ctx, post.Id); err != nil {
+				return err
+			}
+			if err := s.jobs.Publish(ctx, EventPostDeleted, map[string]int64{"id": post.Id}); err != nil {
+				return err
+			}
+		}
+
+		return s.repository.Delete(ctx, id)
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, post := range posts {
+		recordAudit(ctx, s.audit, &models.AuditLog{
+			Action:     AuditPostDeleted,
+			TargetType: AuditTargetPost,
+			TargetId:   &post.Id,
+			Before:     snapshot(post),
+		}, nil)
+
+		publish(s.events, EventPostDeleted, 0, map[string]int64{"id": post.Id})
+	}
+
+	if before != nil {
+		recordAudit(ctx, s.audit, &models.AuditLog{
+			Action:     AuditUserDeleted,
+			TargetType: AuditTargetUser,
+			TargetId:   &id,
+			Before:     snapshot(before),
+		}, nil)
+	}
+
+	return nil
 }
 
-// exist user by email
+// exist user by email, in any case
 func (s *userService) ExistUserByEmail(ctx context.Context, email string) (bool, error) {
-	return s.repository.ExistsByEmail(ctx, email)
+	return s.repository.ExistsByEmail(ctx, models.NormalizeEmail(email))
 }
 
-// find user by email
+// find user by email, in any case
 func (s *userService) FindUserByEmail(ctx context.Context, email string) (*models.User, error) {
-	return s.repository.FindByEmail(ctx, email)
+	return s.repository.FindByEmail(ctx, models.NormalizeEmail(email))
+}
+
+// search users by name or email
+func (s *userService) SearchUsers(ctx context.Context, term string, limit, offset int) ([]*models.User, int64, error) {
+	return s.repository.Search(ctx, term, limit, offset)
+}
+
+// suspend a user, recording it in the audit log
+func (s *userService) SuspendUser(ctx context.Context, id int64) error {
+	if err := s.repository.SetSuspended(ctx, id, true); err != nil {
+		return err
+	}
+
+	s.recordAdminAction(ctx, AuditUserSuspended, id, nil)
+	return nil
+}
+
+// lift a user's suspension, recording it in the audit log
+func (s *userService) UnsuspendUser(ctx context.Context, id int64) error {
+	if err := s.repository.SetSuspended(ctx, id, false); err != nil {
+		return err
+	}
+
+	s.recordAdminAction(ctx, AuditUserUnsuspended, id, nil)
+	return nil
+}
+
+// require a user to change their password, recording it in the audit log
+func (s *userService) RequirePasswordReset(ctx context.Context, id int64) error {
+	if err := s.repository.SetPasswordResetRequired(ctx, id, true); err != nil {
+		return err
+	}
+
+	s.recordAdminAction(ctx, AuditUserPasswordResetForced, id, nil)
+	return nil
+}
+
+// change the role of a user, recording it in the audit log
+func (s *userService) SetUserRole(ctx context.Context, id int64, role string) error {
+	if err := s.repository.SetRole(ctx, id, role); err != nil {
+		return err
+	}
+
+	s.recordAdminAction(ctx, AuditUserRoleChanged, id, map[string]string{"role": role})
+	return nil
+}
+
+// recordAdminAction writes an action taken on a user to the audit log. The
+// actor is the user of the request, or none when run from the command line.
+func (s *userService) recordAdminAction(ctx context.Context, action string, id int64, metadata any) {
+	recordAudit(ctx, s.audit, &models.AuditLog{
+		Action:     action,
+		TargetType: AuditTargetUser,
+		TargetId:   &id,
+	}, metadata)
 }
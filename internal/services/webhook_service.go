@@ -0,0 +1,122 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+
+	"github.com/achintha-dilshan/go-rest-api/internal/models"
+	"github.com/achintha-dilshan/go-rest-api/internal/repositories"
+	"github.com/achintha-dilshan/go-rest-api/internal/utils/webhook"
+)
+
+// ErrUnknownWebhookEvent is returned for a subscription to an event that does not exist.
+var ErrUnknownWebhookEvent = errors.New("unknown webhook event")
+
+// ErrTooManyWebhooks is returned when a user already has the most webhooks allowed.
+var ErrTooManyWebhooks = errors.New("too many webhooks")
+
+// the most webhooks a user can have
+const maxWebhooksPerUser = 10
+
+// WebhookEvents lists the events webhooks can subscribe to.
+var WebhookEvents = []string{EventPostCreated, EventPostUpdated, EventPostDeleted, EventUserRegistered}
+
+type webhookService struct {
+	repository repositories.WebhookRepository
+}
+
+type WebhookService interface {
+	CreateWebhook(ctx context.Context, webhook *models.Webhook) (int64, error)
+	FindWebhookById(ctx context.Context, id int64) (*models.Webhook, error)
+	FindWebhooksByUser(ctx context.Context, userId int64) ([]*models.Webhook, error)
+	UpdateWebhook(ctx context.Context, webhook *models.Webhook) error
+	DeleteWebhook(ctx context.Context, id int64) error
+	FindDeliveries(ctx context.Context, webhookId int64, limit, offset int) ([]*models.WebhookDelivery, int64, error)
+	Dispatch(ctx context.Context, event string, data any) error
+}
+
+func NewWebhookService(repository repositories.WebhookRepository) WebhookService {
+	return &webhookService{
+		repository: repository,
+	}
+}
+
+// create a new webhook with a freshly generated signing secret
+func (s *webhookService) CreateWebhook(ctx context.Context, hook *models.Webhook) (int64, error) {
+	if err := validWebhookEvents(hook.Events); err != nil {
+		return 0, err
+	}
+
+	existing, err := s.repository.FindByUserId(ctx, hook.UserId)
+	if err != nil {
+		return 0, err
+	}
+	if len(existing) >= maxWebhooksPerUser {
+		return 0, ErrTooManyWebhooks
+	}
+
+	secret, err := webhook.NewSecret()
+	if err != nil {
+		return 0, err
+	}
+	hook.Secret = secret
+
+	return s.repository.Create(ctx, hook)
+}
+
+// find webhook by id
+func (s *webhookService) FindWebhookById(ctx context.Context, id int64) (*models.Webhook, error) {
+	return s.repository.FindById(ctx, id)
+}
+
+// find the webhooks of a user
+func (s *webhookService) FindWebhooksByUser(ctx context.Context, userId int64) ([]*models.Webhook, error) {
+	return s.repository.FindByUserId(ctx, userId)
+}
+
+// update a webhook. Turning a disabled webhook back on gives it a clean slate.
+func (s *webhookService) UpdateWebhook(ctx context.Context, hook *models.Webhook) error {
+	if err := validWebhookEvents(hook.Events); err != nil {
+		return err
+	}
+
+	if hook.Active && hook.DisabledAt != nil {
+		hook.FailureCount = 0
+		hook.DisabledAt = nil
+	}
+
+	return s.repository.Update(ctx, hook)
+}
+
+// delete a webhook along with its delivery log
+func (s *webhookService) DeleteWebhook(ctx context.Context, id int64) error {
+	return s.repository.Delete(ctx, id)
+}
+
+// find the deliveries of a webhook, newest first
+func (s *webhookService) FindDeliveries(ctx context.Context, webhookId int64, limit, offset int) ([]*models.WebhookDelivery, int64, error) {
+	return s.repository.FindDeliveries(ctx, webhookId, limit, offset)
+}
+
+// queue an event, with data encoded as JSON, for every webhook subscribed to it
+func (s *webhookService) Dispatch(ctx context.Context, event string, data any) error {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.repository.CreateDeliveries(ctx, event, raw)
+	return err
+}
+
+// validWebhookEvents checks that a webhook subscribes to known events only.
+func validWebhookEvents(events []string) error {
+	for _, event := range events {
+		if !slices.Contains(WebhookEvents, event) {
+			return ErrUnknownWebhookEvent
+		}
+	}
+	return nil
+}
@@ -0,0 +1,189 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/achintha-dilshan/go-rest-api/internal/models"
+	"github.com/achintha-dilshan/go-rest-api/internal/repositories"
+	"github.com/achintha-dilshan/go-rest-api/internal/utils/webhook"
+)
+
+// how the worker looks for deliveries that are due
+const (
+	webhookPollInterval = 5 * time.Second
+	webhookBatchSize    = 32
+	webhookConcurrency  = 8
+	// long enough for a batch to finish before its deliveries are due again
+	webhookLease = 5 * time.Minute
+)
+
+// the longest error message kept in the delivery log
+const maxDeliveryError = 255
+
+// WebhookOptions configures webhook delivery.
+type WebhookOptions struct {
+	// MaxAttempts is the number of attempts before a delivery is given up on.
+	MaxAttempts int
+	// DisableAfter is the number of failed attempts in a row that disables a webhook.
+	DisableAfter int
+	Timeout      time.Duration
+	// AllowPrivate lets webhooks reach loopback and private network addresses.
+	AllowPrivate bool
+}
+
+type webhookWorker struct {
+	repository repositories.WebhookRepository
+	client     *http.Client
+	options    WebhookOptions
+}
+
+type WebhookWorker interface {
+	Run(ctx context.Context)
+}
+
+func NewWebhookWorker(repository repositories.WebhookRepository, options WebhookOptions) WebhookWorker {
+	return &webhookWorker{
+		repository: repository,
+		client:     webhook.NewClient(options.Timeout, options.AllowPrivate),
+		options:    options,
+	}
+}
+
+// webhookPayload is the body sent to a webhook. The ID stays the same across
+// retries, so receivers can ignore deliveries they have already handled.
+type webhookPayload struct {
+	Id        int64           `json:"id"`
+	Event     string          `json:"event"`
+	CreatedAt time.Time       `json:"createdAt"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// deliver due webhooks until ctx is done, returning once attempts under way have finished
+func (w *webhookWorker) Run(ctx context.Context) {
+	ticker := time.NewTicker(webhookPollInterval)
+	defer ticker.Stop()
+
+	for {
+		w.deliverDue(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// deliverDue sends batches of due deliveries until none are left.
+func (w *webhookWorker) deliverDue(ctx context.Context) {
+	for ctx.Err() == nil {
+		deliveries, err := w.repository.ClaimDeliveries(ctx, webhookBatchSize, webhookLease)
+		if err != nil {
+			if ctx.Err() == nil {
+				log.Printf("Failed to claim webhook deliveries: %v", err)
+			}
+			return
+		}
+		if len(deliveries) == 0 {
+			return
+		}
+
+		var wg sync.WaitGroup
+		slots := make(chan struct{}, webhookConcurrency)
+		for _, delivery := range deliveries {
+			wg.Add(1)
+			slots <- struct{}{}
+			go func() {
+				defer wg.Done()
+				defer func() { <-slots }()
+				w.deliver(ctx, delivery)
+			}()
+		}
+		wg.Wait()
+	}
+}
+
+// deliver makes one attempt at a delivery and records how it went.
+func (w *webhookWorker) deliver(ctx context.Context, delivery *models.WebhookDelivery) {
+	body, err := json.Marshal(webhookPayload{
+		Id:        delivery.Id,
+		Event:     delivery.Event,
+		CreatedAt: delivery.CreatedAt,
+		Data:      delivery.Payload,
+	})
+	if err != nil {
+		log.Printf("Failed to encode webhook delivery %d: %v", delivery.Id, err)
+		return
+	}
+
+	start := time.Now()
+	status, err := webhook.Send(ctx, w.client, webhook.Request{
+		URL:        delivery.URL,
+		Secret:     delivery.Secret,
+		Event:      delivery.Event,
+		DeliveryId: delivery.Id,
+		Body:       body,
+	})
+	if ctx.Err() != nil {
+		// cut short by shutdown; the delivery is due again once its lease runs out
+		return
+	}
+
+	duration := time.Since(start).Milliseconds()
+	delivery.Attempts++
+	delivery.DurationMs = &duration
+	delivery.ResponseCode = nil
+	if status != 0 {
+		delivery.ResponseCode = &status
+	}
+
+	delivery.Error = ""
+	if err != nil {
+		delivery.Error = err.Error()
+	} else if status < 200 || status > 299 {
+		delivery.Error = fmt.Sprintf("Unexpected response status %d.", status)
+	}
+	if len(delivery.Error) > maxDeliveryError {
+		// drop any character cut in half
+		delivery.Error = strings.ToValidUTF8(delivery.Error[:maxDeliveryError], "")
+	}
+
+	var retryIn time.Duration
+	switch {
+	case delivery.Error == "":
+		delivery.Status = models.DeliverySucceeded
+	case delivery.Attempts >= w.options.MaxAttempts:
+		delivery.Status = models.DeliveryFailed
+	default:
+		delivery.Status = models.DeliveryPending
+		retryIn = webhook.Backoff(delivery.Attempts)
+	}
+
+	if err := w.repository.RecordAttempt(ctx, delivery, retryIn); err != nil {
+		log.Printf("Failed to record webhook delivery %d: %v", delivery.Id, err)
+		return
+	}
+
+	if delivery.Status == models.DeliverySucceeded {
+		if err := w.repository.RecordSuccess(ctx, delivery.WebhookId); err != nil {
+			log.Printf("Failed to reset failures of webhook %d: %v", delivery.WebhookId, err)
+		}
+		return
+	}
+
+	disabled, err := w.repository.RecordFailure(ctx, delivery.WebhookId, w.options.DisableAfter)
+	if err != nil {
+		log.Printf("Failed to count failure of webhook %d: %v", delivery.WebhookId, err)
+		return
+	}
+	if disabled {
+		log.Printf("Disabled webhook %d after %d failed attempts in a row", delivery.WebhookId, w.options.DisableAfter)
+	}
+}
@@ -4,3 +4,8 @@ package types
 type contextKey string
 
 const UserIDKey contextKey = "userID"
+const SessionIDKey contextKey = "sessionID"
+const UserRoleKey contextKey = "userRole"
+const ImpersonatorIDKey contextKey = "impersonatorID"
+const RequestIDKey contextKey = "requestID"
+const ClientIPKey contextKey = "clientIP"
@@ -0,0 +1,30 @@
+// Package backoff computes delays between retries.
+package backoff
+
+import (
+	"math/rand/v2"
+	"time"
+)
+
+// Exponential returns base doubled for every attempt after the first, capped
+// at max.
+func Exponential(attempt int, base, max time.Duration) time.Duration {
+	delay := base
+	for i := 1; i < attempt; i++ {
+		delay *= 2
+		if delay >= max {
+			return max
+		}
+	}
+	return min(delay, max)
+}
+
+// Jitter returns a random delay between half of d and d, so clients retrying
+// the same failure do not all come back at once.
+func Jitter(d time.Duration) time.Duration {
+	if d <= 1 {
+		return d
+	}
+	half := d / 2
+	return half + rand.N(d-half+1)
+}
@@ -0,0 +1,44 @@
+package backoff
+
+import (
+	"testing"
+	"time"
+)
+
+func TestExponential(t *testing.T) {
+	cases := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{0, time.Second},
+		{1, time.Second},
+		{2, 2 * time.Second},
+		{4, 8 * time.Second},
+		{7, time.Minute},
+		{100, time.Minute},
+	}
+
+	for _, c := range cases {
+		if got := Exponential(c.attempt, time.Second, time.Minute); got != c.want {
+			t.Errorf("Expected '%v' after %d attempts, got '%v'", c.want, c.attempt, got)
+		}
+	}
+}
+
+func TestExponentialBaseAboveMax(t *testing.T) {
+	if got := Exponential(1, time.Hour, time.Minute); got != time.Minute {
+		t.Errorf("Expected '%v', got '%v'", time.Minute, got)
+	}
+}
+
+func TestJitter(t *testing.T) {
+	for i := 0; i < 100; i++ {
+		if got := Jitter(time.Second); got < time.Second/2 || got > time.Second {
+			t.Fatalf("Expected a delay between '%v' and '%v', got '%v'", time.Second/2, time.Second, got)
+		}
+	}
+
+	if got := Jitter(0); got != 0 {
+		t.Errorf("Expected '0s', got '%v'", got)
+	}
+}
@@ -0,0 +1,165 @@
+// Package broker is an in-process publish/subscribe hub for server events,
+// keeping the most recent events so reconnecting subscribers can catch up.
+package broker
+
+import (
+	"sync"
+	"time"
+)
+
+// SubscriberBuffer is the number of events a subscriber can fall behind by
+// before it is disconnected.
+const SubscriberBuffer = 64
+
+// Event is a published event. Events with a UserId are private to that user;
+// the rest go to every subscriber.
+type Event struct {
+	Id     uint64
+	Type   string
+	Data   []byte
+	UserId int64
+}
+
+// Subscription receives events until it is closed. Events stops, with its
+// channel closed, when the subscriber falls too far behind or the broker is
+// closed.
+type Subscription struct {
+	Events <-chan Event
+	events chan Event
+	userId int64
+}
+
+// Broker fans published events out to subscribers.
+type Broker struct {
+	mu     sync.Mutex
+	lastId uint64
+	replay []Event // ring buffer of the latest events
+	next   int     // index in replay the next event is written to
+	subs   map[*Subscription]struct{}
+	closed bool
+}
+
+// New creates a broker that keeps the last replaySize events. Event IDs start
+// from the current time so that they keep increasing across restarts, and
+// IDs from before a restart are never mistaken for newer ones.
+func New(replaySize int) *Broker {
+	return &Broker{
+		lastId: uint64(time.Now().UnixNano()),
+		replay: make([]Event, 0, max(replaySize, 1)),
+		subs:   make(map[*Subscription]struct{}),
+	}
+}
+
+// Publish sends an event to the subscribers allowed to see it. A userId of 0
+// makes the event public. Publishing on a nil broker does nothing.
+func (b *Broker) Publish(eventType string, userId int64, data []byte) {
+	if b == nil {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return
+	}
+
+	b.lastId++
+	event := Event{Id: b.lastId, Type: eventType, Data: data, UserId: userId}
+
+	if len(b.replay) < cap(b.replay) {
+		b.replay = append(b.replay, event)
+	} else {
+		b.replay[b.next] = event
+	}
+	b.next = (b.next + 1) % cap(b.replay)
+
+	for sub := range b.subs {
+		if !visible(event, sub.userId) {
+			continue
+		}
+
+		select {
+		case sub.events <- event:
+		default:
+			// slow subscribers are dropped rather than holding up everyone else
+			b.remove(sub)
+		}
+	}
+}
+
+// Subscribe registers a subscriber for userId, or for public events only
+// when userId is 0. With a lastId, the events after it that are still kept
+// are returned for replay; complete is false when some of them are gone, or
+// lastId is not one this broker issued.
+func (b *Broker) Subscribe(userId int64, lastId *uint64) (sub *Subscription, missed []Event, complete bool) {
+	events := make(chan Event, SubscriberBuffer)
+	sub = &Subscription{Events: events, events: events, userId: userId}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		close(events)
+		return sub, nil, false
+	}
+	b.subs[sub] = struct{}{}
+
+	if lastId == nil {
+		return sub, nil, true
+	}
+
+	kept := b.ordered()
+	complete = *lastId <= b.lastId
+	if len(kept) > 0 && *lastId < kept[0].Id-1 {
+		complete = false
+	}
+
+	for _, event := range kept {
+		if event.Id > *lastId && visible(event, userId) {
+			missed = append(missed, event)
+		}
+	}
+
+	return sub, missed, complete
+}
+
+// Unsubscribe stops a subscription. It is safe to call more than once.
+func (b *Broker) Unsubscribe(sub *Subscription) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.remove(sub)
+}
+
+// Close disconnects every subscriber and stops accepting events.
+func (b *Broker) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.closed = true
+	for sub := range b.subs {
+		b.remove(sub)
+	}
+}
+
+// remove closes a subscription; b.mu must be held.
+func (b *Broker) remove(sub *Subscription) {
+	if _, ok := b.subs[sub]; ok {
+		delete(b.subs, sub)
+		close(sub.events)
+	}
+}
+
+// ordered returns the kept events, oldest first; b.mu must be held.
+func (b *Broker) ordered() []Event {
+	if len(b.replay) < cap(b.replay) {
+		return append([]Event(nil), b.replay...)
+	}
+	return append(append([]Event(nil), b.replay[b.next:]...), b.replay[:b.next]...)
+}
+
+// visible reports whether a subscriber for userId may see event.
+func visible(event Event, userId int64) bool {
+	return event.UserId == 0 || event.UserId == userId
+}
@@ -0,0 +1,114 @@
+package broker
+
+import (
+	"testing"
+)
+
+func receive(t *testing.T, sub *Subscription) Event {
+	t.Helper()
+
+	select {
+	case event, ok := <-sub.Events:
+		if !ok {
+			t.Fatalf("Expected an event, got a closed subscription")
+		}
+		return event
+	default:
+		t.Fatalf("Expected an event, got none")
+	}
+	return Event{}
+}
+
+func TestPublishRespectsAudience(t *testing.T) {
+	b := New(10)
+	anonymous, _, _ := b.Subscribe(0, nil)
+	user, _, _ := b.Subscribe(7, nil)
+
+	b.Publish("post.created", 0, []byte(`{}`))
+	b.Publish("notification.created", 7, []byte(`{}`))
+
+	if event := receive(t, anonymous); event.Type != "post.created" {
+		t.Errorf("Expected 'post.created', got '%s'", event.Type)
+	}
+	if len(anonymous.Events) != 0 {
+		t.Errorf("Expected private events to be withheld from other subscribers")
+	}
+
+	first, second := receive(t, user), receive(t, user)
+	if first.Type != "post.created" || second.Type != "notification.created" {
+		t.Errorf("Expected both events in order, got '%s' and '%s'", first.Type, second.Type)
+	}
+	if second.Id != first.Id+1 {
+		t.Errorf("Expected consecutive IDs, got %d and %d", first.Id, second.Id)
+	}
+}
+
+func TestSubscribeReplaysMissedEvents(t *testing.T) {
+	b := New(3)
+	sub, _, _ := b.Subscribe(0, nil)
+	b.Publish("a", 0, nil)
+	last := receive(t, sub).Id
+	b.Unsubscribe(sub)
+
+	b.Publish("b", 0, nil)
+	b.Publish("c", 0, nil)
+
+	_, missed, complete := b.Subscribe(0, &last)
+	if !complete || len(missed) != 2 || missed[0].Type != "b" || missed[1].Type != "c" {
+		t.Errorf("Expected complete replay of 'b' and 'c', got %v (%v)", missed, complete)
+	}
+
+	// the buffer only holds three events, so "a" and "b" are now gone
+	b.Publish("d", 0, nil)
+	b.Publish("e", 0, nil)
+	before := last - 1
+	_, missed, complete = b.Subscribe(0, &before)
+	if complete || len(missed) != 3 || missed[0].Type != "c" {
+		t.Errorf("Expected incomplete replay starting at 'c', got %v (%v)", missed, complete)
+	}
+
+	future := last + 100
+	if _, missed, complete = b.Subscribe(0, &future); complete || len(missed) != 0 {
+		t.Errorf("Expected an unknown ID to be reported incomplete, got %v (%v)", missed, complete)
+	}
+}
+
+func TestSlowSubscriberIsDropped(t *testing.T) {
+	b := New(1)
+	slow, _, _ := b.Subscribe(0, nil)
+
+	for i := 0; i <= SubscriberBuffer; i++ {
+		b.Publish("tick", 0, nil)
+	}
+
+	for i := 0; i < SubscriberBuffer; i++ {
+		receive(t, slow)
+	}
+	if _, ok := <-slow.Events; ok {
+		t.Errorf("Expected the slow subscriber to be closed")
+	}
+
+	// unsubscribing a dropped subscriber is harmless
+	b.Unsubscribe(slow)
+}
+
+func TestCloseEndsSubscriptions(t *testing.T) {
+	b := New(1)
+	sub, _, _ := b.Subscribe(0, nil)
+	b.Close()
+
+	if _, ok := <-sub.Events; ok {
+		t.Errorf("Expected the subscription to be closed")
+	}
+
+	b.Publish("ignored", 0, nil)
+	late, _, _ := b.Subscribe(0, nil)
+	if _, ok := <-late.Events; ok {
+		t.Errorf("Expected subscriptions after Close to be closed")
+	}
+}
+
+func TestNilBrokerPublish(t *testing.T) {
+	var b *Broker
+	b.Publish("ignored", 0, nil)
+}
@@ -0,0 +1,50 @@
+// Package cursor encodes keyset pagination positions as opaque tokens.
+package cursor
+
+import (
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalid is returned for a token that was not made by Encode.
+var ErrInvalid = errors.New("invalid cursor")
+
+// Cursor is the position of a row ordered by creation time, then ID.
+type Cursor struct {
+	CreatedAt time.Time
+	Id        int64
+}
+
+// Encode returns the token for the position.
+func Encode(c Cursor) string {
+	raw := strconv.FormatInt(c.CreatedAt.UnixNano(), 10) + ":" + strconv.FormatInt(c.Id, 10)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// Decode reads a token made by Encode.
+func Decode(token string) (*Cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, ErrInvalid
+	}
+
+	nanos, id, ok := strings.Cut(string(raw), ":")
+	if !ok {
+		return nil, ErrInvalid
+	}
+
+	n, err := strconv.ParseInt(nanos, 10, 64)
+	if err != nil {
+		return nil, ErrInvalid
+	}
+
+	i, err := strconv.ParseInt(id, 10, 64)
+	if err != nil || i < 1 {
+		return nil, ErrInvalid
+	}
+
+	return &Cursor{CreatedAt: time.Unix(0, n).UTC(), Id: i}, nil
+}
@@ -0,0 +1,34 @@
+package cursor
+
+import (
+	"encoding/base64"
+	"testing"
+	"time"
+)
+
+func TestEncodeDecode(t *testing.T) {
+	c := Cursor{CreatedAt: time.Date(2026, 10, 19, 10, 30, 0, 123, time.UTC), Id: 42}
+
+	got, err := Decode(Encode(c))
+	if err != nil {
+		t.Fatalf("Expected cursor to decode, got '%v'", err)
+	}
+	if !got.CreatedAt.Equal(c.CreatedAt) || got.Id != c.Id {
+		t.Errorf("Expected '%v', got '%v'", c, *got)
+	}
+}
+
+func TestDecodeRejectsInvalidTokens(t *testing.T) {
+	for _, token := range []string{
+		"",
+		"not base64!",
+		base64.RawURLEncoding.EncodeToString([]byte("123")),
+		base64.RawURLEncoding.EncodeToString([]byte("abc:1")),
+		base64.RawURLEncoding.EncodeToString([]byte("123:abc")),
+		base64.RawURLEncoding.EncodeToString([]byte("123:0")),
+	} {
+		if _, err := Decode(token); err != ErrInvalid {
+			t.Errorf("Decode(%q): expected '%v', got '%v'", token, ErrInvalid, err)
+		}
+	}
+}
@@ -0,0 +1,225 @@
+// Package hub keeps track of live client connections: who is online, who is
+// watching whose presence, and closing every connection on shutdown.
+package hub
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+)
+
+// SendBuffer is the number of messages a client can fall behind by before it
+// is disconnected.
+const SendBuffer = 64
+
+// ErrClosed is returned when joining a hub that is shutting down.
+var ErrClosed = errors.New("hub closed")
+
+// Client is a connection registered with the hub. Messages for it are queued
+// on Send, and Done is closed when the hub wants the connection gone.
+type Client struct {
+	UserId int64
+	Send   chan []byte
+
+	done     chan struct{}
+	once     sync.Once
+	watching map[int64]struct{}
+}
+
+// Done is closed when the client falls behind or the hub shuts down.
+func (c *Client) Done() <-chan struct{} {
+	return c.done
+}
+
+func (c *Client) stop() {
+	c.once.Do(func() { close(c.done) })
+}
+
+// Presence is the message sent to watchers when a user comes online or goes offline.
+type Presence struct {
+	Type   string `json:"type"`
+	UserId int64  `json:"user_id"`
+	Online bool   `json:"online"`
+}
+
+// Hub tracks the connected clients.
+type Hub struct {
+	mu       sync.Mutex
+	clients  map[*Client]struct{}
+	online   map[int64]int                  // connections per user
+	watchers map[int64]map[*Client]struct{} // clients watching each user's presence
+	closed   bool
+	wg       sync.WaitGroup
+}
+
+func New() *Hub {
+	return &Hub{
+		clients:  make(map[*Client]struct{}),
+		online:   make(map[int64]int),
+		watchers: make(map[int64]map[*Client]struct{}),
+	}
+}
+
+// Join registers a connection of a user, who comes online with their first one.
+// Every Join must be followed by a Leave.
+func (h *Hub) Join(userId int64) (*Client, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.closed {
+		return nil, ErrClosed
+	}
+
+	c := &Client{
+		UserId:   userId,
+		Send:     make(chan []byte, SendBuffer),
+		done:     make(chan struct{}),
+		watching: make(map[int64]struct{}),
+	}
+	h.clients[c] = struct{}{}
+	h.wg.Add(1)
+
+	h.online[userId]++
+	if h.online[userId] == 1 {
+		h.announce(userId, true)
+	}
+
+	return c, nil
+}
+
+// Leave unregisters a connection; the user goes offline with their last one.
+func (h *Hub) Leave(c *Client) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if _, ok := h.clients[c]; !ok {
+		return
+	}
+	delete(h.clients, c)
+	c.stop()
+
+	for userId := range c.watching {
+		h.unwatch(c, userId)
+	}
+
+	h.online[c.UserId]--
+	if h.online[c.UserId] == 0 {
+		delete(h.online, c.UserId)
+		h.announce(c.UserId, false)
+	}
+
+	h.wg.Done()
+}
+
+// Watch subscribes a client to the presence of users, returning whether each
+// is online now.
+func (h *Hub) Watch(c *Client, userIds []int64) map[int64]bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	online := make(map[int64]bool, len(userIds))
+	for _, userId := range userIds {
+		if h.watchers[userId] == nil {
+			h.watchers[userId] = make(map[*Client]struct{})
+		}
+		h.watchers[userId][c] = struct{}{}
+		c.watching[userId] = struct{}{}
+		online[userId] = h.online[userId] > 0
+	}
+
+	return online
+}
+
+// Unwatch stops presence updates about users.
+func (h *Hub) Unwatch(c *Client, userIds []int64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for _, userId := range userIds {
+		h.unwatch(c, userId)
+	}
+}
+
+// Watching returns the number of users a client watches.
+func (h *Hub) Watching(c *Client) int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return len(c.watching)
+}
+
+// Online reports whether a user has a connection.
+func (h *Hub) Online(userId int64) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return h.online[userId] > 0
+}
+
+// Deliver queues a message for a client without blocking. A client whose
+// queue is full is told to disconnect, so one slow reader never holds up the
+// others; it reports whether the message was queued.
+func (h *Hub) Deliver(c *Client, message []byte) bool {
+	select {
+	case <-c.done:
+		return false
+	default:
+	}
+
+	select {
+	case c.Send <- message:
+		return true
+	default:
+		c.stop()
+		return false
+	}
+}
+
+// Close tells every client to disconnect and turns new ones away.
+func (h *Hub) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.closed = true
+	for c := range h.clients {
+		c.stop()
+	}
+}
+
+// Wait blocks until every client has left, or ctx is done.
+func (h *Hub) Wait(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		h.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// unwatch removes one presence subscription; h.mu must be held.
+func (h *Hub) unwatch(c *Client, userId int64) {
+	delete(c.watching, userId)
+	delete(h.watchers[userId], c)
+	if len(h.watchers[userId]) == 0 {
+		delete(h.watchers, userId)
+	}
+}
+
+// announce tells the watchers of a user about a presence change; h.mu must be held.
+func (h *Hub) announce(userId int64, online bool) {
+	if len(h.watchers[userId]) == 0 {
+		return
+	}
+
+	message, _ := json.Marshal(Presence{Type: "presence", UserId: userId, Online: online})
+	for c := range h.watchers[userId] {
+		h.Deliver(c, message)
+	}
+}
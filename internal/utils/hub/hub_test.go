@@ -0,0 +1,112 @@
+package hub
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func receivePresence(t *testing.T, c *Client) Presence {
+	t.Helper()
+
+	select {
+	case message := <-c.Send:
+		var presence Presence
+		if err := json.Unmarshal(message, &presence); err != nil {
+			t.Fatalf("Expected a presence message, got '%s'", message)
+		}
+		return presence
+	default:
+		t.Fatalf("Expected a presence message, got none")
+	}
+	return Presence{}
+}
+
+func TestPresence(t *testing.T) {
+	h := New()
+	watcher, _ := h.Join(1)
+
+	if online := h.Watch(watcher, []int64{2}); online[2] {
+		t.Errorf("Expected user 2 to be offline")
+	}
+
+	first, _ := h.Join(2)
+	if presence := receivePresence(t, watcher); presence.UserId != 2 || !presence.Online {
+		t.Errorf("Expected user 2 to come online, got '%v'", presence)
+	}
+
+	// a second connection changes nothing
+	second, _ := h.Join(2)
+	h.Leave(first)
+	if len(watcher.Send) != 0 || !h.Online(2) {
+		t.Errorf("Expected user 2 to stay online while connected")
+	}
+
+	h.Leave(second)
+	if presence := receivePresence(t, watcher); presence.UserId != 2 || presence.Online {
+		t.Errorf("Expected user 2 to go offline, got '%v'", presence)
+	}
+
+	h.Unwatch(watcher, []int64{2})
+	third, _ := h.Join(2)
+	if len(watcher.Send) != 0 {
+		t.Errorf("Expected no updates after unwatching")
+	}
+
+	h.Leave(third)
+	h.Leave(watcher)
+	if h.Online(1) || h.Online(2) {
+		t.Errorf("Expected everyone to be offline")
+	}
+}
+
+func TestDeliverDropsSlowClients(t *testing.T) {
+	h := New()
+	c, _ := h.Join(1)
+
+	for i := 0; i < SendBuffer; i++ {
+		if !h.Deliver(c, []byte("message")) {
+			t.Fatalf("Expected message %d to be queued", i)
+		}
+	}
+
+	if h.Deliver(c, []byte("one too many")) {
+		t.Errorf("Expected a full queue to refuse the message")
+	}
+
+	select {
+	case <-c.Done():
+	default:
+		t.Errorf("Expected the slow client to be told to disconnect")
+	}
+
+	h.Leave(c)
+}
+
+func TestCloseAndWait(t *testing.T) {
+	h := New()
+	c, _ := h.Join(1)
+
+	h.Close()
+	if _, err := h.Join(2); err != ErrClosed {
+		t.Errorf("Expected '%v', got '%v'", ErrClosed, err)
+	}
+
+	select {
+	case <-c.Done():
+	default:
+		t.Fatalf("Expected the client to be told to disconnect")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := h.Wait(ctx); err == nil {
+		t.Errorf("Expected Wait to time out while the client is connected")
+	}
+
+	h.Leave(c)
+	if err := h.Wait(context.Background()); err != nil {
+		t.Errorf("Expected Wait to return once the client left, got '%v'", err)
+	}
+}
@@ -0,0 +1,114 @@
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+
+	"golang.org/x/image/draw"
+	"golang.org/x/image/webp"
+)
+
+// MaxPixels bounds the decoded size of an image, so a small file cannot
+// expand into an enormous bitmap.
+const MaxPixels = 40_000_000
+
+var (
+	ErrUnsupportedFormat = errors.New("imaging: unsupported image format")
+	ErrTooLarge          = errors.New("imaging: image dimensions are too large")
+)
+
+// the image types that can be uploaded, by their sniffed content type
+var decoders = map[string]func([]byte) (image.Image, error){
+	"image/jpeg": func(data []byte) (image.Image, error) { return jpeg.Decode(bytes.NewReader(data)) },
+	"image/png":  func(data []byte) (image.Image, error) { return png.Decode(bytes.NewReader(data)) },
+	"image/gif":  func(data []byte) (image.Image, error) { return gif.Decode(bytes.NewReader(data)) },
+	"image/webp": func(data []byte) (image.Image, error) { return webp.Decode(bytes.NewReader(data)) },
+}
+
+var configDecoders = map[string]func([]byte) (image.Config, error){
+	"image/jpeg": func(data []byte) (image.Config, error) { return jpeg.DecodeConfig(bytes.NewReader(data)) },
+	"image/png":  func(data []byte) (image.Config, error) { return png.DecodeConfig(bytes.NewReader(data)) },
+	"image/gif":  func(data []byte) (image.Config, error) { return gif.DecodeConfig(bytes.NewReader(data)) },
+	"image/webp": func(data []byte) (image.Config, error) { return webp.DecodeConfig(bytes.NewReader(data)) },
+}
+
+// Info describes an image.
+type Info struct {
+	ContentType string
+	Width       int
+	Height      int
+}
+
+// Probe identifies an image from its content rather than any name or header
+// supplied with it, and reads its dimensions without decoding it.
+func Probe(data []byte) (*Info, error) {
+	contentType := http.DetectContentType(data)
+
+	decodeConfig, ok := configDecoders[contentType]
+	if !ok {
+		return nil, ErrUnsupportedFormat
+	}
+
+	config, err := decodeConfig(data)
+	if err != nil {
+		return nil, ErrUnsupportedFormat
+	}
+
+	if config.Width <= 0 || config.Height <= 0 || config.Width*config.Height > MaxPixels {
+		return nil, ErrTooLarge
+	}
+
+	return &Info{ContentType: contentType, Width: config.Width, Height: config.Height}, nil
+}
+
+// Thumbnail scales an image down to fit within size x size pixels, keeping
+// its aspect ratio; smaller images keep their size. The result is a PNG when
+// the source may be transparent and a JPEG otherwise. Re-encoding also drops
+// any metadata, such as location, embedded in the original.
+func Thumbnail(data []byte, size int) ([]byte, *Info, error) {
+	info, err := Probe(data)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	src, err := decoders[info.ContentType](data)
+	if err != nil {
+		return nil, nil, ErrUnsupportedFormat
+	}
+
+	width, height := fit(info.Width, info.Height, size)
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, src.Bounds(), draw.Src, nil)
+
+	var buf bytes.Buffer
+	out := &Info{Width: width, Height: height}
+	if info.ContentType == "image/png" || info.ContentType == "image/gif" || !dst.Opaque() {
+		out.ContentType = "image/png"
+		err = png.Encode(&buf, dst)
+	} else {
+		out.ContentType = "image/jpeg"
+		err = jpeg.Encode(&buf, dst, &jpeg.Options{Quality: 85})
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return buf.Bytes(), out, nil
+}
+
+// fit returns the dimensions of a width x height image scaled down to fit within size x size.
+func fit(width, height, size int) (int, int) {
+	if width <= size && height <= size {
+		return width, height
+	}
+
+	if width >= height {
+		return size, max(1, height*size/width)
+	}
+	return max(1, width*size/height), size
+}
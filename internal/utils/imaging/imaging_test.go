@@ -0,0 +1,94 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+func encodePNG(t *testing.T, width, height int, c color.Color) []byte {
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.Set(x, y, c)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func encodeJPEG(t *testing.T, width, height int) []byte {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, nil); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestProbe(t *testing.T) {
+	info, err := Probe(encodeJPEG(t, 40, 30))
+	if err != nil {
+		t.Fatalf("Expected JPEG to be accepted, got '%v'", err)
+	}
+	if info.ContentType != "image/jpeg" || info.Width != 40 || info.Height != 30 {
+		t.Errorf("Unexpected info %+v", info)
+	}
+
+	for name, data := range map[string][]byte{
+		"text":      []byte("definitely not an image"),
+		"html":      []byte("<html><body>hi</body></html>"),
+		"truncated": encodeJPEG(t, 40, 30)[:20],
+	} {
+		if _, err := Probe(data); !errors.Is(err, ErrUnsupportedFormat) {
+			t.Errorf("Expected %s to be rejected with '%v', got '%v'", name, ErrUnsupportedFormat, err)
+		}
+	}
+}
+
+func TestProbeRejectsHugeDimensions(t *testing.T) {
+	// a valid PNG header claiming 100000 x 100000 pixels
+	data := encodePNG(t, 1, 1, color.White)
+	copy(data[16:24], []byte{0, 1, 0x86, 0xa0, 0, 1, 0x86, 0xa0})
+	binary.BigEndian.PutUint32(data[29:33], crc32.ChecksumIEEE(data[12:29]))
+
+	if _, err := Probe(data); !errors.Is(err, ErrTooLarge) {
+		t.Errorf("Expected '%v', got '%v'", ErrTooLarge, err)
+	}
+}
+
+func TestThumbnail(t *testing.T) {
+	data, info, err := Thumbnail(encodeJPEG(t, 400, 200), 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.ContentType != "image/jpeg" || info.Width != 100 || info.Height != 50 {
+		t.Errorf("Unexpected thumbnail %+v", info)
+	}
+
+	decoded, err := jpeg.Decode(bytes.NewReader(data))
+	if err != nil || decoded.Bounds().Dx() != 100 || decoded.Bounds().Dy() != 50 {
+		t.Errorf("Expected a 100x50 JPEG, got %v (%v)", decoded.Bounds(), err)
+	}
+}
+
+func TestThumbnailKeepsTransparencyAndSmallSizes(t *testing.T) {
+	_, info, err := Thumbnail(encodePNG(t, 20, 60, color.NRGBA{255, 0, 0, 128}), 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.ContentType != "image/png" || info.Width != 20 || info.Height != 60 {
+		t.Errorf("Unexpected thumbnail %+v", info)
+	}
+}
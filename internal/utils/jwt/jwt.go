@@ -7,20 +7,44 @@ import (
 	"github.com/golang-jwt/jwt/v5"
 )
 
+// TokenTTL is how long an issued token stays valid.
+const TokenTTL = 15 * time.Minute
+
+// ImpersonationTTL is how long an impersonation token stays valid.
+const ImpersonationTTL = 10 * time.Minute
+
 // generate token
-func GenerateToken(id int64) (string, error) {
-	exp := time.Now().Add(15 * time.Minute).Unix()
-	secret := []byte(config.Env.JWTSecret)
+func GenerateToken(id int64, sessionId int64) (string, error) {
+	// token claims
+	claims := jwt.MapClaims{
+		"id":  id,
+		"sid": sessionId,
+		"exp": time.Now().Add(TokenTTL).Unix(),
+	}
 
+	return sign(claims)
+}
+
+// generate a token for an admin acting as another user; the real actor is
+// carried in the "act" claim as described in RFC 8693
+func GenerateImpersonationToken(id int64, sessionId int64, actorId int64) (string, error) {
 	// token claims
 	claims := jwt.MapClaims{
 		"id":  id,
-		"exp": exp,
+		"sid": sessionId,
+		"act": map[string]interface{}{"sub": actorId},
+		"exp": time.Now().Add(ImpersonationTTL).Unix(),
 	}
 
+	return sign(claims)
+}
+
+// sign the token with secret key
+func sign(claims jwt.MapClaims) (string, error) {
+	secret := []byte(config.Env.JWTSecret)
+
 	// create the token
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 
-	// sign the toke with secret key
 	return token.SignedString(secret)
 }
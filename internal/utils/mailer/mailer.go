@@ -0,0 +1,63 @@
+package mailer
+
+import (
+	"fmt"
+	"log"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+
+	"github.com/achintha-dilshan/go-rest-api/config"
+)
+
+// Mailer sends plain text emails.
+type Mailer interface {
+	Send(to, subject, body string) error
+}
+
+type smtpMailer struct {
+	addr string
+	auth smtp.Auth
+	from string
+}
+
+type logMailer struct{}
+
+// New returns an SMTP mailer, or a mailer that only logs messages when SMTP is not configured,
+// which configuration validation only allows outside production.
+func New() Mailer {
+	if config.Env.SMTPHost == "" {
+		return &logMailer{}
+	}
+
+	var auth smtp.Auth
+	if config.Env.SMTPUser != "" {
+		auth = smtp.PlainAuth("", config.Env.SMTPUser, config.Env.SMTPPassword, config.Env.SMTPHost)
+	}
+
+	return &smtpMailer{
+		addr: net.JoinHostPort(config.Env.SMTPHost, strconv.Itoa(config.Env.SMTPPort)),
+		auth: auth,
+		from: config.Env.MailFrom,
+	}
+}
+
+// Send delivers the message through the SMTP server.
+func (m *smtpMailer) Send(to, subject, body string) error {
+	var msg strings.Builder
+	fmt.Fprintf(&msg, "From: %s\r\n", m.from)
+	fmt.Fprintf(&msg, "To: %s\r\n", to)
+	fmt.Fprintf(&msg, "Subject: %s\r\n", subject)
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/plain; charset=UTF-8\r\n\r\n")
+	msg.WriteString(body)
+
+	return smtp.SendMail(m.addr, m.auth, m.from, []string{to}, []byte(msg.String()))
+}
+
+// Send writes the message to the log, which is handy in development.
+func (m *logMailer) Send(to, subject, body string) error {
+	log.Printf("Mail to %s: %s\n%s", to, subject, body)
+	return nil
+}
@@ -0,0 +1,145 @@
+package markup
+
+import (
+	"bytes"
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/text"
+)
+
+// WordsPerMinute is the reading speed used for reading times.
+const WordsPerMinute = 200
+
+// markdown renders GitHub flavoured Markdown. It is left in goldmark's safe
+// mode: raw HTML is dropped and links with dangerous schemes such as
+// javascript: lose their destination.
+var markdown = goldmark.New(goldmark.WithExtensions(extension.GFM))
+
+var paragraphBreak = regexp.MustCompile(`\n\s*\n`)
+
+// a mention links to the profile of the user, as in [@Jane](/users/42)
+var mention = regexp.MustCompile(`\[@[^\]\n]+\]\(/users/(\d+)\)`)
+
+// Render converts a body to HTML that is safe to embed in a page. Markdown
+// bodies are rendered; plain bodies are escaped, with blank lines separating
+// paragraphs.
+func Render(body string, isMarkdown bool) (string, error) {
+	body = strings.ReplaceAll(body, "\r\n", "\n")
+
+	if isMarkdown {
+		var buf bytes.Buffer
+		if err := markdown.Convert([]byte(body), &buf); err != nil {
+			return "", err
+		}
+		return buf.String(), nil
+	}
+
+	var b strings.Builder
+	for _, paragraph := range paragraphBreak.Split(strings.TrimSpace(body), -1) {
+		if paragraph == "" {
+			continue
+		}
+		lines := strings.Split(strings.TrimSpace(paragraph), "\n")
+		for i, line := range lines {
+			lines[i] = html.EscapeString(line)
+		}
+		b.WriteString("<p>" + strings.Join(lines, "<br>\n") + "</p>\n")
+	}
+	return b.String(), nil
+}
+
+// PlainText returns the readable text of a body with markup removed and
+// whitespace collapsed.
+func PlainText(body string, isMarkdown bool) string {
+	if !isMarkdown {
+		return strings.Join(strings.Fields(body), " ")
+	}
+
+	source := []byte(body)
+	doc := markdown.Parser().Parse(text.NewReader(source))
+
+	var b strings.Builder
+	ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			if n.Type() == ast.TypeBlock {
+				b.WriteByte(' ')
+			}
+			return ast.WalkContinue, nil
+		}
+
+		switch n := n.(type) {
+		case *ast.Text:
+			b.Write(n.Segment.Value(source))
+			if n.SoftLineBreak() || n.HardLineBreak() {
+				b.WriteByte(' ')
+			}
+		case *ast.String:
+			b.Write(n.Value)
+		case *ast.AutoLink:
+			b.Write(n.Label(source))
+			return ast.WalkSkipChildren, nil
+		case *ast.FencedCodeBlock, *ast.CodeBlock:
+			lines := n.Lines()
+			for i := 0; i < lines.Len(); i++ {
+				segment := lines.At(i)
+				b.Write(segment.Value(source))
+			}
+		case *ast.RawHTML, *ast.HTMLBlock:
+			return ast.WalkSkipChildren, nil
+		}
+		return ast.WalkContinue, nil
+	})
+
+	return strings.Join(strings.Fields(b.String()), " ")
+}
+
+// Excerpt shortens text to at most n characters, cutting at a word boundary
+// where possible and marking the cut with an ellipsis.
+func Excerpt(text string, n int) string {
+	if utf8.RuneCountInString(text) <= n {
+		return text
+	}
+
+	runes := []rune(text)
+	cut := string(runes[:n])
+	if i := strings.LastIndexByte(cut, ' '); i > len(cut)/2 {
+		cut = cut[:i]
+	}
+	return strings.TrimRight(cut, " .,;:!?-") + "…"
+}
+
+// ReadingTime estimates how many minutes text takes to read, rounding up.
+func ReadingTime(text string) int {
+	words := len(strings.Fields(text))
+	return max(1, (words+WordsPerMinute-1)/WordsPerMinute)
+}
+
+// Mentions returns the IDs of the users mentioned in a body, in order of
+// first mention, keeping at most n of them.
+func Mentions(body string, n int) []int64 {
+	var ids []int64
+	seen := make(map[int64]bool)
+
+	for _, match := range mention.FindAllStringSubmatch(body, -1) {
+		if len(ids) == n {
+			break
+		}
+
+		id, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil || id < 1 || seen[id] {
+			continue
+		}
+
+		seen[id] = true
+		ids = append(ids, id)
+	}
+
+	return ids
+}
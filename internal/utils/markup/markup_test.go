@@ -0,0 +1,95 @@
+package markup
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRenderMarkdown(t *testing.T) {
+	got, err := Render("# Title\n\nSome *emphasis* and `code`.", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []string{"<h1>Title</h1>", "<em>emphasis</em>", "<code>code</code>"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Expected '%s' in '%s'", want, got)
+		}
+	}
+}
+
+func TestRenderMarkdownIsSanitized(t *testing.T) {
+	body := "<script>alert(1)</script>\n\nHello <img src=x onerror=alert(1)>\n\n" +
+		"[click](javascript:alert(1)) [data](data:text/html;base64,PHNjcmlwdD4=)"
+
+	got, err := Render(body, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, banned := range []string{"<script", "<img", "onerror", "javascript:", "data:text/html"} {
+		if strings.Contains(got, banned) {
+			t.Errorf("Expected '%s' to be removed from '%s'", banned, got)
+		}
+	}
+}
+
+func TestRenderPlain(t *testing.T) {
+	got, err := Render("first <b>line</b>\r\nsecond line\n\n\nnext paragraph", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := "<p>first &lt;b&gt;line&lt;/b&gt;<br>\nsecond line</p>\n<p>next paragraph</p>\n"
+	if got != want {
+		t.Errorf("Expected '%s', got '%s'", want, got)
+	}
+}
+
+func TestPlainText(t *testing.T) {
+	body := "# Title\n\nSome **bold** text with a [link](https://example.com).\n\n<div>raw</div>\n\n```\ncode here\n```"
+
+	want := "Title Some bold text with a link. code here"
+	if got := PlainText(body, true); got != want {
+		t.Errorf("Expected '%s', got '%s'", want, got)
+	}
+
+	if got := PlainText("  spaced\n\n out  ", false); got != "spaced out" {
+		t.Errorf("Expected 'spaced out', got '%s'", got)
+	}
+}
+
+func TestExcerpt(t *testing.T) {
+	if got := Excerpt("short text", 20); got != "short text" {
+		t.Errorf("Expected short text to be unchanged, got '%s'", got)
+	}
+
+	if got := Excerpt("The quick brown fox jumps over the lazy dog.", 20); got != "The quick brown fox…" {
+		t.Errorf("Expected a cut at a word boundary, got '%s'", got)
+	}
+
+	if got := Excerpt("ééééééééééé", 5); got != "ééééé…" {
+		t.Errorf("Expected a cut between characters, got '%s'", got)
+	}
+}
+
+func TestReadingTime(t *testing.T) {
+	for words, minutes := range map[int]int{0: 1, 1: 1, 200: 1, 201: 2, 1000: 5} {
+		if got := ReadingTime(strings.Repeat("word ", words)); got != minutes {
+			t.Errorf("Expected %d minutes for %d words, got %d", minutes, words, got)
+		}
+	}
+}
+
+func TestMentions(t *testing.T) {
+	body := "Thanks [@Jane](/users/42) and [@Bob](/users/7), again [@Jane](/users/42). Not [Jane](/users/3), [@x](/users/0) or @5."
+
+	got := Mentions(body, 10)
+	if len(got) != 2 || got[0] != 42 || got[1] != 7 {
+		t.Errorf("Expected [42 7], got %v", got)
+	}
+
+	if got := Mentions(body, 1); len(got) != 1 || got[0] != 42 {
+		t.Errorf("Expected [42], got %v", got)
+	}
+}
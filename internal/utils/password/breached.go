@@ -0,0 +1,57 @@
+package password
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// BreachedList reports whether a password is known to have been leaked.
+type BreachedList interface {
+	IsBreached(password string) (bool, error)
+}
+
+// rangeDirectory looks passwords up in a local copy of the Have I Been Pwned
+// range files. The SHA-1 hash of the password is split into a five character
+// prefix naming the file (e.g. "5BAA6.txt") and a suffix searched for inside
+// it, so only hashes sharing the prefix are ever read.
+type rangeDirectory struct {
+	dir string
+}
+
+// NewRangeDirectory creates a breached password list backed by range files in dir.
+func NewRangeDirectory(dir string) BreachedList {
+	return &rangeDirectory{dir: dir}
+}
+
+// IsBreached checks the range file for the password's hash prefix.
+func (d *rangeDirectory) IsBreached(password string) (bool, error) {
+	sum := sha1.Sum([]byte(password))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+	prefix, suffix := hash[:5], hash[5:]
+
+	f, err := os.Open(filepath.Join(d.dir, prefix+".txt"))
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+
+	// each line is "SUFFIX:COUNT"
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), ":")
+		if strings.EqualFold(strings.TrimSpace(line), suffix) {
+			return true, nil
+		}
+	}
+
+	return false, scanner.Err()
+}
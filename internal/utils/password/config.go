@@ -0,0 +1,37 @@
+package password
+
+import "github.com/achintha-dilshan/go-rest-api/config"
+
+// PolicyFromConfig returns the configured password policy.
+func PolicyFromConfig() *Policy {
+	options := PolicyOptions{
+		MinLength:     config.Env.PasswordMinLength,
+		MaxLength:     config.Env.PasswordMaxLength,
+		RequireUpper:  config.Env.PasswordRequireUpper,
+		RequireLower:  config.Env.PasswordRequireLower,
+		RequireDigit:  config.Env.PasswordRequireDigit,
+		RequireSymbol: config.Env.PasswordRequireSymbol,
+	}
+
+	if config.Env.PasswordBreachedDir != "" {
+		options.Breached = NewRangeDirectory(config.Env.PasswordBreachedDir)
+	}
+
+	return NewPolicy(options)
+}
+
+// HasherFromConfig returns the configured password hasher. Hashes made with
+// the other algorithm keep verifying and are upgraded on login.
+func HasherFromConfig() Hasher {
+	bcrypt := NewBcrypt(config.Env.BcryptCost)
+	argon2id := NewArgon2id(Argon2idParams{
+		Memory:      uint32(config.Env.Argon2Memory),
+		Iterations:  uint32(config.Env.Argon2Iterations),
+		Parallelism: uint8(config.Env.Argon2Parallelism),
+	})
+
+	if config.Env.PasswordHashAlgorithm == "bcrypt" {
+		return NewUpgradingHasher(bcrypt, argon2id)
+	}
+	return NewUpgradingHasher(argon2id, bcrypt)
+}
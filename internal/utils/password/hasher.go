@@ -0,0 +1,190 @@
+package password
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// ErrUnknownFormat is returned when a stored hash was not produced by the hasher.
+var ErrUnknownFormat = errors.New("password: unknown hash format")
+
+// Hasher hashes passwords and verifies them against stored hashes.
+type Hasher interface {
+	// Hash returns the encoded hash of a password.
+	Hash(password string) (string, error)
+	// Verify reports whether the password matches the encoded hash.
+	Verify(password, encoded string) (bool, error)
+	// NeedsRehash reports whether the encoded hash should be replaced because
+	// it uses another algorithm or outdated parameters.
+	NeedsRehash(encoded string) bool
+}
+
+type bcryptHasher struct {
+	cost int
+}
+
+// NewBcrypt creates a hasher producing bcrypt hashes ("$2a$<cost>$...").
+func NewBcrypt(cost int) Hasher {
+	return &bcryptHasher{cost: cost}
+}
+
+func (h *bcryptHasher) Hash(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), h.cost)
+	return string(hash), err
+}
+
+func (h *bcryptHasher) Verify(password, encoded string) (bool, error) {
+	if !isBcrypt(encoded) {
+		return false, ErrUnknownFormat
+	}
+
+	err := bcrypt.CompareHashAndPassword([]byte(encoded), []byte(password))
+	if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (h *bcryptHasher) NeedsRehash(encoded string) bool {
+	cost, err := bcrypt.Cost([]byte(encoded))
+	return err != nil || cost != h.cost
+}
+
+func isBcrypt(encoded string) bool {
+	return strings.HasPrefix(encoded, "$2a$") || strings.HasPrefix(encoded, "$2b$") || strings.HasPrefix(encoded, "$2y$")
+}
+
+// Argon2idParams are the Argon2id cost parameters.
+type Argon2idParams struct {
+	// Memory in KiB
+	Memory      uint32
+	Iterations  uint32
+	Parallelism uint8
+	SaltLength  uint32
+	KeyLength   uint32
+}
+
+type argon2idHasher struct {
+	params Argon2idParams
+}
+
+// NewArgon2id creates a hasher producing PHC formatted Argon2id hashes
+// ("$argon2id$v=19$m=65536,t=3,p=2$<salt>$<hash>").
+func NewArgon2id(params Argon2idParams) Hasher {
+	if params.SaltLength == 0 {
+		params.SaltLength = 16
+	}
+	if params.KeyLength == 0 {
+		params.KeyLength = 32
+	}
+	return &argon2idHasher{params: params}
+}
+
+func (h *argon2idHasher) Hash(password string) (string, error) {
+	salt := make([]byte, h.params.SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+
+	key := argon2.IDKey([]byte(password), salt, h.params.Iterations, h.params.Memory, h.params.Parallelism, h.params.KeyLength)
+
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version, h.params.Memory, h.params.Iterations, h.params.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+func (h *argon2idHasher) Verify(password, encoded string) (bool, error) {
+	params, salt, key, err := decodeArgon2id(encoded)
+	if err != nil {
+		return false, err
+	}
+
+	candidate := argon2.IDKey([]byte(password), salt, params.Iterations, params.Memory, params.Parallelism, params.KeyLength)
+	return subtle.ConstantTimeCompare(key, candidate) == 1, nil
+}
+
+func (h *argon2idHasher) NeedsRehash(encoded string) bool {
+	params, salt, _, err := decodeArgon2id(encoded)
+	if err != nil {
+		return true
+	}
+
+	return params.Memory != h.params.Memory ||
+		params.Iterations != h.params.Iterations ||
+		params.Parallelism != h.params.Parallelism ||
+		params.KeyLength != h.params.KeyLength ||
+		uint32(len(salt)) != h.params.SaltLength
+}
+
+// decodeArgon2id parses a PHC formatted Argon2id hash.
+func decodeArgon2id(encoded string) (Argon2idParams, []byte, []byte, error) {
+	var params Argon2idParams
+
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 6 || parts[0] != "" || parts[1] != "argon2id" {
+		return params, nil, nil, ErrUnknownFormat
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return params, nil, nil, errors.New("password: unsupported argon2 version")
+	}
+
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.Memory, &params.Iterations, &params.Parallelism); err != nil {
+		return params, nil, nil, errors.New("password: invalid argon2 parameters")
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return params, nil, nil, errors.New("password: invalid argon2 salt")
+	}
+
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(key) == 0 {
+		return params, nil, nil, errors.New("password: invalid argon2 hash")
+	}
+
+	params.SaltLength = uint32(len(salt))
+	params.KeyLength = uint32(len(key))
+
+	return params, salt, key, nil
+}
+
+type upgradingHasher struct {
+	preferred Hasher
+	legacy    []Hasher
+}
+
+// NewUpgradingHasher creates a hasher that hashes with the preferred hasher
+// and still verifies hashes made by the legacy ones. Any hash the preferred
+// hasher did not make with its current parameters needs a rehash.
+func NewUpgradingHasher(preferred Hasher, legacy ...Hasher) Hasher {
+	return &upgradingHasher{preferred: preferred, legacy: legacy}
+}
+
+func (h *upgradingHasher) Hash(password string) (string, error) {
+	return h.preferred.Hash(password)
+}
+
+func (h *upgradingHasher) Verify(password, encoded string) (bool, error) {
+	for _, hasher := range append([]Hasher{h.preferred}, h.legacy...) {
+		ok, err := hasher.Verify(password, encoded)
+		if errors.Is(err, ErrUnknownFormat) {
+			continue
+		}
+		return ok, err
+	}
+	return false, ErrUnknownFormat
+}
+
+func (h *upgradingHasher) NeedsRehash(encoded string) bool {
+	return h.preferred.NeedsRehash(encoded)
+}
@@ -0,0 +1,87 @@
+package password
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// cheap parameters keep the tests fast
+var testArgon2idParams = Argon2idParams{Memory: 1024, Iterations: 1, Parallelism: 1}
+
+func TestArgon2idHashAndVerify(t *testing.T) {
+	h := NewArgon2id(testArgon2idParams)
+
+	hash, err := h.Hash("s3cret-pass")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.HasPrefix(hash, "$argon2id$v=19$m=1024,t=1,p=1$") {
+		t.Errorf("Expected PHC formatted hash, got '%s'", hash)
+	}
+
+	if ok, err := h.Verify("s3cret-pass", hash); !ok || err != nil {
+		t.Errorf("Expected password to verify, got %v (%v)", ok, err)
+	}
+	if ok, err := h.Verify("wrong-pass", hash); ok || err != nil {
+		t.Errorf("Expected wrong password to be rejected, got %v (%v)", ok, err)
+	}
+	if h.NeedsRehash(hash) {
+		t.Errorf("Expected hash with current parameters not to need a rehash")
+	}
+	if !NewArgon2id(Argon2idParams{Memory: 2048, Iterations: 1, Parallelism: 1}).NeedsRehash(hash) {
+		t.Errorf("Expected hash with outdated parameters to need a rehash")
+	}
+}
+
+func TestBcryptHashAndVerify(t *testing.T) {
+	h := NewBcrypt(4)
+
+	hash, err := h.Hash("s3cret-pass")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if ok, err := h.Verify("s3cret-pass", hash); !ok || err != nil {
+		t.Errorf("Expected password to verify, got %v (%v)", ok, err)
+	}
+	if ok, err := h.Verify("wrong-pass", hash); ok || err != nil {
+		t.Errorf("Expected wrong password to be rejected, got %v (%v)", ok, err)
+	}
+	if !NewBcrypt(5).NeedsRehash(hash) {
+		t.Errorf("Expected hash with a different cost to need a rehash")
+	}
+}
+
+func TestVerifyRejectsOtherFormats(t *testing.T) {
+	bcryptHash, _ := NewBcrypt(4).Hash("s3cret-pass")
+
+	if _, err := NewArgon2id(testArgon2idParams).Verify("s3cret-pass", bcryptHash); !errors.Is(err, ErrUnknownFormat) {
+		t.Errorf("Expected '%v', got '%v'", ErrUnknownFormat, err)
+	}
+	if _, err := NewBcrypt(4).Verify("s3cret-pass", "$argon2id$v=19$m=1,t=1,p=1$c2FsdA$aGFzaA"); !errors.Is(err, ErrUnknownFormat) {
+		t.Errorf("Expected '%v', got '%v'", ErrUnknownFormat, err)
+	}
+}
+
+func TestUpgradingHasherMigratesBcrypt(t *testing.T) {
+	legacyHash, _ := NewBcrypt(4).Hash("s3cret-pass")
+	h := NewUpgradingHasher(NewArgon2id(testArgon2idParams), NewBcrypt(4))
+
+	if ok, err := h.Verify("s3cret-pass", legacyHash); !ok || err != nil {
+		t.Errorf("Expected legacy hash to verify, got %v (%v)", ok, err)
+	}
+	if !h.NeedsRehash(legacyHash) {
+		t.Errorf("Expected legacy hash to need a rehash")
+	}
+
+	hash, _ := h.Hash("s3cret-pass")
+	if !strings.HasPrefix(hash, "$argon2id$") || h.NeedsRehash(hash) {
+		t.Errorf("Expected new hashes to use the preferred hasher, got '%s'", hash)
+	}
+
+	if _, err := h.Verify("s3cret-pass", "plain-text"); !errors.Is(err, ErrUnknownFormat) {
+		t.Errorf("Expected '%v', got '%v'", ErrUnknownFormat, err)
+	}
+}
@@ -0,0 +1,107 @@
+package password
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// PolicyOptions configures the rules a new password has to satisfy.
+type PolicyOptions struct {
+	MinLength     int
+	MaxLength     int
+	RequireUpper  bool
+	RequireLower  bool
+	RequireDigit  bool
+	RequireSymbol bool
+	// Breached is consulted last; nil disables the check.
+	Breached BreachedList
+}
+
+// Policy validates new passwords.
+type Policy struct {
+	options PolicyOptions
+}
+
+// NewPolicy creates a password policy.
+func NewPolicy(options PolicyOptions) *Policy {
+	return &Policy{options: options}
+}
+
+// Validate checks a new password for the user with the given name and email.
+// It returns a message describing the first rule that failed, or an empty
+// string if the password is acceptable. The error is only set when the
+// breached password list could not be read.
+func (p *Policy) Validate(password, name, email string) (string, error) {
+	length := utf8.RuneCountInString(password)
+	if length < p.options.MinLength {
+		return fmt.Sprintf("Password must be at least %d characters long.", p.options.MinLength), nil
+	}
+	if p.options.MaxLength > 0 && length > p.options.MaxLength {
+		return fmt.Sprintf("Password must be at most %d characters long.", p.options.MaxLength), nil
+	}
+
+	var hasUpper, hasLower, hasDigit, hasSymbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			hasUpper = true
+		case unicode.IsLower(r):
+			hasLower = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r) || unicode.IsSpace(r):
+			hasSymbol = true
+		}
+	}
+
+	switch {
+	case p.options.RequireUpper && !hasUpper:
+		return "Password must contain an uppercase letter.", nil
+	case p.options.RequireLower && !hasLower:
+		return "Password must contain a lowercase letter.", nil
+	case p.options.RequireDigit && !hasDigit:
+		return "Password must contain a digit.", nil
+	case p.options.RequireSymbol && !hasSymbol:
+		return "Password must contain a symbol.", nil
+	}
+
+	if containsPersonalInfo(password, name, email) {
+		return "Password must not contain your name or email.", nil
+	}
+
+	if p.options.Breached != nil {
+		breached, err := p.options.Breached.IsBreached(password)
+		if err != nil {
+			return "", err
+		}
+		if breached {
+			return "Password has appeared in a data breach. Choose a different one.", nil
+		}
+	}
+
+	return "", nil
+}
+
+// containsPersonalInfo reports whether the password contains the user's email,
+// its local part, their full name or any part of the name. Parts shorter than
+// three characters are ignored since they match too many passwords.
+func containsPersonalInfo(password, name, email string) bool {
+	password = strings.ToLower(password)
+	email = strings.ToLower(strings.TrimSpace(email))
+	name = strings.ToLower(name)
+
+	candidates := []string{email, strings.Join(strings.Fields(name), "")}
+	if local, _, ok := strings.Cut(email, "@"); ok {
+		candidates = append(candidates, local)
+	}
+	candidates = append(candidates, strings.Fields(name)...)
+
+	for _, candidate := range candidates {
+		if utf8.RuneCountInString(candidate) >= 3 && strings.Contains(password, candidate) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,78 @@
+package password
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPolicyLengthRules(t *testing.T) {
+	p := NewPolicy(PolicyOptions{MinLength: 8, MaxLength: 12})
+
+	if msg, _ := p.Validate("short", "", ""); msg != "Password must be at least 8 characters long." {
+		t.Errorf("Expected min length error, got '%s'", msg)
+	}
+	if msg, _ := p.Validate("much-too-long-password", "", ""); msg != "Password must be at most 12 characters long." {
+		t.Errorf("Expected max length error, got '%s'", msg)
+	}
+	if msg, _ := p.Validate("ünïcødé!", "", ""); msg != "" {
+		t.Errorf("Expected length to be counted in characters, got '%s'", msg)
+	}
+}
+
+func TestPolicyCharacterClasses(t *testing.T) {
+	p := NewPolicy(PolicyOptions{MinLength: 1, RequireUpper: true, RequireLower: true, RequireDigit: true, RequireSymbol: true})
+
+	cases := map[string]string{
+		"lower1!":  "Password must contain an uppercase letter.",
+		"UPPER1!":  "Password must contain a lowercase letter.",
+		"Letters!": "Password must contain a digit.",
+		"Letters1": "Password must contain a symbol.",
+		"Lett3rs!": "",
+	}
+
+	for input, expected := range cases {
+		if msg, _ := p.Validate(input, "", ""); msg != expected {
+			t.Errorf("For '%s' expected '%s', got '%s'", input, expected, msg)
+		}
+	}
+}
+
+func TestPolicyRejectsPersonalInfo(t *testing.T) {
+	p := NewPolicy(PolicyOptions{MinLength: 1})
+	expected := "Password must not contain your name or email."
+
+	for _, input := range []string{"john.doe@example.com!", "xxJOHN.DOExx", "my-doe-pass", "JohnDoe2024"} {
+		if msg, _ := p.Validate(input, "John Doe", "john.doe@example.com"); msg != expected {
+			t.Errorf("For '%s' expected '%s', got '%s'", input, expected, msg)
+		}
+	}
+
+	if msg, _ := p.Validate("correct horse", "Al", "al@example.com"); msg != "" {
+		t.Errorf("Expected short name parts to be ignored, got '%s'", msg)
+	}
+}
+
+func TestPolicyRejectsBreachedPasswords(t *testing.T) {
+	dir := t.TempDir()
+
+	// SHA-1("password") = 5BAA61E4C9B93F3F0682250B6CF8331B7EE68FD8
+	content := "003D68EB55068C33ACE09247EE4C639306B:3\r\n1E4C9B93F3F0682250B6CF8331B7EE68FD8:9545824\r\n"
+	if err := os.WriteFile(filepath.Join(dir, "5BAA6.txt"), []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	p := NewPolicy(PolicyOptions{MinLength: 1, Breached: NewRangeDirectory(dir)})
+
+	msg, err := p.Validate("password", "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if msg != "Password has appeared in a data breach. Choose a different one." {
+		t.Errorf("Expected breached password error, got '%s'", msg)
+	}
+
+	if msg, err := p.Validate("not in the list", "", ""); err != nil || msg != "" {
+		t.Errorf("Expected password without a range file to pass, got '%s' (%v)", msg, err)
+	}
+}
@@ -0,0 +1,67 @@
+package ratelimit
+
+import (
+	"sync"
+	"time"
+)
+
+// Limiter allows a fixed number of events per key within a time window.
+type Limiter struct {
+	limit     int
+	window    time.Duration
+	mu        sync.Mutex
+	windows   map[string]*window
+	lastPrune time.Time
+	now       func() time.Time
+}
+
+type window struct {
+	start time.Time
+	count int
+}
+
+// New creates a limiter allowing limit events per key every period.
+func New(limit int, period time.Duration) *Limiter {
+	return &Limiter{
+		limit:   limit,
+		window:  period,
+		windows: make(map[string]*window),
+		now:     time.Now,
+	}
+}
+
+// Allow records an event for the key and reports whether it is within the limit.
+func (l *Limiter) Allow(key string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.prune(now)
+
+	w, ok := l.windows[key]
+	if !ok || now.Sub(w.start) >= l.window {
+		w = &window{start: now}
+		l.windows[key] = w
+	}
+
+	if w.count >= l.limit {
+		return false
+	}
+
+	w.count++
+	return true
+}
+
+// prune drops expired windows at most once per window so the map stays bounded.
+func (l *Limiter) prune(now time.Time) {
+	if now.Sub(l.lastPrune) < l.window {
+		return
+	}
+
+	for key, w := range l.windows {
+		if now.Sub(w.start) >= l.window {
+			delete(l.windows, key)
+		}
+	}
+	l.lastPrune = now
+}
@@ -0,0 +1,52 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAllowWithinLimit(t *testing.T) {
+	l := New(2, time.Minute)
+
+	if !l.Allow("a") || !l.Allow("a") {
+		t.Errorf("Expected the first two events to be allowed")
+	}
+	if l.Allow("a") {
+		t.Errorf("Expected the third event to be rejected")
+	}
+	if !l.Allow("b") {
+		t.Errorf("Expected a different key to have its own limit")
+	}
+}
+
+func TestAllowResetsAfterWindow(t *testing.T) {
+	now := time.Now()
+	l := New(1, time.Minute)
+	l.now = func() time.Time { return now }
+
+	if !l.Allow("a") {
+		t.Errorf("Expected the first event to be allowed")
+	}
+	if l.Allow("a") {
+		t.Errorf("Expected the second event to be rejected")
+	}
+
+	now = now.Add(time.Minute)
+	if !l.Allow("a") {
+		t.Errorf("Expected the limit to reset after the window")
+	}
+}
+
+func TestPruneRemovesExpiredWindows(t *testing.T) {
+	now := time.Now()
+	l := New(1, time.Minute)
+	l.now = func() time.Time { return now }
+
+	l.Allow("a")
+	now = now.Add(2 * time.Minute)
+	l.Allow("b")
+
+	if _, ok := l.windows["a"]; ok {
+		t.Errorf("Expected expired window to be pruned")
+	}
+}
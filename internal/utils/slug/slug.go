@@ -0,0 +1,76 @@
+package slug
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// MaxLength is the longest slug Make returns, leaving room for a suffix.
+const MaxLength = 100
+
+// Fallback is used when a title has nothing that can be transliterated.
+const Fallback = "post"
+
+// letters that do not decompose into a base letter and accents
+var transliterations = map[rune]string{
+	'ß': "ss", 'æ': "ae", 'œ': "oe", 'ø': "o", 'đ': "d", 'ð': "d", 'ł': "l", 'þ': "th", 'ı': "i", 'ŋ': "ng",
+	'&': " and ",
+
+	// Cyrillic
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "yo", 'ж': "zh", 'з': "z", 'и': "i",
+	'й': "y", 'к': "k", 'л': "l", 'м': "m", 'н': "n", 'о': "o", 'п': "p", 'р': "r", 'с': "s", 'т': "t",
+	'у': "u", 'ф': "f", 'х': "kh", 'ц': "ts", 'ч': "ch", 'ш': "sh", 'щ': "shch", 'ъ': "", 'ы': "y", 'ь': "",
+	'э': "e", 'ю': "yu", 'я': "ya", 'є': "ye", 'і': "i", 'ї': "yi", 'ґ': "g",
+
+	// Greek
+	'α': "a", 'β': "v", 'γ': "g", 'δ': "d", 'ε': "e", 'ζ': "z", 'η': "i", 'θ': "th", 'ι': "i", 'κ': "k",
+	'λ': "l", 'μ': "m", 'ν': "n", 'ξ': "x", 'ο': "o", 'π': "p", 'ρ': "r", 'σ': "s", 'ς': "s", 'τ': "t",
+	'υ': "y", 'φ': "f", 'χ': "ch", 'ψ': "ps", 'ω': "o",
+}
+
+// Make turns a title into a lowercase, hyphen-separated ASCII slug. Accented
+// letters lose their accents and some other scripts are transliterated;
+// anything else is dropped.
+func Make(title string) string {
+	var b strings.Builder
+	hyphen := false
+
+	for _, r := range norm.NFKD.String(strings.ToLower(title)) {
+		if unicode.Is(unicode.Mn, r) {
+			continue
+		}
+
+		replacement, ok := transliterations[r]
+		if !ok {
+			replacement = string(r)
+		}
+
+		for _, c := range replacement {
+			if c < unicode.MaxASCII && (unicode.IsLetter(c) || unicode.IsDigit(c)) {
+				if hyphen && b.Len() > 0 {
+					b.WriteByte('-')
+				}
+				b.WriteRune(c)
+				hyphen = false
+			} else {
+				hyphen = true
+			}
+		}
+	}
+
+	slug := b.String()
+	if len(slug) > MaxLength {
+		slug = slug[:MaxLength]
+		if i := strings.LastIndexByte(slug, '-'); i > MaxLength/2 {
+			slug = slug[:i]
+		}
+		slug = strings.TrimRight(slug, "-")
+	}
+
+	if slug == "" {
+		return Fallback
+	}
+	return slug
+}
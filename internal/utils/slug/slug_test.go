@@ -0,0 +1,34 @@
+package slug
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMake(t *testing.T) {
+	for title, want := range map[string]string{
+		"Hello, World!":               "hello-world",
+		"  Go   1.23 --- released  ":  "go-1-23-released",
+		"Crème brûlée à la française": "creme-brulee-a-la-francaise",
+		"Straße & Ærø":                "strasse-and-aero",
+		"Привет, мир":                 "privet-mir",
+		"Καλημέρα κόσμε":              "kalimera-kosme",
+		"ｆｕｌｌｗｉｄｔｈ":                   "fullwidth",
+		"日本語":                         Fallback,
+		"!!!":                         Fallback,
+		"Łódź is nice":                "lodz-is-nice",
+		"already-a-slug":              "already-a-slug",
+	} {
+		if got := Make(title); got != want {
+			t.Errorf("Make(%q): expected '%s', got '%s'", title, want, got)
+		}
+	}
+}
+
+func TestMakeTruncatesAtWordBoundary(t *testing.T) {
+	got := Make(strings.Repeat("word ", 40))
+
+	if len(got) > MaxLength || strings.HasSuffix(got, "-") || strings.HasSuffix(got, "-wor") {
+		t.Errorf("Expected a slug cut between words within %d characters, got '%s'", MaxLength, got)
+	}
+}
@@ -0,0 +1,25 @@
+package storage
+
+import "github.com/achintha-dilshan/go-rest-api/config"
+
+// FromConfig returns the configured storage: an S3 bucket, or a local
+// directory whose files the API serves under /media/files.
+func FromConfig() Storage {
+	if config.Env.StorageDriver == "s3" {
+		return NewS3(S3Config{
+			Endpoint:  config.Env.S3Endpoint,
+			Region:    config.Env.S3Region,
+			Bucket:    config.Env.S3Bucket,
+			AccessKey: config.Env.S3AccessKey,
+			SecretKey: config.Env.S3SecretKey,
+			PathStyle: config.Env.S3PathStyle,
+			PublicURL: config.Env.StoragePublicURL,
+		})
+	}
+
+	publicURL := config.Env.StoragePublicURL
+	if publicURL == "" {
+		publicURL = config.Env.AppURL + "/media/files"
+	}
+	return NewLocal(config.Env.StorageLocalDir, publicURL)
+}
@@ -0,0 +1,77 @@
+package storage
+
+import (
+	"context"
+	"strings"
+	"sync"
+)
+
+// Memory keeps files in memory. It is meant for tests.
+type Memory struct {
+	mu           sync.RWMutex
+	objects      map[string][]byte
+	contentTypes map[string]string
+	baseURL      string
+}
+
+// NewMemory returns an empty in-memory Storage whose URLs start with baseURL.
+func NewMemory(baseURL string) *Memory {
+	return &Memory{
+		objects:      map[string][]byte{},
+		contentTypes: map[string]string{},
+		baseURL:      strings.TrimSuffix(baseURL, "/"),
+	}
+}
+
+func (m *Memory) Put(ctx context.Context, key string, data []byte, contentType string) error {
+	if !validKey(key) {
+		return ErrInvalidKey
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.objects[key] = append([]byte(nil), data...)
+	m.contentTypes[key] = contentType
+	return nil
+}
+
+func (m *Memory) Get(ctx context.Context, key string) ([]byte, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	data, ok := m.objects[key]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return append([]byte(nil), data...), nil
+}
+
+func (m *Memory) Delete(ctx context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.objects, key)
+	delete(m.contentTypes, key)
+	return nil
+}
+
+func (m *Memory) URL(key string) string {
+	return m.baseURL + "/" + key
+}
+
+// ContentType returns the content type an object was stored with.
+func (m *Memory) ContentType(key string) string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.contentTypes[key]
+}
+
+// Len returns the number of stored objects.
+func (m *Memory) Len() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return len(m.objects)
+}
@@ -0,0 +1,38 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"strings"
+)
+
+// ErrNotFound is returned when no object is stored under a key.
+var ErrNotFound = errors.New("storage: object not found")
+
+// ErrInvalidKey is returned for keys that are empty or could escape the store.
+var ErrInvalidKey = errors.New("storage: invalid key")
+
+// Storage stores uploaded files under slash-separated keys.
+type Storage interface {
+	// Put stores data under key, replacing any existing object.
+	Put(ctx context.Context, key string, data []byte, contentType string) error
+	// Get returns the data stored under key, or ErrNotFound.
+	Get(ctx context.Context, key string) ([]byte, error)
+	// Delete removes the object stored under key. Deleting a missing key is not an error.
+	Delete(ctx context.Context, key string) error
+	// URL returns the public URL of the object stored under key.
+	URL(key string) string
+}
+
+// validKey rejects keys that are empty, absolute or contain "." or ".." segments.
+func validKey(key string) bool {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, "\\") {
+		return false
+	}
+	for _, segment := range strings.Split(key, "/") {
+		if segment == "" || segment == "." || segment == ".." {
+			return false
+		}
+	}
+	return true
+}
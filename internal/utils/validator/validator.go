@@ -2,11 +2,13 @@ package validator
 
 import (
 	"fmt"
+	"net/url"
 	"reflect"
 	"regexp"
 	"slices"
 	"strconv"
 	"strings"
+	"unicode/utf8"
 )
 
 // Validator provides methods for struct validation.
@@ -37,6 +39,15 @@ func (v *validator) Validate(input interface{}) map[string]interface{} {
 			continue
 		}
 
+		// optional fields are pointers; a missing value validates as empty
+		if fieldValue.Kind() == reflect.Pointer {
+			if fieldValue.IsNil() {
+				fieldValue = reflect.ValueOf("")
+			} else {
+				fieldValue = fieldValue.Elem()
+			}
+		}
+
 		rules := strings.Split(validateTag, ",")
 		if err := validateField(rules, fieldValue.String(), errField); err != "" {
 			errors[errField] = err
@@ -82,6 +93,20 @@ func validateField(rules []string, value string, field string) string {
 		}
 	}
 
+	// validate max value field
+	if index := slices.IndexFunc(rules, func(rule string) bool {
+		return strings.HasPrefix(rule, "max=")
+	}); index != -1 {
+		if err := validateMaxLength(rules[index], value, field); err != "" {
+			return err
+		}
+	}
+
+	// validate url field; an empty value is allowed so it can be cleared
+	if slices.Contains(rules, "url") && value != "" && !isValidURL(value) {
+		return "Enter a valid URL."
+	}
+
 	// add new rules here
 	// ###
 
@@ -107,3 +132,25 @@ func validateMinLength(rule, value, field string) string {
 	}
 	return ""
 }
+
+// validateMaxLength validates if the string is within the maximum length.
+func validateMaxLength(rule, value, field string) string {
+	maxValue := strings.TrimPrefix(rule, "max=")
+	maxLength, err := strconv.Atoi(maxValue)
+	if err != nil {
+		return fmt.Sprintf("Invalid max value for field '%s'", field)
+	}
+	if utf8.RuneCountInString(strings.TrimSpace(value)) > maxLength {
+		return fmt.Sprintf("'%s' field must be at most %d characters", field, maxLength)
+	}
+	return ""
+}
+
+// isValidURL checks if the value is an absolute http or https URL.
+func isValidURL(value string) bool {
+	u, err := url.ParseRequestURI(value)
+	if err != nil {
+		return false
+	}
+	return (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
@@ -58,6 +58,64 @@ func TestValidateMinLengthField(t *testing.T) {
 	}
 }
 
+func TestValidateMaxLengthField(t *testing.T) {
+	type TestStruct struct {
+		Bio string `json:"bio" validate:"max=5"`
+	}
+
+	v := New()
+	result := v.Validate(TestStruct{Bio: "abcdef"})
+
+	expectedError := "'bio' field must be at most 5 characters"
+	if err, ok := result["error"].(map[string]string); ok {
+		if err["bio"] != expectedError {
+			t.Errorf("Expected error '%s', got '%s'", expectedError, err["bio"])
+		}
+	} else {
+		t.Errorf("Expected validation errors, got none")
+	}
+}
+
+func TestValidateURLField(t *testing.T) {
+	type TestStruct struct {
+		Avatar string `json:"avatar" validate:"url"`
+	}
+
+	v := New()
+	for _, value := range []string{"not a url", "ftp://example.com/a.png", "/relative.png"} {
+		result := v.Validate(TestStruct{Avatar: value})
+
+		expectedError := "Enter a valid URL."
+		if err, ok := result["error"].(map[string]string); ok {
+			if err["avatar"] != expectedError {
+				t.Errorf("Expected error '%s' for '%s', got '%s'", expectedError, value, err["avatar"])
+			}
+		} else {
+			t.Errorf("Expected validation errors for '%s', got none", value)
+		}
+	}
+
+	if result := v.Validate(TestStruct{Avatar: "https://example.com/a.png"}); result != nil {
+		t.Errorf("Expected no validation errors, got '%v'", result)
+	}
+}
+
+func TestValidatePointerField(t *testing.T) {
+	type TestStruct struct {
+		Bio *string `json:"bio" validate:"max=3"`
+	}
+
+	v := New()
+	if result := v.Validate(TestStruct{}); result != nil {
+		t.Errorf("Expected a missing optional field to pass, got '%v'", result)
+	}
+
+	bio := "abcd"
+	if result := v.Validate(TestStruct{Bio: &bio}); result == nil {
+		t.Errorf("Expected validation errors, got none")
+	}
+}
+
 func TestValidateValidInput(t *testing.T) {
 	type TestStruct struct {
 		Name     string `json:"name" validate:"required"`
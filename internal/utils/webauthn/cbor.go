@@ -0,0 +1,159 @@
+package webauthn
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// maxCBORDepth bounds nesting so malformed input cannot exhaust the stack.
+const maxCBORDepth = 16
+
+var errCBORTruncated = errors.New("cbor: unexpected end of data")
+
+// decodeCBOR decodes the first CBOR item in data and returns it along with the
+// number of bytes it occupied. Only the subset used by WebAuthn is supported:
+// integers, byte and text strings, arrays, maps and simple values, all with
+// definite lengths.
+func decodeCBOR(data []byte) (any, int, error) {
+	d := &cborDecoder{data: data}
+	v, err := d.decode(0)
+	return v, d.pos, err
+}
+
+type cborDecoder struct {
+	data []byte
+	pos  int
+}
+
+func (d *cborDecoder) decode(depth int) (any, error) {
+	if depth > maxCBORDepth {
+		return nil, errors.New("cbor: nesting too deep")
+	}
+
+	major, arg, err := d.head()
+	if err != nil {
+		return nil, err
+	}
+
+	switch major {
+	case 0:
+		if arg > math.MaxInt64 {
+			return nil, errors.New("cbor: integer overflow")
+		}
+		return int64(arg), nil
+	case 1:
+		if arg > math.MaxInt64 {
+			return nil, errors.New("cbor: integer overflow")
+		}
+		return -1 - int64(arg), nil
+	case 2, 3:
+		b, err := d.take(arg)
+		if err != nil {
+			return nil, err
+		}
+		if major == 3 {
+			return string(b), nil
+		}
+		return append([]byte(nil), b...), nil
+	case 4:
+		if arg > uint64(len(d.data)-d.pos) {
+			return nil, errCBORTruncated
+		}
+		items := make([]any, 0, arg)
+		for i := uint64(0); i < arg; i++ {
+			item, err := d.decode(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+		}
+		return items, nil
+	case 5:
+		if arg > uint64(len(d.data)-d.pos) {
+			return nil, errCBORTruncated
+		}
+		m := make(map[any]any, arg)
+		for i := uint64(0); i < arg; i++ {
+			key, err := d.decode(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+			switch key.(type) {
+			case int64, string:
+			default:
+				return nil, errors.New("cbor: unsupported map key type")
+			}
+			value, err := d.decode(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+			m[key] = value
+		}
+		return m, nil
+	case 7:
+		switch arg {
+		case 20:
+			return false, nil
+		case 21:
+			return true, nil
+		case 22, 23:
+			return nil, nil
+		}
+	}
+
+	return nil, fmt.Errorf("cbor: unsupported item (major type %d)", major)
+}
+
+// head reads the initial byte and argument of an item.
+func (d *cborDecoder) head() (byte, uint64, error) {
+	if d.pos >= len(d.data) {
+		return 0, 0, errCBORTruncated
+	}
+
+	b := d.data[d.pos]
+	d.pos++
+	major, info := b>>5, b&0x1f
+
+	switch {
+	case info < 24:
+		return major, uint64(info), nil
+	case info == 24:
+		v, err := d.take(1)
+		if err != nil {
+			return 0, 0, err
+		}
+		return major, uint64(v[0]), nil
+	case info == 25:
+		v, err := d.take(2)
+		if err != nil {
+			return 0, 0, err
+		}
+		return major, uint64(binary.BigEndian.Uint16(v)), nil
+	case info == 26:
+		v, err := d.take(4)
+		if err != nil {
+			return 0, 0, err
+		}
+		return major, uint64(binary.BigEndian.Uint32(v)), nil
+	case info == 27:
+		v, err := d.take(8)
+		if err != nil {
+			return 0, 0, err
+		}
+		return major, binary.BigEndian.Uint64(v), nil
+	}
+
+	return 0, 0, errors.New("cbor: indefinite lengths are not supported")
+}
+
+// take returns the next n bytes.
+func (d *cborDecoder) take(n uint64) ([]byte, error) {
+	if n > uint64(len(d.data)-d.pos) {
+		return nil, errCBORTruncated
+	}
+	b := d.data[d.pos : d.pos+int(n)]
+	d.pos += int(n)
+	return b, nil
+}
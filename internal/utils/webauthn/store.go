@@ -0,0 +1,60 @@
+package webauthn
+
+import (
+	"sync"
+	"time"
+)
+
+// ChallengeStore keeps issued challenges until their ceremony finishes or times out.
+type ChallengeStore struct {
+	mu         sync.Mutex
+	challenges map[string]pendingChallenge
+	now        func() time.Time
+}
+
+type pendingChallenge struct {
+	userId    int64
+	expiresAt time.Time
+}
+
+// NewChallengeStore creates an empty store.
+func NewChallengeStore() *ChallengeStore {
+	return &ChallengeStore{
+		challenges: make(map[string]pendingChallenge),
+		now:        time.Now,
+	}
+}
+
+// Put remembers a challenge issued to a user; userId is zero when the user is not yet known.
+func (s *ChallengeStore) Put(challenge []byte, userId int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	for key, pending := range s.challenges {
+		if now.After(pending.expiresAt) {
+			delete(s.challenges, key)
+		}
+	}
+
+	s.challenges[string(challenge)] = pendingChallenge{userId: userId, expiresAt: now.Add(Timeout)}
+}
+
+// Take removes a challenge and returns the user it was issued to.
+// Each challenge can only be taken once.
+func (s *ChallengeStore) Take(challenge []byte) (int64, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	pending, ok := s.challenges[string(challenge)]
+	if !ok {
+		return 0, false
+	}
+	delete(s.challenges, string(challenge))
+
+	if s.now().After(pending.expiresAt) {
+		return 0, false
+	}
+
+	return pending.userId, true
+}
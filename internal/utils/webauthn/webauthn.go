@@ -0,0 +1,459 @@
+package webauthn
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// Timeout is how long the browser and the server wait for a ceremony to finish.
+const Timeout = 5 * time.Minute
+
+// authenticator data flags
+const (
+	flagUserPresent  = 0x01
+	flagAttestedData = 0x40
+)
+
+var (
+	ErrInvalidResponse = errors.New("webauthn: invalid authenticator response")
+	ErrChallenge       = errors.New("webauthn: challenge mismatch")
+	ErrOrigin          = errors.New("webauthn: origin mismatch")
+	ErrRelyingParty    = errors.New("webauthn: relying party mismatch")
+	ErrUserPresence    = errors.New("webauthn: user presence not asserted")
+	ErrSignature       = errors.New("webauthn: signature verification failed")
+	ErrSignCount       = errors.New("webauthn: sign counter did not increase, the authenticator may be cloned")
+)
+
+// Config identifies the relying party.
+type Config struct {
+	RPID   string
+	RPName string
+	Origin string
+	// Secret keys the decoy credential IDs offered for unknown users
+	Secret []byte
+}
+
+// WebAuthn runs registration and authentication ceremonies for one relying party.
+type WebAuthn struct {
+	config Config
+}
+
+// New creates a WebAuthn relying party.
+func New(config Config) *WebAuthn {
+	return &WebAuthn{config: config}
+}
+
+// User is the account a credential is registered for.
+type User struct {
+	ID          []byte
+	Name        string
+	DisplayName string
+}
+
+// Credential is what has to be stored for a registered authenticator.
+type Credential struct {
+	ID        []byte
+	PublicKey []byte
+	SignCount uint32
+}
+
+// CreationOptions are passed to navigator.credentials.create as publicKey.
+type CreationOptions struct {
+	Challenge              string                 `json:"challenge"`
+	RP                     rpEntity               `json:"rp"`
+	User                   userEntity             `json:"user"`
+	PubKeyCredParams       []credentialParameter  `json:"pubKeyCredParams"`
+	Timeout                int64                  `json:"timeout"`
+	ExcludeCredentials     []credentialDescriptor `json:"excludeCredentials"`
+	AuthenticatorSelection authenticatorSelection `json:"authenticatorSelection"`
+	Attestation            string                 `json:"attestation"`
+}
+
+// RequestOptions are passed to navigator.credentials.get as publicKey.
+type RequestOptions struct {
+	Challenge        string                 `json:"challenge"`
+	RPID             string                 `json:"rpId"`
+	Timeout          int64                  `json:"timeout"`
+	AllowCredentials []credentialDescriptor `json:"allowCredentials"`
+	UserVerification string                 `json:"userVerification"`
+}
+
+type rpEntity struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type userEntity struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	DisplayName string `json:"displayName"`
+}
+
+type credentialParameter struct {
+	Type string `json:"type"`
+	Alg  int64  `json:"alg"`
+}
+
+type credentialDescriptor struct {
+	Type string `json:"type"`
+	ID   string `json:"id"`
+}
+
+type authenticatorSelection struct {
+	ResidentKey      string `json:"residentKey"`
+	UserVerification string `json:"userVerification"`
+}
+
+// RegistrationResponse is the JSON serialization of the credential returned by navigator.credentials.create.
+type RegistrationResponse struct {
+	ID       string `json:"id"`
+	RawID    string `json:"rawId"`
+	Type     string `json:"type"`
+	Response struct {
+		ClientDataJSON    string `json:"clientDataJSON"`
+		AttestationObject string `json:"attestationObject"`
+	} `json:"response"`
+}
+
+// AssertionResponse is the JSON serialization of the credential returned by navigator.credentials.get.
+type AssertionResponse struct {
+	ID       string `json:"id"`
+	RawID    string `json:"rawId"`
+	Type     string `json:"type"`
+	Response struct {
+		ClientDataJSON    string `json:"clientDataJSON"`
+		AuthenticatorData string `json:"authenticatorData"`
+		Signature         string `json:"signature"`
+		UserHandle        string `json:"userHandle"`
+	} `json:"response"`
+}
+
+type clientData struct {
+	Type      string `json:"type"`
+	Challenge string `json:"challenge"`
+	Origin    string `json:"origin"`
+}
+
+type authenticatorData struct {
+	rpIDHash     []byte
+	flags        byte
+	signCount    uint32
+	credentialID []byte
+	publicKey    []byte
+}
+
+// NewChallenge returns a random challenge for a ceremony.
+func NewChallenge() ([]byte, error) {
+	challenge := make([]byte, 32)
+	if _, err := rand.Read(challenge); err != nil {
+		return nil, err
+	}
+	return challenge, nil
+}
+
+// DecoyCredentialID returns a credential ID that is stable for the name but
+// belongs to no one, to offer when the name has no credentials so a login
+// does not reveal whether an account exists.
+func (w *WebAuthn) DecoyCredentialID(name string) []byte {
+	mac := hmac.New(sha256.New, w.config.Secret)
+	mac.Write([]byte("webauthn decoy credential\x00" + name))
+	return mac.Sum(nil)
+}
+
+// BeginRegistration builds the options for registering a new credential.
+// Credentials the user already has are excluded so an authenticator is not registered twice.
+func (w *WebAuthn) BeginRegistration(user User, challenge []byte, existing [][]byte) *CreationOptions {
+	options := &CreationOptions{
+		Challenge: encode(challenge),
+		RP:        rpEntity{ID: w.config.RPID, Name: w.config.RPName},
+		User: userEntity{
+			ID:          encode(user.ID),
+			Name:        user.Name,
+			DisplayName: user.DisplayName,
+		},
+		Timeout:            Timeout.Milliseconds(),
+		ExcludeCredentials: descriptors(existing),
+		AuthenticatorSelection: authenticatorSelection{
+			ResidentKey:      "preferred",
+			UserVerification: "preferred",
+		},
+		Attestation: "none",
+	}
+
+	for _, alg := range SupportedAlgorithms {
+		options.PubKeyCredParams = append(options.PubKeyCredParams, credentialParameter{Type: "public-key", Alg: alg})
+	}
+
+	return options
+}
+
+// FinishRegistration verifies the authenticator's response and returns the new credential.
+// Attestation statements are not verified because only "none" attestation is requested.
+func (w *WebAuthn) FinishRegistration(challenge []byte, response RegistrationResponse) (*Credential, error) {
+	if response.Type != "public-key" {
+		return nil, ErrInvalidResponse
+	}
+
+	clientDataJSON, err := decode(response.Response.ClientDataJSON)
+	if err != nil {
+		return nil, ErrInvalidResponse
+	}
+
+	if err := w.verifyClientData(clientDataJSON, "webauthn.create", challenge); err != nil {
+		return nil, err
+	}
+
+	attestation, err := decode(response.Response.AttestationObject)
+	if err != nil {
+		return nil, ErrInvalidResponse
+	}
+
+	v, _, err := decodeCBOR(attestation)
+	if err != nil {
+		return nil, ErrInvalidResponse
+	}
+
+	m, ok := v.(map[any]any)
+	if !ok {
+		return nil, ErrInvalidResponse
+	}
+
+	rawAuthData, ok := m["authData"].([]byte)
+	if !ok {
+		return nil, ErrInvalidResponse
+	}
+
+	authData, err := parseAuthenticatorData(rawAuthData)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := w.verifyAuthenticatorData(authData); err != nil {
+		return nil, err
+	}
+
+	if authData.flags&flagAttestedData == 0 {
+		return nil, ErrInvalidResponse
+	}
+
+	if _, err := parsePublicKey(authData.publicKey); err != nil {
+		return nil, err
+	}
+
+	return &Credential{
+		ID:        authData.credentialID,
+		PublicKey: authData.publicKey,
+		SignCount: authData.signCount,
+	}, nil
+}
+
+// BeginLogin builds the options for an assertion. With no allowed credentials
+// the browser offers any discoverable credential for the relying party.
+func (w *WebAuthn) BeginLogin(challenge []byte, allowed [][]byte) *RequestOptions {
+	return &RequestOptions{
+		Challenge:        encode(challenge),
+		RPID:             w.config.RPID,
+		Timeout:          Timeout.Milliseconds(),
+		AllowCredentials: descriptors(allowed),
+		UserVerification: "preferred",
+	}
+}
+
+// FinishLogin verifies an assertion made with the stored credential and returns the new sign counter.
+func (w *WebAuthn) FinishLogin(challenge []byte, credential Credential, response AssertionResponse) (uint32, error) {
+	if response.Type != "public-key" {
+		return 0, ErrInvalidResponse
+	}
+
+	rawID, err := CredentialID(response)
+	if err != nil || !bytes.Equal(rawID, credential.ID) {
+		return 0, ErrInvalidResponse
+	}
+
+	clientDataJSON, err := decode(response.Response.ClientDataJSON)
+	if err != nil {
+		return 0, ErrInvalidResponse
+	}
+
+	if err := w.verifyClientData(clientDataJSON, "webauthn.get", challenge); err != nil {
+		return 0, err
+	}
+
+	rawAuthData, err := decode(response.Response.AuthenticatorData)
+	if err != nil {
+		return 0, ErrInvalidResponse
+	}
+
+	authData, err := parseAuthenticatorData(rawAuthData)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := w.verifyAuthenticatorData(authData); err != nil {
+		return 0, err
+	}
+
+	signature, err := decode(response.Response.Signature)
+	if err != nil {
+		return 0, ErrInvalidResponse
+	}
+
+	key, err := parsePublicKey(credential.PublicKey)
+	if err != nil {
+		return 0, err
+	}
+
+	clientDataHash := sha256.Sum256(clientDataJSON)
+	signed := append(append([]byte(nil), rawAuthData...), clientDataHash[:]...)
+	if !key.verify(signed, signature) {
+		return 0, ErrSignature
+	}
+
+	// authenticators that do not count always report zero
+	if (authData.signCount != 0 || credential.SignCount != 0) && authData.signCount <= credential.SignCount {
+		return 0, ErrSignCount
+	}
+
+	return authData.signCount, nil
+}
+
+// CredentialID returns the raw ID of the credential used for an assertion so it can be looked up.
+func CredentialID(response AssertionResponse) ([]byte, error) {
+	id, err := decode(response.RawID)
+	if err != nil || len(id) == 0 {
+		return nil, ErrInvalidResponse
+	}
+	return id, nil
+}
+
+// ClientChallenge extracts the challenge echoed in base64url encoded client data,
+// so the server side ceremony state can be found before verification.
+func ClientChallenge(encodedClientData string) ([]byte, error) {
+	raw, err := decode(encodedClientData)
+	if err != nil {
+		return nil, ErrInvalidResponse
+	}
+
+	var data clientData
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return nil, ErrInvalidResponse
+	}
+
+	challenge, err := decode(data.Challenge)
+	if err != nil {
+		return nil, ErrInvalidResponse
+	}
+
+	return challenge, nil
+}
+
+// verifyClientData checks the ceremony type, challenge and origin reported by the browser.
+func (w *WebAuthn) verifyClientData(raw []byte, ceremony string, challenge []byte) error {
+	var data clientData
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return ErrInvalidResponse
+	}
+
+	if data.Type != ceremony {
+		return ErrInvalidResponse
+	}
+
+	received, err := decode(data.Challenge)
+	if err != nil || subtle.ConstantTimeCompare(received, challenge) != 1 {
+		return ErrChallenge
+	}
+
+	if data.Origin != w.config.Origin {
+		return ErrOrigin
+	}
+
+	return nil
+}
+
+// verifyAuthenticatorData checks the relying party hash and the user presence flag.
+func (w *WebAuthn) verifyAuthenticatorData(data *authenticatorData) error {
+	rpIDHash := sha256.Sum256([]byte(w.config.RPID))
+	if !bytes.Equal(data.rpIDHash, rpIDHash[:]) {
+		return ErrRelyingParty
+	}
+
+	if data.flags&flagUserPresent == 0 {
+		return ErrUserPresence
+	}
+
+	return nil
+}
+
+// parseAuthenticatorData splits the binary authenticator data into its fields.
+func parseAuthenticatorData(data []byte) (*authenticatorData, error) {
+	if len(data) < 37 {
+		return nil, ErrInvalidResponse
+	}
+
+	parsed := &authenticatorData{
+		rpIDHash:  data[:32],
+		flags:     data[32],
+		signCount: binary.BigEndian.Uint32(data[33:37]),
+	}
+
+	if parsed.flags&flagAttestedData == 0 {
+		return parsed, nil
+	}
+
+	// attested credential data: aaguid (16), length (2), id, COSE key
+	rest := data[37:]
+	if len(rest) < 18 {
+		return nil, ErrInvalidResponse
+	}
+
+	idLength := int(binary.BigEndian.Uint16(rest[16:18]))
+	rest = rest[18:]
+	if idLength == 0 || len(rest) < idLength {
+		return nil, ErrInvalidResponse
+	}
+
+	parsed.credentialID = rest[:idLength]
+	rest = rest[idLength:]
+
+	_, n, err := decodeCBOR(rest)
+	if err != nil {
+		return nil, ErrInvalidResponse
+	}
+	parsed.publicKey = rest[:n]
+
+	return parsed, nil
+}
+
+// descriptors lists credential IDs in the form the browser expects.
+func descriptors(ids [][]byte) []credentialDescriptor {
+	list := make([]credentialDescriptor, 0, len(ids))
+	for _, id := range ids {
+		list = append(list, credentialDescriptor{Type: "public-key", ID: encode(id)})
+	}
+	return list
+}
+
+// encode returns unpadded base64url, the encoding WebAuthn uses for binary values.
+func encode(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// decode accepts base64url with or without padding.
+func decode(s string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(trimPadding(s))
+}
+
+func trimPadding(s string) string {
+	for len(s) > 0 && s[len(s)-1] == '=' {
+		s = s[:len(s)-1]
+	}
+	return s
+}
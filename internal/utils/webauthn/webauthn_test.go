@@ -0,0 +1,330 @@
+package webauthn
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"sort"
+	"testing"
+)
+
+var testConfig = Config{RPID: "example.com", RPName: "Example", Origin: "https://example.com"}
+
+// softAuthenticator is a software authenticator holding a single credential.
+type softAuthenticator struct {
+	id        []byte
+	signer    crypto.Signer
+	coseKey   []byte
+	signCount uint32
+}
+
+func newES256Authenticator(t *testing.T) *softAuthenticator {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	x := key.X.FillBytes(make([]byte, 32))
+	y := key.Y.FillBytes(make([]byte, 32))
+
+	return &softAuthenticator{
+		id:      []byte("es256-credential"),
+		signer:  key,
+		coseKey: encodeCBOR(map[int64]any{1: int64(2), 3: AlgES256, -1: int64(1), -2: x, -3: y}),
+	}
+}
+
+func newEdDSAAuthenticator(t *testing.T) *softAuthenticator {
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &softAuthenticator{
+		id:      []byte("eddsa-credential"),
+		signer:  private,
+		coseKey: encodeCBOR(map[int64]any{1: int64(1), 3: AlgEdDSA, -1: int64(6), -2: []byte(public)}),
+	}
+}
+
+// create answers navigator.credentials.create.
+func (a *softAuthenticator) create(options *CreationOptions, origin string) RegistrationResponse {
+	authData := a.authData(options.RP.ID, flagUserPresent|flagAttestedData)
+	authData = append(authData, make([]byte, 16)...) // aaguid
+	authData = binary.BigEndian.AppendUint16(authData, uint16(len(a.id)))
+	authData = append(authData, a.id...)
+	authData = append(authData, a.coseKey...)
+
+	attestation := encodeCBOR(map[string]any{"fmt": "none", "attStmt": map[string]any{}, "authData": authData})
+
+	var response RegistrationResponse
+	response.ID = encode(a.id)
+	response.RawID = encode(a.id)
+	response.Type = "public-key"
+	response.Response.ClientDataJSON = encode(clientDataFor("webauthn.create", options.Challenge, origin))
+	response.Response.AttestationObject = encode(attestation)
+	return response
+}
+
+// get answers navigator.credentials.get.
+func (a *softAuthenticator) get(t *testing.T, options *RequestOptions, origin string) AssertionResponse {
+	a.signCount++
+	authData := a.authData(options.RPID, flagUserPresent)
+	clientDataJSON := clientDataFor("webauthn.get", options.Challenge, origin)
+	clientDataHash := sha256.Sum256(clientDataJSON)
+	signed := append(append([]byte(nil), authData...), clientDataHash[:]...)
+
+	var signature []byte
+	var err error
+	if _, ok := a.signer.(ed25519.PrivateKey); ok {
+		signature, err = a.signer.Sign(rand.Reader, signed, crypto.Hash(0))
+	} else {
+		digest := sha256.Sum256(signed)
+		signature, err = a.signer.Sign(rand.Reader, digest[:], crypto.SHA256)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var response AssertionResponse
+	response.ID = encode(a.id)
+	response.RawID = encode(a.id)
+	response.Type = "public-key"
+	response.Response.ClientDataJSON = encode(clientDataJSON)
+	response.Response.AuthenticatorData = encode(authData)
+	response.Response.Signature = encode(signature)
+	return response
+}
+
+func (a *softAuthenticator) authData(rpID string, flags byte) []byte {
+	rpIDHash := sha256.Sum256([]byte(rpID))
+	data := append([]byte(nil), rpIDHash[:]...)
+	data = append(data, flags)
+	return binary.BigEndian.AppendUint32(data, a.signCount)
+}
+
+func clientDataFor(ceremony, challenge, origin string) []byte {
+	b, _ := json.Marshal(map[string]string{"type": ceremony, "challenge": challenge, "origin": origin})
+	return b
+}
+
+// encodeCBOR encodes the handful of types the tests need, with canonical map ordering.
+func encodeCBOR(v any) []byte {
+	head := func(major byte, n uint64) []byte {
+		switch {
+		case n < 24:
+			return []byte{major<<5 | byte(n)}
+		case n <= 0xff:
+			return []byte{major<<5 | 24, byte(n)}
+		case n <= 0xffff:
+			return binary.BigEndian.AppendUint16([]byte{major<<5 | 25}, uint16(n))
+		default:
+			return binary.BigEndian.AppendUint32([]byte{major<<5 | 26}, uint32(n))
+		}
+	}
+
+	switch v := v.(type) {
+	case int64:
+		if v < 0 {
+			return head(1, uint64(-1-v))
+		}
+		return head(0, uint64(v))
+	case []byte:
+		return append(head(2, uint64(len(v))), v...)
+	case string:
+		return append(head(3, uint64(len(v))), v...)
+	case map[int64]any:
+		keys := make([]int64, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+		out := head(5, uint64(len(v)))
+		for _, k := range keys {
+			out = append(out, encodeCBOR(k)...)
+			out = append(out, encodeCBOR(v[k])...)
+		}
+		return out
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		out := head(5, uint64(len(v)))
+		for _, k := range keys {
+			out = append(out, encodeCBOR(k)...)
+			out = append(out, encodeCBOR(v[k])...)
+		}
+		return out
+	}
+	panic("unsupported type")
+}
+
+// register runs a full registration ceremony and returns the stored credential.
+func register(t *testing.T, w *WebAuthn, a *softAuthenticator) *Credential {
+	challenge, err := NewChallenge()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	options := w.BeginRegistration(User{ID: []byte("1"), Name: "john@example.com", DisplayName: "John"}, challenge, nil)
+	credential, err := w.FinishRegistration(challenge, a.create(options, testConfig.Origin))
+	if err != nil {
+		t.Fatalf("Expected registration to succeed, got '%v'", err)
+	}
+
+	return credential
+}
+
+func TestRegistrationAndLogin(t *testing.T) {
+	w := New(testConfig)
+
+	for name, a := range map[string]*softAuthenticator{
+		"ES256": newES256Authenticator(t),
+		"EdDSA": newEdDSAAuthenticator(t),
+	} {
+		t.Run(name, func(t *testing.T) {
+			credential := register(t, w, a)
+			if string(credential.ID) != string(a.id) {
+				t.Errorf("Expected credential ID '%s', got '%s'", a.id, credential.ID)
+			}
+
+			for i := 0; i < 2; i++ {
+				challenge, _ := NewChallenge()
+				options := w.BeginLogin(challenge, [][]byte{credential.ID})
+				response := a.get(t, options, testConfig.Origin)
+
+				id, err := CredentialID(response)
+				if err != nil || string(id) != string(credential.ID) {
+					t.Fatalf("Expected credential ID to be readable from the assertion")
+				}
+
+				signCount, err := w.FinishLogin(challenge, *credential, response)
+				if err != nil {
+					t.Fatalf("Expected login to succeed, got '%v'", err)
+				}
+				if signCount != a.signCount {
+					t.Errorf("Expected sign count %d, got %d", a.signCount, signCount)
+				}
+				credential.SignCount = signCount
+			}
+		})
+	}
+}
+
+func TestRegistrationRejectsWrongChallengeAndOrigin(t *testing.T) {
+	w := New(testConfig)
+	a := newES256Authenticator(t)
+	challenge, _ := NewChallenge()
+	other, _ := NewChallenge()
+	options := w.BeginRegistration(User{ID: []byte("1"), Name: "john", DisplayName: "John"}, challenge, nil)
+
+	if _, err := w.FinishRegistration(other, a.create(options, testConfig.Origin)); !errors.Is(err, ErrChallenge) {
+		t.Errorf("Expected '%v', got '%v'", ErrChallenge, err)
+	}
+
+	if _, err := w.FinishRegistration(challenge, a.create(options, "https://evil.example")); !errors.Is(err, ErrOrigin) {
+		t.Errorf("Expected '%v', got '%v'", ErrOrigin, err)
+	}
+
+	options.RP.ID = "evil.example"
+	if _, err := w.FinishRegistration(challenge, a.create(options, testConfig.Origin)); !errors.Is(err, ErrRelyingParty) {
+		t.Errorf("Expected '%v', got '%v'", ErrRelyingParty, err)
+	}
+}
+
+func TestLoginRejectsTamperedSignature(t *testing.T) {
+	w := New(testConfig)
+	a := newES256Authenticator(t)
+	credential := register(t, w, a)
+
+	challenge, _ := NewChallenge()
+	response := a.get(t, w.BeginLogin(challenge, nil), testConfig.Origin)
+	response.Response.AuthenticatorData = encode(a.authData(testConfig.RPID, flagUserPresent|0x04))
+
+	if _, err := w.FinishLogin(challenge, *credential, response); !errors.Is(err, ErrSignature) {
+		t.Errorf("Expected '%v', got '%v'", ErrSignature, err)
+	}
+}
+
+func TestLoginRejectsSignCountRegression(t *testing.T) {
+	w := New(testConfig)
+	a := newES256Authenticator(t)
+	credential := register(t, w, a)
+	credential.SignCount = 10
+
+	challenge, _ := NewChallenge()
+	response := a.get(t, w.BeginLogin(challenge, nil), testConfig.Origin)
+
+	if _, err := w.FinishLogin(challenge, *credential, response); !errors.Is(err, ErrSignCount) {
+		t.Errorf("Expected '%v', got '%v'", ErrSignCount, err)
+	}
+}
+
+func TestLoginRejectsOtherCredential(t *testing.T) {
+	w := New(testConfig)
+	credential := register(t, w, newES256Authenticator(t))
+	other := newEdDSAAuthenticator(t)
+
+	challenge, _ := NewChallenge()
+	response := other.get(t, w.BeginLogin(challenge, nil), testConfig.Origin)
+
+	if _, err := w.FinishLogin(challenge, *credential, response); !errors.Is(err, ErrInvalidResponse) {
+		t.Errorf("Expected '%v', got '%v'", ErrInvalidResponse, err)
+	}
+}
+
+func TestClientChallenge(t *testing.T) {
+	challenge, _ := NewChallenge()
+	encoded := encode(clientDataFor("webauthn.get", encode(challenge), testConfig.Origin))
+
+	got, err := ClientChallenge(encoded)
+	if err != nil || string(got) != string(challenge) {
+		t.Errorf("Expected challenge to be extracted from client data")
+	}
+}
+
+func TestChallengeStoreTakesOnce(t *testing.T) {
+	store := NewChallengeStore()
+	challenge, _ := NewChallenge()
+	store.Put(challenge, 7)
+
+	if userId, ok := store.Take(challenge); !ok || userId != 7 {
+		t.Errorf("Expected challenge for user 7, got %d (%v)", userId, ok)
+	}
+	if _, ok := store.Take(challenge); ok {
+		t.Errorf("Expected challenge to be usable only once")
+	}
+}
+
+func TestDecodeCBORRejectsTruncatedInput(t *testing.T) {
+	data := encodeCBOR(map[string]any{"authData": []byte("0123456789")})
+
+	if _, _, err := decodeCBOR(data[:len(data)-1]); err == nil {
+		t.Errorf("Expected truncated input to fail")
+	}
+}
+
+func TestDecoyCredentialID(t *testing.T) {
+	w := New(Config{RPID: testConfig.RPID, Secret: []byte("secret")})
+
+	first, second := w.DecoyCredentialID("ada@example.com"), w.DecoyCredentialID("ada@example.com")
+	if string(first) != string(second) {
+		t.Errorf("Expected the same decoy for the same name")
+	}
+	if string(first) == string(w.DecoyCredentialID("bob@example.com")) {
+		t.Errorf("Expected different decoys for different names")
+	}
+
+	other := New(Config{RPID: testConfig.RPID, Secret: []byte("other")})
+	if string(first) == string(other.DecoyCredentialID("ada@example.com")) {
+		t.Errorf("Expected the decoy to depend on the secret")
+	}
+}
@@ -0,0 +1,156 @@
+// Package webhook signs and sends webhook requests.
+//
+// Each request carries the event, a delivery ID receivers can use to ignore
+// repeats, a Unix timestamp, and an HMAC-SHA256 signature of the timestamp and
+// body joined by a dot, so receivers can reject forged and replayed requests.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/achintha-dilshan/go-rest-api/internal/utils/backoff"
+)
+
+// request headers
+const (
+	HeaderEvent     = "X-Webhook-Event"
+	HeaderDelivery  = "X-Webhook-Delivery"
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	HeaderSignature = "X-Webhook-Signature"
+)
+
+// SecretPrefix starts every generated secret, which makes leaked ones easy to spot.
+const SecretPrefix = "whsec_"
+
+// retry delays double from BaseDelay up to MaxDelay
+const (
+	BaseDelay = 30 * time.Second
+	MaxDelay  = 6 * time.Hour
+)
+
+// how much of a response is read before the connection is dropped
+const maxResponseBytes = 64 << 10
+
+// ErrPrivateAddress is returned when a webhook URL resolves to an address
+// that is not on the public internet.
+var ErrPrivateAddress = errors.New("webhook address is not public")
+
+// Request is a webhook call.
+type Request struct {
+	URL        string
+	Secret     string
+	Event      string
+	DeliveryId int64
+	Body       []byte
+}
+
+// NewSecret generates a signing secret.
+func NewSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return SecretPrefix + hex.EncodeToString(b), nil
+}
+
+// Sign returns the signature of a body sent at timestamp.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify reports whether signature is the signature of a body sent at timestamp.
+func Verify(secret string, timestamp int64, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, timestamp, body)), []byte(signature))
+}
+
+// Backoff returns how long to wait before the next attempt, after attempt
+// attempts have failed.
+func Backoff(attempt int) time.Duration {
+	return backoff.Exponential(attempt, BaseDelay, MaxDelay)
+}
+
+// NewClient creates the HTTP client requests are sent with. Redirects are not
+// followed, and unless allowPrivate is set, connections to loopback, private
+// and link-local addresses are refused so webhooks cannot reach internal
+// services.
+func NewClient(timeout time.Duration, allowPrivate bool) *http.Client {
+	dialer := &net.Dialer{Timeout: timeout}
+	if !allowPrivate {
+		// checked on the resolved address, so DNS cannot point a public name inward
+		dialer.Control = func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || !public(ip) {
+				return ErrPrivateAddress
+			}
+			return nil
+		}
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// Send makes a webhook call, returning the response status code. Any
+// response is returned without error; it is up to the caller to decide which
+// codes count as delivered.
+func Send(ctx context.Context, client *http.Client, r Request) (int, error) {
+	timestamp := time.Now().Unix()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.URL, bytes.NewReader(r.Body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "go-rest-api-webhooks/1.0")
+	req.Header.Set(HeaderEvent, r.Event)
+	req.Header.Set(HeaderDelivery, strconv.FormatInt(r.DeliveryId, 10))
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderSignature, Sign(r.Secret, timestamp, r.Body))
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	// reading the response lets the connection be reused
+	if _, err := io.Copy(io.Discard, io.LimitReader(resp.Body, maxResponseBytes)); err != nil {
+		return resp.StatusCode, fmt.Errorf("reading response: %w", err)
+	}
+
+	return resp.StatusCode, nil
+}
+
+// public reports whether ip is a public unicast address.
+func public(ip net.IP) bool {
+	return ip.IsGlobalUnicast() && !ip.IsPrivate() && !ip.IsLoopback() && !ip.IsLinkLocalUnicast()
+}
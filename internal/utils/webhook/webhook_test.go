@@ -0,0 +1,126 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSignAndVerify(t *testing.T) {
+	body := []byte(`{"event":"post.created"}`)
+	signature := Sign("secret", 1700000000, body)
+
+	if !strings.HasPrefix(signature, "sha256=") {
+		t.Errorf("Expected a sha256 signature, got '%s'", signature)
+	}
+	if !Verify("secret", 1700000000, body, signature) {
+		t.Errorf("Expected the signature to verify")
+	}
+	if Verify("other", 1700000000, body, signature) {
+		t.Errorf("Expected another secret to fail")
+	}
+	if Verify("secret", 1700000001, body, signature) {
+		t.Errorf("Expected another timestamp to fail")
+	}
+	if Verify("secret", 1700000000, []byte(`{"event":"post.deleted"}`), signature) {
+		t.Errorf("Expected another body to fail")
+	}
+}
+
+func TestNewSecret(t *testing.T) {
+	first, err := NewSecret()
+	if err != nil {
+		t.Fatalf("Expected no error, got '%v'", err)
+	}
+	second, _ := NewSecret()
+
+	if !strings.HasPrefix(first, SecretPrefix) || len(first) != len(SecretPrefix)+64 {
+		t.Errorf("Expected a prefixed 32 byte secret, got '%s'", first)
+	}
+	if first == second {
+		t.Errorf("Expected secrets to differ")
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	cases := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{1, 30 * time.Second},
+		{2, time.Minute},
+		{3, 2 * time.Minute},
+		{6, 16 * time.Minute},
+		{20, MaxDelay},
+	}
+
+	for _, c := range cases {
+		if got := Backoff(c.attempt); got != c.want {
+			t.Errorf("Expected '%v' after %d attempts, got '%v'", c.want, c.attempt, got)
+		}
+	}
+}
+
+func TestSend(t *testing.T) {
+	var received *http.Request
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	status, err := Send(context.Background(), NewClient(time.Second, true), Request{
+		URL:        server.URL,
+		Secret:     "secret",
+		Event:      "post.created",
+		DeliveryId: 42,
+		Body:       []byte(`{"id":1}`),
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got '%v'", err)
+	}
+	if status != http.StatusAccepted {
+		t.Errorf("Expected '%d', got '%d'", http.StatusAccepted, status)
+	}
+
+	if received.Header.Get(HeaderEvent) != "post.created" || received.Header.Get(HeaderDelivery) != "42" {
+		t.Errorf("Expected event and delivery headers, got '%v'", received.Header)
+	}
+
+	timestamp, _ := strconv.ParseInt(received.Header.Get(HeaderTimestamp), 10, 64)
+	if !Verify("secret", timestamp, body, received.Header.Get(HeaderSignature)) {
+		t.Errorf("Expected a valid signature, got '%s'", received.Header.Get(HeaderSignature))
+	}
+}
+
+func TestSendRefusesPrivateAddresses(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("Expected no request to reach a loopback address")
+	}))
+	defer server.Close()
+
+	_, err := Send(context.Background(), NewClient(time.Second, false), Request{URL: server.URL})
+	if !errors.Is(err, ErrPrivateAddress) {
+		t.Errorf("Expected '%v', got '%v'", ErrPrivateAddress, err)
+	}
+}
+
+func TestSendDoesNotFollowRedirects(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/elsewhere", http.StatusFound)
+	}))
+	defer server.Close()
+
+	status, err := Send(context.Background(), NewClient(time.Second, true), Request{URL: server.URL})
+	if err != nil || status != http.StatusFound {
+		t.Errorf("Expected '%d', got '%d' and '%v'", http.StatusFound, status, err)
+	}
+}